@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+
+	"github.com/HeadGardener/coursework/internal/service"
+	"github.com/HeadGardener/coursework/internal/storage"
+	"github.com/google/uuid"
+)
+
+const exportFilePerm = 0o600
+
+// runExport handles the export subcommand, it writes the personal data archive
+// of a user to a file without going through the background job:
+//
+//	coursework export -user <id or username> -out export.zip
+func runExport(ctx context.Context, args []string, userStorage *storage.UserStorage,
+	exportService *service.ExportService) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	user := fs.String("user", "", "id or username of the user to export")
+	out := fs.String("out", "export.zip", "path to write the archive to")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *user == "" {
+		return errors.New("user is not set")
+	}
+
+	userID := *user
+	if _, err := uuid.Parse(userID); err != nil {
+		u, err := userStorage.GetByUsername(ctx, *user)
+		if err != nil {
+			return err
+		}
+
+		userID = u.ID
+	}
+
+	archive, err := exportService.Build(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(*out, archive, exportFilePerm)
+}
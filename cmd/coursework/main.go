@@ -11,6 +11,10 @@ import (
 	"github.com/HeadGardener/coursework/internal/config"
 	"github.com/HeadGardener/coursework/internal/handlers"
 	"github.com/HeadGardener/coursework/internal/lib/auth"
+	"github.com/HeadGardener/coursework/internal/lib/hash"
+	"github.com/HeadGardener/coursework/internal/lib/oidc"
+	"github.com/HeadGardener/coursework/internal/models"
+	"github.com/HeadGardener/coursework/internal/notifier"
 	"github.com/HeadGardener/coursework/internal/server"
 	"github.com/HeadGardener/coursework/internal/service"
 	"github.com/HeadGardener/coursework/internal/storage"
@@ -44,25 +48,87 @@ func main() {
 	}
 
 	var (
-		userStorage  = storage.NewUserStorage(db)
-		drinkStorage = storage.NewDrinkStorage(db)
-		tokenStorage = storage.NewTokenStorage(rdb)
+		userStorage         = storage.NewUserStorage(db)
+		drinkStorage        = storage.NewDrinkStorage(db)
+		categoryStorage     = storage.NewCategoryStorage(db)
+		tokenStorage        = storage.NewTokenStorage(rdb)
+		denylist            = storage.NewDenylistStorage(rdb)
+		resetStorage        = storage.NewResetTokenStorage(rdb)
+		recoveryCodeStorage = storage.NewRecoveryCodeStorage(db)
+		mfaStorage          = storage.NewMFAStorage(rdb)
+		attemptStorage      = storage.NewLoginAttemptStorage(rdb)
+		exportStorage       = storage.NewExportStorage(rdb)
+		apiKeyStorage       = storage.NewAPIKeyStorage(db)
+		oauthClientStorage  = storage.NewOAuthClientStorage(db)
+		authCodeStorage     = storage.NewAuthorizationCodeStorage(rdb)
+		identityStorage     = storage.NewIdentityStorage(db)
+		oidcLoginStorage    = storage.NewOIDCLoginStorage(rdb)
 	)
 
-	var (
-		tokenManager = auth.NewTokenManager(&conf.TokensConfig)
-	)
+	passwordHasher := hash.NewPasswordHasher(hash.Argon2Params{
+		Memory:      conf.HashConfig.Memory,
+		Iterations:  conf.HashConfig.Iterations,
+		Parallelism: conf.HashConfig.Parallelism,
+		SaltLen:     hash.DefaultArgon2Params.SaltLen,
+		KeyLen:      hash.DefaultArgon2Params.KeyLen,
+	})
+
+	var notify service.Notifier = notifier.NewLogNotifier()
+	if conf.NotifierConfig.FilePath != "" {
+		notify = notifier.NewFileNotifier(conf.NotifierConfig.FilePath)
+	}
+
+	tokenManager, err := auth.NewTokenManager(&conf.TokensConfig)
+	if err != nil {
+		stop()
+		log.Fatalf("[FATAL] error while initializing token manager: %s", err.Error())
+	}
+
+	agePolicies, err := models.NewAgePolicies(conf.AgePolicy.DefaultRegion, conf.AgePolicy.MinAges)
+	if err != nil {
+		stop()
+		log.Fatalf("[FATAL] error while initializing drinking age policies: %s", err.Error())
+	}
+
+	oidcProviders := make(map[string]service.OIDCProvider, len(conf.OIDCConfig.Providers))
+	for _, providerConf := range conf.OIDCConfig.Providers {
+		oidcProviders[providerConf.Name] = oidc.NewProvider(providerConf)
+	}
 
 	var (
-		authService  = service.NewAuthService(tokenManager, tokenStorage, userStorage)
-		drinkService = service.NewDrinkService(drinkStorage)
+		authService = service.NewAuthService(tokenManager, tokenStorage, userStorage, denylist,
+			resetStorage, notify, recoveryCodeStorage, mfaStorage, attemptStorage, passwordHasher, agePolicies)
+		drinkService    = service.NewDrinkService(drinkStorage, categoryStorage, conf.AgePolicy.SoftDrinkMaxABV)
+		categoryService = service.NewCategoryService(categoryStorage, conf.AgePolicy.SoftDrinkMaxABV)
+		exportService   = service.NewExportService(userStorage, tokenStorage, apiKeyStorage, identityStorage, exportStorage,
+			conf.ExportConfig.LinkTTL)
+		apiKeyService = service.NewAPIKeyService(userStorage, apiKeyStorage, agePolicies)
+		oauthService  = service.NewOAuthService(authService, oauthClientStorage, authCodeStorage)
+		oidcService   = service.NewOIDCService(authService, oidcProviders, identityStorage, oidcLoginStorage)
 	)
 
-	handler := handlers.NewHandler(authService, drinkService)
+	if flag.Arg(0) == "export" {
+		err = runExport(ctx, flag.Args()[1:], userStorage, exportService)
+		stop()
+
+		_ = db.Close()
+		_ = rdb.Close()
+
+		if err != nil {
+			log.Fatalf("[FATAL] error while exporting user data: %s", err.Error())
+		}
+
+		log.Println("[INFO] user data exported")
+
+		return
+	}
+
+	handler := handlers.NewHandler(authService, drinkService, exportService, apiKeyService, oauthService, oidcService,
+		categoryService)
 
 	srv := &server.Server{}
 	go func() {
-		if err = srv.Run(conf.ServerConfig, handler.InitRoutes()); err != nil {
+		if err = srv.Run(conf.ServerConfig, handler.InitRoutes(conf.HandlerConfig)); err != nil {
 			log.Printf("[ERROR] failed to run server: %e", err)
 		}
 	}()
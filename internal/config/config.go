@@ -4,18 +4,29 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
 )
 
+var checkProviderName = regexp.MustCompile(`^[a-z0-9]+$`)
+
 type Config struct {
-	DBConfig     DBConfig
-	ServerConfig ServerConfig
-	RedisConfig  RedisConfig
-	TokensConfig TokensConfig
+	DBConfig       DBConfig
+	ServerConfig   ServerConfig
+	RedisConfig    RedisConfig
+	TokensConfig   TokensConfig
+	NotifierConfig NotifierConfig
+	HandlerConfig  HandlerConfig
+	HashConfig     HashConfig
+	AgePolicy      AgePolicyConfig
+	ExportConfig   ExportConfig
+	OIDCConfig     OIDCConfig
 }
 
 type DBConfig struct {
@@ -33,11 +44,70 @@ type RedisConfig struct {
 }
 
 type TokensConfig struct {
-	SecretKey      string
-	AccessTokenTTL time.Duration
+	SecretKey            string
+	SigningKeyFile       string
+	VerificationKeyFiles []string
+	AccessTokenTTL       time.Duration
 
 	InitialLen      int
 	RefreshTokenTTL time.Duration
+
+	ResetTokenTTL time.Duration
+}
+
+type HashConfig struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+}
+
+// AgePolicyConfig holds minimum ages keyed by region and drink age category.
+type AgePolicyConfig struct {
+	DefaultRegion   string
+	MinAges         map[string]map[string]int
+	SoftDrinkMaxABV float64
+}
+
+// ExportConfig sets how long a personal data export can be downloaded.
+type ExportConfig struct {
+	LinkTTL time.Duration
+}
+
+// OIDCConfig lists the identity providers users can sign in with.
+type OIDCConfig struct {
+	Providers []OIDCProviderConfig
+}
+
+// OIDCProviderConfig is a client registration at an OpenID Connect provider.
+// Without AutoProvision only users who linked the identity to their account can sign in.
+type OIDCProviderConfig struct {
+	Name          string
+	Issuer        string
+	ClientID      string
+	ClientSecret  string
+	RedirectURL   string
+	Scopes        []string
+	AutoProvision bool
+	TrustMFA      bool
+}
+
+type HandlerConfig struct {
+	RequireAdminMFA bool
+	Cookies         CookieConfig
+}
+
+// CookieConfig enables the browser auth mode, where tokens are kept in HttpOnly
+// cookies instead of being returned in the response body.
+type CookieConfig struct {
+	Enabled  bool
+	Domain   string
+	Secure   bool
+	SameSite http.SameSite
+	MaxAge   time.Duration
+}
+
+type NotifierConfig struct {
+	FilePath string
 }
 
 func Init(path string) (*Config, error) {
@@ -69,8 +139,14 @@ func Init(path string) (*Config, error) {
 	}
 
 	accessTokenSecretKey := os.Getenv("ACCESS_TOKEN_SECRET_KEY")
-	if accessTokenSecretKey == "" {
-		return nil, errors.New("access token secret key is empty")
+	signingKeyFile := os.Getenv("ACCESS_TOKEN_SIGNING_KEY_FILE")
+	if accessTokenSecretKey == "" && signingKeyFile == "" {
+		return nil, errors.New("both access token secret key and signing key file are empty")
+	}
+
+	var verificationKeyFiles []string
+	if files := os.Getenv("ACCESS_TOKEN_VERIFICATION_KEY_FILES"); files != "" {
+		verificationKeyFiles = strings.Split(files, ",")
 	}
 
 	accessTokenTTL, err := strconv.Atoi(os.Getenv("ACCESS_TOKEN_TTL"))
@@ -88,6 +164,46 @@ func Init(path string) (*Config, error) {
 		return nil, fmt.Errorf("invalid refresh token ttl: %w", err)
 	}
 
+	resetTokenTTL, err := strconv.Atoi(os.Getenv("PASSWORD_RESET_TOKEN_TTL"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid password reset token ttl: %w", err)
+	}
+
+	notifierFilePath := os.Getenv("NOTIFIER_FILE_PATH")
+
+	var requireAdminMFA bool
+	if v := os.Getenv("ADMIN_REQUIRE_MFA"); v != "" {
+		requireAdminMFA, err = strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid admin require mfa: %w", err)
+		}
+	}
+
+	cookieConf, err := initCookieConfig(time.Duration(refreshTokenTTL) * time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	hashConf, err := initHashConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	agePolicy, err := initAgePolicyConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	exportConf, err := initExportConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	oidcConf, err := initOIDCConfig()
+	if err != nil {
+		return nil, err
+	}
+
 	return &Config{
 		DBConfig: DBConfig{
 			URL: dburl,
@@ -101,10 +217,278 @@ func Init(path string) (*Config, error) {
 			DB:       redisDB,
 		},
 		TokensConfig: TokensConfig{
-			SecretKey:       accessTokenSecretKey,
-			AccessTokenTTL:  time.Duration(accessTokenTTL) * time.Minute,
-			InitialLen:      refreshInitialLen,
-			RefreshTokenTTL: time.Duration(refreshTokenTTL) * time.Minute,
+			SecretKey:            accessTokenSecretKey,
+			SigningKeyFile:       signingKeyFile,
+			VerificationKeyFiles: verificationKeyFiles,
+			AccessTokenTTL:       time.Duration(accessTokenTTL) * time.Minute,
+			InitialLen:           refreshInitialLen,
+			RefreshTokenTTL:      time.Duration(refreshTokenTTL) * time.Minute,
+			ResetTokenTTL:        time.Duration(resetTokenTTL) * time.Minute,
+		},
+		NotifierConfig: NotifierConfig{
+			FilePath: notifierFilePath,
 		},
+		HandlerConfig: HandlerConfig{
+			RequireAdminMFA: requireAdminMFA,
+			Cookies:         cookieConf,
+		},
+		HashConfig:   hashConf,
+		AgePolicy:    agePolicy,
+		ExportConfig: exportConf,
+		OIDCConfig:   oidcConf,
 	}, nil
 }
+
+// initCookieConfig reads AUTH_COOKIES, which turns the cookie mode on, and the
+// cookie attributes: AUTH_COOKIE_DOMAIN, AUTH_COOKIE_SECURE (true by default) and
+// AUTH_COOKIE_SAMESITE, one of strict (default), lax or none. Cookies live as long
+// as refresh tokens do.
+func initCookieConfig(maxAge time.Duration) (CookieConfig, error) {
+	conf := CookieConfig{
+		Domain:   os.Getenv("AUTH_COOKIE_DOMAIN"),
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+		MaxAge:   maxAge,
+	}
+
+	if v := os.Getenv("AUTH_COOKIES"); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return CookieConfig{}, fmt.Errorf("invalid auth cookies: %w", err)
+		}
+
+		conf.Enabled = enabled
+	}
+
+	if v := os.Getenv("AUTH_COOKIE_SECURE"); v != "" {
+		secure, err := strconv.ParseBool(v)
+		if err != nil {
+			return CookieConfig{}, fmt.Errorf("invalid auth cookie secure: %w", err)
+		}
+
+		conf.Secure = secure
+	}
+
+	switch v := os.Getenv("AUTH_COOKIE_SAMESITE"); strings.ToLower(v) {
+	case "", "strict":
+	case "lax":
+		conf.SameSite = http.SameSiteLaxMode
+	case "none":
+		// browsers drop SameSite=None cookies without the Secure attribute
+		if !conf.Secure {
+			return CookieConfig{}, errors.New("invalid auth cookie samesite: none requires secure cookies")
+		}
+
+		conf.SameSite = http.SameSiteNoneMode
+	default:
+		return CookieConfig{}, fmt.Errorf("invalid auth cookie samesite %q: must be strict, lax or none", v)
+	}
+
+	return conf, nil
+}
+
+// initHashConfig reads Argon2id parameters, each of them falls back to
+// the default when not set. Values argon2 can't work with are rejected.
+//
+//nolint:gomnd
+func initHashConfig() (HashConfig, error) {
+	conf := HashConfig{
+		Memory:      64 * 1024,
+		Iterations:  3,
+		Parallelism: 2,
+	}
+
+	if v := os.Getenv("ARGON2_MEMORY"); v != "" {
+		memory, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return HashConfig{}, fmt.Errorf("invalid argon2 memory: %w", err)
+		}
+
+		conf.Memory = uint32(memory)
+	}
+
+	if v := os.Getenv("ARGON2_ITERATIONS"); v != "" {
+		iterations, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return HashConfig{}, fmt.Errorf("invalid argon2 iterations: %w", err)
+		}
+
+		conf.Iterations = uint32(iterations)
+	}
+
+	if v := os.Getenv("ARGON2_PARALLELISM"); v != "" {
+		parallelism, err := strconv.ParseUint(v, 10, 8)
+		if err != nil {
+			return HashConfig{}, fmt.Errorf("invalid argon2 parallelism: %w", err)
+		}
+
+		conf.Parallelism = uint8(parallelism)
+	}
+
+	// argon2 panics on zero iterations or parallelism, and needs 8 KiB of memory per lane
+	if conf.Iterations < 1 {
+		return HashConfig{}, errors.New("invalid argon2 iterations: must be at least 1")
+	}
+
+	if conf.Parallelism < 1 {
+		return HashConfig{}, errors.New("invalid argon2 parallelism: must be at least 1")
+	}
+
+	if conf.Memory < 8*uint32(conf.Parallelism) {
+		return HashConfig{}, fmt.Errorf("invalid argon2 memory: must be at least %d KiB for parallelism %d",
+			8*uint32(conf.Parallelism), conf.Parallelism)
+	}
+
+	return conf, nil
+}
+
+// initAgePolicyConfig reads DRINKING_AGE_POLICIES in the form
+// "DEFAULT:beer_wine=18,spirits=18;DE:beer_wine=16,spirits=18". Without it every
+// region gets 18 for all alcoholic drinks. Regions take the ages they don't set
+// from the default region. Drinks up to SOFT_DRINK_MAX_ABV percent
+// (0.5 by default) are soft.
+//
+//nolint:gomnd
+func initAgePolicyConfig() (AgePolicyConfig, error) {
+	conf := AgePolicyConfig{
+		DefaultRegion:   "DEFAULT",
+		SoftDrinkMaxABV: 0.5,
+	}
+
+	if v := os.Getenv("DEFAULT_REGION"); v != "" {
+		conf.DefaultRegion = v
+	}
+
+	if v := os.Getenv("SOFT_DRINK_MAX_ABV"); v != "" {
+		softDrinkMaxABV, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return AgePolicyConfig{}, fmt.Errorf("invalid soft drink max abv: %w", err)
+		}
+
+		conf.SoftDrinkMaxABV = softDrinkMaxABV
+	}
+
+	policies := os.Getenv("DRINKING_AGE_POLICIES")
+	if policies == "" {
+		conf.MinAges = map[string]map[string]int{
+			conf.DefaultRegion: {"beer_wine": 18, "spirits": 18},
+		}
+
+		return conf, nil
+	}
+
+	conf.MinAges = make(map[string]map[string]int)
+
+	for _, policy := range strings.Split(policies, ";") {
+		region, ages, ok := strings.Cut(strings.TrimSpace(policy), ":")
+		if !ok || region == "" {
+			return AgePolicyConfig{}, fmt.Errorf("invalid drinking age policy %q: must be like REGION:category=age", policy)
+		}
+
+		conf.MinAges[region] = make(map[string]int)
+
+		for _, entry := range strings.Split(ages, ",") {
+			category, age, ok := strings.Cut(strings.TrimSpace(entry), "=")
+			if !ok {
+				return AgePolicyConfig{}, fmt.Errorf("invalid drinking age policy entry %q for %s", entry, region)
+			}
+
+			minAge, err := strconv.Atoi(age)
+			if err != nil {
+				return AgePolicyConfig{}, fmt.Errorf("invalid drinking age for %s in %s: %w", category, region, err)
+			}
+
+			conf.MinAges[region][category] = minAge
+		}
+	}
+
+	return conf, nil
+}
+
+// initExportConfig reads EXPORT_LINK_TTL in minutes, exports can be downloaded
+// for a day by default.
+//
+//nolint:gomnd
+func initExportConfig() (ExportConfig, error) {
+	conf := ExportConfig{
+		LinkTTL: 24 * time.Hour,
+	}
+
+	if v := os.Getenv("EXPORT_LINK_TTL"); v != "" {
+		linkTTL, err := strconv.Atoi(v)
+		if err != nil {
+			return ExportConfig{}, fmt.Errorf("invalid export link ttl: %w", err)
+		}
+
+		if linkTTL <= 0 {
+			return ExportConfig{}, errors.New("invalid export link ttl: must be positive")
+		}
+
+		conf.LinkTTL = time.Duration(linkTTL) * time.Minute
+	}
+
+	return conf, nil
+}
+
+// initOIDCConfig reads the comma separated provider names from OIDC_PROVIDERS and
+// the settings of each provider from OIDC_<NAME>_ISSUER, _CLIENT_ID, _CLIENT_SECRET,
+// _REDIRECT_URL, and optionally _SCOPES ("openid profile" by default),
+// _AUTO_PROVISION (true by default) and _TRUST_MFA (false by default), which lets
+// a second factor done at the provider stand in for the user's own 2FA.
+func initOIDCConfig() (OIDCConfig, error) {
+	var conf OIDCConfig
+
+	names := os.Getenv("OIDC_PROVIDERS")
+	if names == "" {
+		return conf, nil
+	}
+
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if !checkProviderName.MatchString(name) {
+			return OIDCConfig{}, fmt.Errorf("invalid oidc provider name %q: must contain only lowercase letters and numbers", name)
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+
+		provider := OIDCProviderConfig{
+			Name:          name,
+			Issuer:        os.Getenv(prefix + "ISSUER"),
+			ClientID:      os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret:  os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:   os.Getenv(prefix + "REDIRECT_URL"),
+			Scopes:        []string{"openid", "profile"},
+			AutoProvision: true,
+		}
+
+		if provider.Issuer == "" || provider.ClientID == "" || provider.RedirectURL == "" {
+			return OIDCConfig{}, fmt.Errorf("invalid oidc provider %s: issuer, client id and redirect url are required", name)
+		}
+
+		if v := os.Getenv(prefix + "SCOPES"); v != "" {
+			provider.Scopes = strings.Fields(v)
+		}
+
+		if v := os.Getenv(prefix + "AUTO_PROVISION"); v != "" {
+			autoProvision, err := strconv.ParseBool(v)
+			if err != nil {
+				return OIDCConfig{}, fmt.Errorf("invalid oidc auto provision for %s: %w", name, err)
+			}
+
+			provider.AutoProvision = autoProvision
+		}
+
+		if v := os.Getenv(prefix + "TRUST_MFA"); v != "" {
+			trustMFA, err := strconv.ParseBool(v)
+			if err != nil {
+				return OIDCConfig{}, fmt.Errorf("invalid oidc trust mfa for %s: %w", name, err)
+			}
+
+			provider.TrustMFA = trustMFA
+		}
+
+		conf.Providers = append(conf.Providers, provider)
+	}
+
+	return conf, nil
+}
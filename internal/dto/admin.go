@@ -0,0 +1,73 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/HeadGardener/coursework/internal/models"
+)
+
+const (
+	defaultPerPage = 20
+	maxPerPage     = 100
+	maxUsersPage   = 10000
+)
+
+type ListUsersReq struct {
+	Query   string `form:"q"`
+	Page    int    `form:"page"`
+	PerPage int    `form:"per_page"`
+}
+
+type SetRolesReq struct {
+	Roles []string `json:"roles"`
+}
+
+func (r *ListUsersReq) Validate() error {
+	// keeps the offset from overflowing, q narrows the list down when it's that long
+	if r.Page < 0 || r.Page > maxUsersPage {
+		return fmt.Errorf("invalid page: must be between 1 and %d", maxUsersPage)
+	}
+
+	if r.PerPage < 0 || r.PerPage > maxPerPage {
+		return errors.New("invalid per_page: must be between 1 and 100")
+	}
+
+	return nil
+}
+
+// Filter returns the requested page, the first one of defaultPerPage users by default.
+func (r *ListUsersReq) Filter() models.UserFilter {
+	filter := models.UserFilter{
+		Query:   r.Query,
+		Page:    r.Page,
+		PerPage: r.PerPage,
+	}
+
+	if filter.Page == 0 {
+		filter.Page = 1
+	}
+
+	if filter.PerPage == 0 {
+		filter.PerPage = defaultPerPage
+	}
+
+	return filter
+}
+
+func (r *SetRolesReq) Validate() error {
+	if len(r.Roles) == 0 {
+		return errors.New("invalid roles: can't be empty")
+	}
+
+	seen := make(map[string]bool, len(r.Roles))
+	for _, role := range r.Roles {
+		if seen[role] {
+			return errors.New("invalid roles: must not repeat")
+		}
+
+		seen[role] = true
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package dto
+
+import (
+	"errors"
+	"time"
+
+	"github.com/HeadGardener/coursework/internal/models"
+)
+
+const (
+	defaultAPIKeyTTLDays = 90
+	maxAPIKeyTTLDays     = 365
+	maxAPIKeyNameLen     = 64
+)
+
+// CreateAPIKeyReq mints a key for UserID, or for the admin making the request if it is empty.
+type CreateAPIKeyReq struct {
+	Name          string   `json:"name"`
+	UserID        string   `json:"user_id"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days"`
+}
+
+type ListAPIKeysReq struct {
+	UserID string `form:"user_id"`
+}
+
+func (r *CreateAPIKeyReq) Validate() error {
+	if r.Name == "" || len(r.Name) > maxAPIKeyNameLen {
+		return errors.New("invalid name: must be between 1 and 64 characters")
+	}
+
+	if r.ExpiresInDays < 0 || r.ExpiresInDays > maxAPIKeyTTLDays {
+		return errors.New("invalid expires_in_days: must be between 1 and 365")
+	}
+
+	seen := make(map[string]bool, len(r.Scopes))
+	for _, scope := range r.Scopes {
+		if scope == "" || seen[scope] {
+			return errors.New("invalid scopes: must not be empty or repeat")
+		}
+
+		seen[scope] = true
+	}
+
+	return nil
+}
+
+func (r *CreateAPIKeyReq) ScopeList() []models.Permission {
+	return toPermissions(r.Scopes)
+}
+
+// ExpiresAt returns when the key expires, in defaultAPIKeyTTLDays by default.
+func (r *CreateAPIKeyReq) ExpiresAt() time.Time {
+	days := r.ExpiresInDays
+	if days == 0 {
+		days = defaultAPIKeyTTLDays
+	}
+
+	return time.Now().AddDate(0, 0, days)
+}
@@ -3,19 +3,27 @@ package dto
 import (
 	"errors"
 	"regexp"
+	"time"
+)
+
+const (
+	DateLayout = "2006-01-02"
+	maxAge     = 111
 )
 
 var (
 	checkUsername = regexp.MustCompile(`^[0-9A-Za-z]+$`)
 	checkName     = regexp.MustCompile(`^[A-Za-z]+$`)
 	checkPassword = regexp.MustCompile(`[0-9A-z]{8,16}$`)
+	checkRegion   = regexp.MustCompile(`^[A-Z]{2}(-[A-Z0-9]{1,3})?$`)
 )
 
 type SignUpReq struct {
-	Username string `json:"username"`
-	Name     string `json:"name"`
-	Age      int    `json:"age"`
-	Password string `json:"password"`
+	Username  string `json:"username"`
+	Name      string `json:"name"`
+	BirthDate string `json:"birth_date"`
+	Region    string `json:"region"`
+	Password  string `json:"password"`
 }
 
 type SignInReq struct {
@@ -28,6 +36,29 @@ type RefreshRequest struct {
 	RefreshToken string `json:"refresh_token"`
 }
 
+type ChangePasswordReq struct {
+	OldPassword string `json:"old_password"`
+	NewPassword string `json:"new_password"`
+}
+
+type PasswordResetReq struct {
+	Username string `json:"username"`
+}
+
+type ConfirmPasswordResetReq struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+type CompleteSignInReq struct {
+	MFAToken string `json:"mfa_token"`
+	Code     string `json:"code"`
+}
+
+type MFACodeReq struct {
+	Code string `json:"code"`
+}
+
 func (r *SignUpReq) Validate() error {
 	if !checkUsername.MatchString(r.Username) {
 		return errors.New("invalid username: must contain only letters, numbers and symbols(_-) ")
@@ -37,8 +68,18 @@ func (r *SignUpReq) Validate() error {
 		return errors.New("invalid name: must contain only letters")
 	}
 
-	if r.Age <= 0 || r.Age >= 112 {
-		return errors.New("invalid age: can't be less than 0 or greater than 111")
+	birthDate, err := r.ParseBirthDate()
+	if err != nil {
+		return errors.New("invalid birth date: must be in YYYY-MM-DD format")
+	}
+
+	now := time.Now()
+	if birthDate.After(now) || birthDate.Before(now.AddDate(-maxAge, 0, 0)) {
+		return errors.New("invalid birth date: can't be in the future or more than 111 years ago")
+	}
+
+	if r.Region != "" && !checkRegion.MatchString(r.Region) {
+		return errors.New("invalid region: must be an ISO 3166 code like US or CA-ON")
 	}
 
 	if !checkPassword.MatchString(r.Password) {
@@ -48,6 +89,10 @@ func (r *SignUpReq) Validate() error {
 	return nil
 }
 
+func (r *SignUpReq) ParseBirthDate() (time.Time, error) {
+	return time.Parse(DateLayout, r.BirthDate)
+}
+
 func (r *SignInReq) Validate() error {
 	if !checkUsername.MatchString(r.Username) {
 		return errors.New("invalid username: must contain only letters, numbers and symbols(_-) ")
@@ -59,3 +104,55 @@ func (r *SignInReq) Validate() error {
 
 	return nil
 }
+
+func (r *ChangePasswordReq) Validate() error {
+	if r.OldPassword == "" {
+		return errors.New("invalid old password: can't be empty")
+	}
+
+	if !checkPassword.MatchString(r.NewPassword) {
+		return errors.New("invalid new password: must contain only letters and numbers")
+	}
+
+	return nil
+}
+
+func (r *PasswordResetReq) Validate() error {
+	if !checkUsername.MatchString(r.Username) {
+		return errors.New("invalid username: must contain only letters, numbers and symbols(_-) ")
+	}
+
+	return nil
+}
+
+func (r *ConfirmPasswordResetReq) Validate() error {
+	if r.Token == "" {
+		return errors.New("invalid token: can't be empty")
+	}
+
+	if !checkPassword.MatchString(r.NewPassword) {
+		return errors.New("invalid new password: must contain only letters and numbers")
+	}
+
+	return nil
+}
+
+func (r *CompleteSignInReq) Validate() error {
+	if r.MFAToken == "" {
+		return errors.New("invalid mfa token: can't be empty")
+	}
+
+	if r.Code == "" {
+		return errors.New("invalid code: can't be empty")
+	}
+
+	return nil
+}
+
+func (r *MFACodeReq) Validate() error {
+	if r.Code == "" {
+		return errors.New("invalid code: can't be empty")
+	}
+
+	return nil
+}
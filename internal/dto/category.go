@@ -0,0 +1,52 @@
+package dto
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/HeadGardener/coursework/internal/models"
+)
+
+const (
+	maxCategoryNameLen = 64
+)
+
+// CategoryRequest creates or replaces a category. A missing parent_id makes it a top-level
+// one, a missing age_category inherits the parent's default.
+type CategoryRequest struct {
+	Name        string `json:"name"`
+	ParentID    *int   `json:"parent_id"`
+	AgeCategory string `json:"age_category"`
+}
+
+func (r *CategoryRequest) Validate() error {
+	r.Name = strings.TrimSpace(r.Name)
+
+	if r.Name == "" || len(r.Name) > maxCategoryNameLen {
+		return errors.New("invalid name: must be between 1 and 64 characters")
+	}
+
+	if r.ParentID != nil && *r.ParentID <= 0 {
+		return errors.New("invalid parent_id: must be greater than 0")
+	}
+
+	if r.AgeCategory != "" && !models.AgeCategory(r.AgeCategory).Valid() {
+		return errors.New("invalid age category: must be one of soft, beer_wine, spirits")
+	}
+
+	return nil
+}
+
+func (r *CategoryRequest) Category() *models.Category {
+	category := &models.Category{
+		Name:     r.Name,
+		ParentID: r.ParentID,
+	}
+
+	if r.AgeCategory != "" {
+		ageCategory := models.AgeCategory(r.AgeCategory)
+		category.AgeCategory = &ageCategory
+	}
+
+	return category
+}
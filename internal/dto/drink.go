@@ -1,22 +1,391 @@
 package dto
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
 
+	"github.com/HeadGardener/coursework/internal/models"
+)
+
+const (
+	maxABV             = 100
+	maxDrinkNameLen    = 255
+	maxSearchQueryLen  = 100
+	maxSearchPage      = 100
+	defaultSuggestions = 10
+	maxSuggestions     = 20
+)
+
+// DrinkRequest is a whole drink, as added or as it replaces one. ABV is a pointer so
+// that a left out abv isn't taken for a soft drink. An empty age_category is derived
+// from the ABV and the category.
 type DrinkRequest struct {
-	Name   string `json:"name"`
-	Type   string `json:"type"`
-	Bottle int    `json:"bottle"`
-	Cost   int    `json:"cost"`
-	Soft   bool   `json:"soft"`
+	Name        string       `json:"name"`
+	CategoryID  int          `json:"category_id"`
+	Bottle      int          `json:"bottle"`
+	Cost        models.Money `json:"cost"`
+	ABV         *float64     `json:"abv"`
+	AgeCategory string       `json:"age_category"`
 }
 
 func (r *DrinkRequest) Validate() error {
-	if r.Bottle <= 0 {
+	if err := validateDrinkName(r.Name); err != nil {
+		return err
+	}
+
+	if err := validateCategoryID(r.CategoryID); err != nil {
+		return err
+	}
+
+	if err := validateBottle(r.Bottle); err != nil {
+		return err
+	}
+
+	if !r.Cost.Currency.Valid() {
+		return errCurrency
+	}
+
+	if r.Cost.Amount < 0 {
+		return errNegativeCost
+	}
+
+	if r.ABV == nil {
+		return errors.New("invalid abv: required")
+	}
+
+	if err := validateABV(*r.ABV); err != nil {
+		return err
+	}
+
+	if r.AgeCategory != "" {
+		return validateAgeCategory(r.AgeCategory)
+	}
+
+	return nil
+}
+
+// DrinkPatchRequest is a JSON Merge Patch (RFC 7396) of a drink: fields left out are
+// kept, cost is merged field by field. Only age_category may be null, which derives
+// it anew.
+type DrinkPatchRequest struct {
+	Name        Optional[string]     `json:"name"`
+	CategoryID  Optional[int]        `json:"category_id"`
+	Bottle      Optional[int]        `json:"bottle"`
+	Cost        Optional[MoneyPatch] `json:"cost"`
+	ABV         Optional[float64]    `json:"abv"`
+	AgeCategory Optional[string]     `json:"age_category"`
+}
+
+type MoneyPatch struct {
+	Amount   Optional[Decimal] `json:"amount"`
+	Currency Optional[string]  `json:"currency"`
+}
+
+func (r *DrinkPatchRequest) Validate() error {
+	for _, field := range []struct {
+		name string
+		null bool
+	}{
+		{"name", r.Name.Null},
+		{"category_id", r.CategoryID.Null},
+		{"bottle", r.Bottle.Null},
+		{"cost", r.Cost.Null},
+		{"cost.amount", r.Cost.Value.Amount.Null},
+		{"cost.currency", r.Cost.Value.Currency.Null},
+		{"abv", r.ABV.Null},
+	} {
+		if field.null {
+			return fmt.Errorf("invalid %s: can't be null", field.name)
+		}
+	}
+
+	if r.Name.Set {
+		if err := validateDrinkName(r.Name.Value); err != nil {
+			return err
+		}
+	}
+
+	if r.CategoryID.Set {
+		if err := validateCategoryID(r.CategoryID.Value); err != nil {
+			return err
+		}
+	}
+
+	if r.Bottle.Set {
+		if err := validateBottle(r.Bottle.Value); err != nil {
+			return err
+		}
+	}
+
+	if currency := r.Cost.Value.Currency; currency.Set && !models.Currency(currency.Value).Valid() {
+		return errCurrency
+	}
+
+	if amount := r.Cost.Value.Amount; amount.Set && strings.HasPrefix(string(amount.Value), "-") {
+		return errNegativeCost
+	}
+
+	if r.ABV.Set {
+		if err := validateABV(r.ABV.Value); err != nil {
+			return err
+		}
+	}
+
+	if r.AgeCategory.Set && !r.AgeCategory.Null {
+		return validateAgeCategory(r.AgeCategory.Value)
+	}
+
+	return nil
+}
+
+func (r *DrinkPatchRequest) Patch() models.DrinkPatch {
+	patch := models.DrinkPatch{
+		Name:             optionalValue(r.Name),
+		CategoryID:       optionalValue(r.CategoryID),
+		Bottle:           optionalValue(r.Bottle),
+		ABV:              optionalValue(r.ABV),
+		ResetAgeCategory: r.AgeCategory.Null,
+	}
+
+	if amount := r.Cost.Value.Amount; amount.Set {
+		costAmount := string(amount.Value)
+		patch.CostAmount = &costAmount
+	}
+
+	if currency := r.Cost.Value.Currency; currency.Set {
+		costCurrency := models.Currency(currency.Value)
+		patch.Currency = &costCurrency
+	}
+
+	if r.AgeCategory.Set && !r.AgeCategory.Null {
+		ageCategory := models.AgeCategory(r.AgeCategory.Value)
+		patch.AgeCategory = &ageCategory
+	}
+
+	return patch
+}
+
+var (
+	errCurrency     = errors.New("invalid cost: currency must be an ISO 4217 code")
+	errNegativeCost = errors.New("invalid cost: cost can't be less than 0")
+)
+
+func validateDrinkName(name string) error {
+	if strings.TrimSpace(name) == "" || len(name) > maxDrinkNameLen {
+		return errors.New("invalid name: must be between 1 and 255 characters")
+	}
+
+	return nil
+}
+
+func validateCategoryID(id int) error {
+	if id <= 0 {
+		return errors.New("invalid category_id: must be greater than 0")
+	}
+
+	return nil
+}
+
+func validateBottle(bottle int) error {
+	if bottle <= 0 {
 		return errors.New("invalid bottle: bottle can't be less or equals 0")
 	}
 
-	if r.Cost < 0 {
-		return errors.New("invalid cost: cost can't be less than 0")
+	return nil
+}
+
+func validateABV(abv float64) error {
+	if abv < 0 || abv > maxABV {
+		return errors.New("invalid abv: must be between 0 and 100")
+	}
+
+	return nil
+}
+
+func validateAgeCategory(category string) error {
+	if !models.AgeCategory(category).Valid() {
+		return errors.New("invalid age category: must be one of soft, beer_wine, spirits")
+	}
+
+	return nil
+}
+
+func optionalValue[T any](o Optional[T]) *T {
+	if !o.Set || o.Null {
+		return nil
+	}
+
+	return &o.Value
+}
+
+// ListDrinksReq filters and pages the catalog. Costs are in minor units, e.g. cents,
+// so filtering or sorting by cost needs a currency to compare them in. Sort is one of
+// id, name or cost, prefixed with - for descending order. Cursor is the next_cursor
+// of the previous page.
+type ListDrinksReq struct {
+	Categories []int  `form:"category"`
+	Currency   string `form:"currency"`
+	MinCost    *int   `form:"min_cost"`
+	MaxCost    *int   `form:"max_cost"`
+	MinBottle  *int   `form:"min_bottle"`
+	MaxBottle  *int   `form:"max_bottle"`
+	Soft       *bool  `form:"soft"`
+	Sort       string `form:"sort"`
+	Limit      int    `form:"limit"`
+	Cursor     string `form:"cursor"`
+}
+
+// Query validates the request and returns the query for it, sorted by id and
+// limited to defaultPerPage drinks by default.
+func (r *ListDrinksReq) Query() (models.DrinkQuery, error) {
+	query := models.DrinkQuery{
+		Categories: r.Categories,
+		Currency:   models.Currency(r.Currency),
+		MinCost:    r.MinCost,
+		MaxCost:    r.MaxCost,
+		MinBottle:  r.MinBottle,
+		MaxBottle:  r.MaxBottle,
+		Soft:       r.Soft,
+		Sort:       models.DrinkSortID,
+		Limit:      r.Limit,
+	}
+
+	if err := checkRange("cost", r.MinCost, r.MaxCost); err != nil {
+		return models.DrinkQuery{}, err
+	}
+
+	if err := checkRange("bottle", r.MinBottle, r.MaxBottle); err != nil {
+		return models.DrinkQuery{}, err
+	}
+
+	if r.Sort != "" {
+		sort, desc := strings.CutPrefix(r.Sort, "-")
+
+		query.Sort, query.Desc = models.DrinkSort(sort), desc
+		if !query.Sort.Valid() {
+			return models.DrinkQuery{}, errors.New("invalid sort: must be one of id, name, cost, optionally prefixed with -")
+		}
+	}
+
+	if query.Currency != "" && !query.Currency.Valid() {
+		return models.DrinkQuery{}, errors.New("invalid currency: must be an ISO 4217 code")
+	}
+
+	if query.Currency == "" && (r.MinCost != nil || r.MaxCost != nil || query.Sort == models.DrinkSortCost) {
+		return models.DrinkQuery{}, errors.New("invalid currency: required to filter or sort by cost")
+	}
+
+	if r.Limit < 0 || r.Limit > maxPerPage {
+		return models.DrinkQuery{}, errors.New("invalid limit: must be between 1 and 100")
+	}
+
+	if query.Limit == 0 {
+		query.Limit = defaultPerPage
+	}
+
+	if r.Cursor != "" {
+		cursor, err := models.ParseDrinkCursor(r.Cursor, query.Sort, query.Desc)
+		if err != nil {
+			return models.DrinkQuery{}, err
+		}
+
+		query.After = &cursor
+	}
+
+	return query, nil
+}
+
+type SearchDrinksReq struct {
+	Query   string `form:"q"`
+	Page    int    `form:"page"`
+	PerPage int    `form:"per_page"`
+}
+
+func (r *SearchDrinksReq) Validate() error {
+	if err := validateSearchQuery(r.Query); err != nil {
+		return err
+	}
+
+	// deeper pages would mean long offset scans, ranked results that far down aren't useful
+	if r.Page < 0 || r.Page > maxSearchPage {
+		return fmt.Errorf("invalid page: must be between 1 and %d", maxSearchPage)
+	}
+
+	if r.PerPage < 0 || r.PerPage > maxPerPage {
+		return errors.New("invalid per_page: must be between 1 and 100")
+	}
+
+	return nil
+}
+
+// Search returns the requested page, the first one of defaultPerPage drinks by default.
+func (r *SearchDrinksReq) Search() models.DrinkSearch {
+	search := models.DrinkSearch{
+		Query:   strings.TrimSpace(r.Query),
+		Page:    r.Page,
+		PerPage: r.PerPage,
+	}
+
+	if search.Page == 0 {
+		search.Page = 1
+	}
+
+	if search.PerPage == 0 {
+		search.PerPage = defaultPerPage
+	}
+
+	return search
+}
+
+type AutocompleteDrinksReq struct {
+	Query string `form:"q"`
+	Limit int    `form:"limit"`
+}
+
+func (r *AutocompleteDrinksReq) Validate() error {
+	if err := validateSearchQuery(r.Query); err != nil {
+		return err
+	}
+
+	if r.Limit < 0 || r.Limit > maxSuggestions {
+		return fmt.Errorf("invalid limit: must be between 1 and %d", maxSuggestions)
+	}
+
+	return nil
+}
+
+// Prefix returns the name typed so far and how many suggestions to return,
+// defaultSuggestions by default.
+func (r *AutocompleteDrinksReq) Prefix() (string, int) {
+	if r.Limit == 0 {
+		return strings.TrimSpace(r.Query), defaultSuggestions
+	}
+
+	return strings.TrimSpace(r.Query), r.Limit
+}
+
+func validateSearchQuery(query string) error {
+	query = strings.TrimSpace(query)
+
+	if query == "" {
+		return errors.New("invalid q: can't be empty")
+	}
+
+	if len(query) > maxSearchQueryLen {
+		return fmt.Errorf("invalid q: can't be longer than %d bytes", maxSearchQueryLen)
+	}
+
+	return nil
+}
+
+func checkRange(name string, low, high *int) error {
+	if (low != nil && *low < 0) || (high != nil && *high < 0) {
+		return fmt.Errorf("invalid %s range: can't be less than 0", name)
+	}
+
+	if low != nil && high != nil && *low > *high {
+		return fmt.Errorf("invalid %s range: min_%s can't be greater than max_%s", name, name, name)
 	}
 
 	return nil
@@ -0,0 +1,176 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/HeadGardener/coursework/internal/models"
+)
+
+const (
+	responseTypeCode = "code"
+	maxClientNameLen = 64
+)
+
+var grantTypes = []string{
+	models.GrantAuthorizationCode,
+	models.GrantClientCredentials,
+	models.GrantRefreshToken,
+}
+
+// AuthorizeReq is an authorization request of RFC 6749, sent as query parameters
+// to get the consent screen and as JSON together with the user's decision.
+type AuthorizeReq struct {
+	ResponseType        string `form:"response_type" json:"response_type"`
+	ClientID            string `form:"client_id" json:"client_id"`
+	RedirectURI         string `form:"redirect_uri" json:"redirect_uri"`
+	Scope               string `form:"scope" json:"scope"`
+	State               string `form:"state" json:"state"`
+	CodeChallenge       string `form:"code_challenge" json:"code_challenge"`
+	CodeChallengeMethod string `form:"code_challenge_method" json:"code_challenge_method"`
+}
+
+type ConsentReq struct {
+	AuthorizeReq
+	Approve bool `json:"approve"`
+}
+
+// TokenReq is a form encoded token endpoint request, client credentials may also
+// come in the Authorization header.
+type TokenReq struct {
+	GrantType    string `form:"grant_type"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	CodeVerifier string `form:"code_verifier"`
+	RefreshToken string `form:"refresh_token"`
+	Scope        string `form:"scope"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+}
+
+// TokenActionReq is an introspection or revocation request, token_type_hint is
+// accepted but not needed.
+type TokenActionReq struct {
+	Token         string `form:"token"`
+	TokenTypeHint string `form:"token_type_hint"`
+	ClientID      string `form:"client_id"`
+	ClientSecret  string `form:"client_secret"`
+}
+
+type CreateOAuthClientReq struct {
+	Name         string   `json:"name"`
+	RedirectURIs []string `json:"redirect_uris"`
+	GrantTypes   []string `json:"grant_types"`
+	Scopes       []string `json:"scopes"`
+	Public       bool     `json:"public"`
+}
+
+func (r *AuthorizeReq) Validate() error {
+	if r.ResponseType != responseTypeCode {
+		return errors.New("invalid response_type: only code is supported")
+	}
+
+	if r.ClientID == "" {
+		return errors.New("invalid client_id: can't be empty")
+	}
+
+	return nil
+}
+
+func (r *AuthorizeReq) Request() models.AuthorizationRequest {
+	return models.AuthorizationRequest{
+		ClientID:            r.ClientID,
+		RedirectURI:         r.RedirectURI,
+		Scopes:              models.ParseScopes(r.Scope),
+		State:               r.State,
+		CodeChallenge:       r.CodeChallenge,
+		CodeChallengeMethod: r.CodeChallengeMethod,
+	}
+}
+
+func (r *TokenReq) Validate() error {
+	switch r.GrantType {
+	case "":
+		return errors.New("invalid grant_type: can't be empty")
+	case models.GrantAuthorizationCode:
+		if r.Code == "" || r.CodeVerifier == "" {
+			return errors.New("invalid request: code and code_verifier are required")
+		}
+	case models.GrantRefreshToken:
+		if r.RefreshToken == "" {
+			return errors.New("invalid request: refresh_token is required")
+		}
+	}
+
+	return nil
+}
+
+func (r *TokenReq) Request() models.TokenRequest {
+	return models.TokenRequest{
+		GrantType:    r.GrantType,
+		Code:         r.Code,
+		RedirectURI:  r.RedirectURI,
+		CodeVerifier: r.CodeVerifier,
+		RefreshToken: r.RefreshToken,
+		Scopes:       models.ParseScopes(r.Scope),
+		ClientID:     r.ClientID,
+		ClientSecret: r.ClientSecret,
+	}
+}
+
+func (r *TokenActionReq) Validate() error {
+	if r.Token == "" {
+		return errors.New("invalid token: can't be empty")
+	}
+
+	return nil
+}
+
+func (r *CreateOAuthClientReq) Validate() error {
+	if r.Name == "" || len(r.Name) > maxClientNameLen {
+		return errors.New("invalid name: must be between 1 and 64 characters")
+	}
+
+	if len(r.GrantTypes) == 0 {
+		return errors.New("invalid grant_types: can't be empty")
+	}
+
+	for _, grantType := range r.GrantTypes {
+		if !slices.Contains(grantTypes, grantType) {
+			return fmt.Errorf("invalid grant_types: unknown grant type %s", grantType)
+		}
+	}
+
+	if r.Public && slices.Contains(r.GrantTypes, models.GrantClientCredentials) {
+		return errors.New("invalid grant_types: public clients can't use client_credentials")
+	}
+
+	if slices.Contains(r.GrantTypes, models.GrantAuthorizationCode) && len(r.RedirectURIs) == 0 {
+		return errors.New("invalid redirect_uris: required for authorization_code")
+	}
+
+	for _, redirectURI := range r.RedirectURIs {
+		u, err := url.Parse(redirectURI)
+		if err != nil || !u.IsAbs() || u.Fragment != "" || strings.ContainsAny(redirectURI, " \t\n") {
+			return fmt.Errorf("invalid redirect_uris: %s must be an absolute URL without fragment", redirectURI)
+		}
+	}
+
+	return nil
+}
+
+func (r *CreateOAuthClientReq) ScopeList() []models.Permission {
+	return toPermissions(r.Scopes)
+}
+
+func toPermissions(names []string) []models.Permission {
+	permissions := make([]models.Permission, len(names))
+	for i := range names {
+		permissions[i] = models.Permission(names[i])
+	}
+
+	return permissions
+}
@@ -0,0 +1,31 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+)
+
+// OIDCCallbackReq is the redirect back from an identity provider, it carries either
+// a code or an error.
+type OIDCCallbackReq struct {
+	Code             string `form:"code"`
+	State            string `form:"state"`
+	Error            string `form:"error"`
+	ErrorDescription string `form:"error_description"`
+}
+
+func (r *OIDCCallbackReq) Validate() error {
+	if r.Error != "" {
+		return fmt.Errorf("identity provider returned %s: %s", r.Error, r.ErrorDescription)
+	}
+
+	if r.Code == "" {
+		return errors.New("invalid code: can't be empty")
+	}
+
+	if r.State == "" {
+		return errors.New("invalid state: can't be empty")
+	}
+
+	return nil
+}
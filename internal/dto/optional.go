@@ -0,0 +1,47 @@
+package dto
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// Optional is a field of a JSON Merge Patch. It tells a field left out of the document
+// (Set is false) from an explicit null (Null is true) and from a value.
+type Optional[T any] struct {
+	Set   bool
+	Null  bool
+	Value T
+}
+
+// UnmarshalJSON is only called for fields present in the document.
+func (o *Optional[T]) UnmarshalJSON(b []byte) error {
+	o.Set = true
+
+	if string(b) == "null" {
+		o.Null = true
+		return nil
+	}
+
+	return json.Unmarshal(b, &o.Value)
+}
+
+// Decimal is an amount given either as a JSON string or a number, kept as written
+// so it can be parsed exactly.
+type Decimal string
+
+func (d *Decimal) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*d = Decimal(s)
+		return nil
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return errors.New("amount must be a decimal string or a number")
+	}
+
+	*d = Decimal(n)
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package dto
+
+import "errors"
+
+// UpdateProfileReq changes the fields that are set, empty ones are left as they are.
+type UpdateProfileReq struct {
+	Username string `json:"username"`
+	Name     string `json:"name"`
+}
+
+type DeleteAccountReq struct {
+	Password string `json:"password"`
+}
+
+func (r *UpdateProfileReq) Validate() error {
+	if r.Username == "" && r.Name == "" {
+		return errors.New("invalid profile: nothing to update")
+	}
+
+	if r.Username != "" && !checkUsername.MatchString(r.Username) {
+		return errors.New("invalid username: must contain only letters, numbers and symbols(_-) ")
+	}
+
+	if r.Name != "" && !checkName.MatchString(r.Name) {
+		return errors.New("invalid name: must contain only letters")
+	}
+
+	return nil
+}
+
+func (r *DeleteAccountReq) Validate() error {
+	if r.Password == "" {
+		return errors.New("invalid password: can't be empty")
+	}
+
+	return nil
+}
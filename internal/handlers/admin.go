@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/HeadGardener/coursework/internal/dto"
+	"github.com/HeadGardener/coursework/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) listUsers(c *gin.Context) {
+	var req dto.ListUsersReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while decoding list users request", err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while validating list users request", err)
+		return
+	}
+
+	users, err := h.authService.ListUsers(c, req.Filter())
+	if err != nil {
+		newErrResponse(c, http.StatusInternalServerError, "failed while listing users", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+func (h *Handler) viewUser(c *gin.Context) {
+	user, err := h.authService.GetUser(c, c.Param("id"))
+	if err != nil {
+		newErrResponse(c, userErrStatus(err), "failed while getting user", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+func (h *Handler) setUserRoles(c *gin.Context) {
+	var req dto.SetRolesReq
+	if err := c.BindJSON(&req); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while decoding set roles request", err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while validating set roles request", err)
+		return
+	}
+
+	if err := h.authService.SetUserRoles(c, c.Param("id"), req.Roles); err != nil {
+		newErrResponse(c, userErrStatus(err), "failed while setting roles", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]any{
+		"status": "updated",
+	})
+}
+
+func (h *Handler) disableUser(c *gin.Context) {
+	h.setUserDisabled(c, true)
+}
+
+func (h *Handler) enableUser(c *gin.Context) {
+	h.setUserDisabled(c, false)
+}
+
+func (h *Handler) setUserDisabled(c *gin.Context, disabled bool) {
+	actorID, err := getUserID(c)
+	if err != nil {
+		newErrResponse(c, http.StatusForbidden, "failed while getting user id", err)
+		return
+	}
+
+	if err = h.authService.SetUserDisabled(c, actorID, c.Param("id"), disabled); err != nil {
+		newErrResponse(c, userErrStatus(err), "failed while updating user", err)
+		return
+	}
+
+	status := "enabled"
+	if disabled {
+		status = "disabled"
+	}
+
+	c.JSON(http.StatusOK, map[string]any{
+		"status": status,
+	})
+}
+
+func (h *Handler) forceLogout(c *gin.Context) {
+	if err := h.authService.ForceLogout(c, c.Param("id")); err != nil {
+		newErrResponse(c, userErrStatus(err), "failed while logging user out", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]any{
+		"status": "logged out",
+	})
+}
+
+func userErrStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrUnknownRole),
+		errors.Is(err, service.ErrDisablingSelf):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrUsernameTaken):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
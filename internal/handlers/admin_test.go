@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mock_service "github.com/HeadGardener/coursework/internal/handlers/mocks"
+	"github.com/HeadGardener/coursework/internal/lib/auth"
+	"github.com/HeadGardener/coursework/internal/models"
+	"github.com/HeadGardener/coursework/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"go.uber.org/mock/gomock"
+)
+
+func TestListUsersHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAuthService)
+
+	testTable := []struct {
+		name                 string
+		query                string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:  "ok",
+			query: "?q=bob&page=2",
+			mockBehavior: func(s *mock_service.MockAuthService) {
+				s.EXPECT().ListUsers(gomock.Any(), models.UserFilter{Query: "bob", Page: 2, PerPage: 20}).
+					Return(models.UserPage{Users: []models.UserInfo{}, Total: 0, Page: 2, PerPage: 20}, nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"users":[],"total":0,"page":2,"per_page":20}`,
+		},
+		{
+			name:                 "invalid per page",
+			query:                "?per_page=1000",
+			mockBehavior:         func(s *mock_service.MockAuthService) {},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"Msg":"failed while validating list users request","Error":"invalid per_page: must be between 1 and 100"}`,
+		},
+		{
+			name:                 "page too deep",
+			query:                "?page=9223372036854775807",
+			mockBehavior:         func(s *mock_service.MockAuthService) {},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"Msg":"failed while validating list users request","Error":"invalid page: must be between 1 and 10000"}`,
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			authService := mock_service.NewMockAuthService(c)
+			tc.mockBehavior(authService)
+
+			handler := NewHandler(authService, nil, nil, nil, nil, nil, nil)
+
+			gin.SetMode(gin.ReleaseMode)
+			router := gin.New()
+			router.Use(gin.Recovery())
+			router.GET("/api/admin/users", handler.listUsers)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "/api/admin/users"+tc.query, nil)
+
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+			assert.Equal(t, tc.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestSetUserRolesHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAuthService)
+
+	testTable := []struct {
+		name                 string
+		inputBody            string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:      "ok",
+			inputBody: `{"roles": ["bartender"]}`,
+			mockBehavior: func(s *mock_service.MockAuthService) {
+				s.EXPECT().SetUserRoles(gomock.Any(), "2", []string{"bartender"}).Return(nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"status":"updated"}`,
+		},
+		{
+			name:      "unknown role",
+			inputBody: `{"roles": ["owner"]}`,
+			mockBehavior: func(s *mock_service.MockAuthService) {
+				s.EXPECT().SetUserRoles(gomock.Any(), "2", []string{"owner"}).
+					Return(fmt.Errorf("%w: owner", service.ErrUnknownRole))
+			},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"Msg":"failed while setting roles","Error":"unknown role: owner"}`,
+		},
+		{
+			name:      "user not found",
+			inputBody: `{"roles": ["user"]}`,
+			mockBehavior: func(s *mock_service.MockAuthService) {
+				s.EXPECT().SetUserRoles(gomock.Any(), "2", []string{"user"}).Return(service.ErrUserNotFound)
+			},
+			expectedStatusCode:   http.StatusNotFound,
+			expectedResponseBody: `{"Msg":"failed while setting roles","Error":"user not found"}`,
+		},
+		{
+			name:                 "no roles",
+			inputBody:            `{"roles": []}`,
+			mockBehavior:         func(s *mock_service.MockAuthService) {},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"Msg":"failed while validating set roles request","Error":"invalid roles: can't be empty"}`,
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			authService := mock_service.NewMockAuthService(c)
+			tc.mockBehavior(authService)
+
+			handler := NewHandler(authService, nil, nil, nil, nil, nil, nil)
+
+			gin.SetMode(gin.ReleaseMode)
+			router := gin.New()
+			router.Use(gin.Recovery())
+			router.PUT("/api/admin/users/:id/roles", handler.setUserRoles)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("PUT", "/api/admin/users/2/roles", bytes.NewBufferString(tc.inputBody))
+
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+			assert.Equal(t, tc.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestDisableUserHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAuthService)
+
+	testTable := []struct {
+		name                 string
+		userID               string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:   "ok",
+			userID: "2",
+			mockBehavior: func(s *mock_service.MockAuthService) {
+				s.EXPECT().SetUserDisabled(gomock.Any(), "1", "2", true).Return(nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"status":"disabled"}`,
+		},
+		{
+			name:   "self",
+			userID: "1",
+			mockBehavior: func(s *mock_service.MockAuthService) {
+				s.EXPECT().SetUserDisabled(gomock.Any(), "1", "1", true).Return(service.ErrDisablingSelf)
+			},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"Msg":"failed while updating user","Error":"can't disable own account"}`,
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			authService := mock_service.NewMockAuthService(c)
+			tc.mockBehavior(authService)
+
+			handler := NewHandler(authService, nil, nil, nil, nil, nil, nil)
+
+			gin.SetMode(gin.ReleaseMode)
+			router := gin.New()
+			router.Use(gin.Recovery())
+			router.Use(func(c *gin.Context) {
+				c.Set(userCtx, auth.UserAttributes{ID: "1"})
+			})
+			router.POST("/api/admin/users/:id/disable", handler.disableUser)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "/api/admin/users/"+tc.userID+"/disable", nil)
+
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+			assert.Equal(t, tc.expectedResponseBody, w.Body.String())
+		})
+	}
+}
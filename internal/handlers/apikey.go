@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/HeadGardener/coursework/internal/dto"
+	"github.com/HeadGardener/coursework/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) listAPIKeys(c *gin.Context) {
+	var req dto.ListAPIKeysReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while decoding list api keys request", err)
+		return
+	}
+
+	keys, err := h.apiKeyService.List(c, req.UserID)
+	if err != nil {
+		newErrResponse(c, http.StatusInternalServerError, "failed while listing api keys", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+func (h *Handler) createAPIKey(c *gin.Context) {
+	var req dto.CreateAPIKeyReq
+	if err := c.BindJSON(&req); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while decoding create api key request", err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while validating create api key request", err)
+		return
+	}
+
+	if req.UserID == "" {
+		userID, err := getUserID(c)
+		if err != nil {
+			newErrResponse(c, http.StatusForbidden, "failed while getting user id", err)
+			return
+		}
+
+		req.UserID = userID
+	}
+
+	key, err := h.apiKeyService.Create(c, req.UserID, req.Name, req.ScopeList(), req.ExpiresAt())
+	if err != nil {
+		newErrResponse(c, apiKeyErrStatus(err), "failed while creating api key", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, key)
+}
+
+func (h *Handler) revokeAPIKey(c *gin.Context) {
+	if err := h.apiKeyService.Revoke(c, c.Param("id")); err != nil {
+		newErrResponse(c, apiKeyErrStatus(err), "failed while revoking api key", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]any{
+		"status": "revoked",
+	})
+}
+
+func apiKeyErrStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrUserNotFound),
+		errors.Is(err, service.ErrAPIKeyNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrScopeNotGranted):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -1,9 +1,13 @@
 package handlers
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/HeadGardener/coursework/internal/dto"
+	"github.com/HeadGardener/coursework/internal/service"
 	"github.com/gin-gonic/gin"
 )
 
@@ -20,7 +24,13 @@ func (h *Handler) signUp(c *gin.Context) {
 		return
 	}
 
-	id, err := h.authService.SignUp(c, req.Username, req.Name, req.Age, req.Password)
+	birthDate, err := req.ParseBirthDate()
+	if err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while validating sign up request", err)
+		return
+	}
+
+	id, err := h.authService.SignUp(c, req.Username, req.Name, birthDate, req.Region, req.Password)
 	if err != nil {
 		newErrResponse(c, http.StatusInternalServerError, "failed while signing up", err)
 		return
@@ -44,47 +54,115 @@ func (h *Handler) signIn(c *gin.Context) {
 		return
 	}
 
-	tokens, err := h.authService.SignIn(c, req.Username, req.Password)
+	result, err := h.authService.SignIn(c, req.Username, req.Password, getClientInfo(c))
 	if err != nil {
+		var retryErr *service.RetryAfterError
+		if errors.As(err, &retryErr) {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryErr.RetryAfter.Seconds()))))
+			newErrResponse(c, http.StatusTooManyRequests, "failed while signing in", err)
+			return
+		}
+
+		if errors.Is(err, service.ErrUserDisabled) {
+			newErrResponse(c, http.StatusForbidden, "failed while signing in", err)
+			return
+		}
+
 		newErrResponse(c, http.StatusInternalServerError, "failed while signing in", err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, tokens)
+	if result.MFARequired != nil {
+		c.JSON(http.StatusAccepted, result.MFARequired)
+		return
+	}
+
+	h.writeTokens(c, http.StatusCreated, result.Tokens)
+}
+
+func (h *Handler) completeSignIn(c *gin.Context) {
+	var req dto.CompleteSignInReq
+
+	if err := c.BindJSON(&req); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while decoding complete sign in request", err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while validating complete sign in request", err)
+		return
+	}
+
+	tokens, err := h.authService.CompleteSignIn(c, req.MFAToken, req.Code, getClientInfo(c))
+	if err != nil {
+		newErrResponse(c, http.StatusUnauthorized, "failed while completing sign in", err)
+		return
+	}
+
+	h.writeTokens(c, http.StatusCreated, tokens)
 }
 
+// refresh takes the tokens from the body, or in cookie mode from the cookies, which
+// needs the CSRF token as well.
 func (h *Handler) refresh(c *gin.Context) {
 	var req dto.RefreshRequest
-	if err := c.BindJSON(&req); err != nil {
+	if h.cookieMode(c) {
+		if err := checkCSRF(c); err != nil {
+			newErrResponse(c, http.StatusForbidden, "failed while checking csrf token", err)
+			return
+		}
+
+		req.AccessToken, _ = c.Cookie(accessTokenCookie)
+		req.RefreshToken, _ = c.Cookie(refreshTokenCookie)
+	} else if err := c.BindJSON(&req); err != nil {
 		newErrResponse(c, http.StatusBadRequest, "failed while decoding RefreshRequest", err)
 		return
 	}
 
-	tokens, err := h.authService.Refresh(c.Request.Context(), req.AccessToken, req.RefreshToken)
+	tokens, err := h.authService.Refresh(c.Request.Context(), req.AccessToken, req.RefreshToken, getClientInfo(c))
+	if errors.Is(err, service.ErrUserDisabled) {
+		newErrResponse(c, http.StatusForbidden, "failed while refreshing", err)
+		return
+	}
+
 	if err != nil {
 		newErrResponse(c, http.StatusBadRequest, "failed while refreshing", err)
 		return
 	}
 
-	c.JSON(http.StatusOK, map[string]any{
-		"access_token":  tokens.AccessToken,
-		"refresh_token": tokens.RefreshToken,
-	})
+	h.writeTokens(c, http.StatusOK, tokens)
 }
 
 func (h *Handler) logout(c *gin.Context) {
-	userID, err := getUserID(c)
+	userAttributes, err := getUserAttributes(c)
 	if err != nil {
-		newErrResponse(c, http.StatusForbidden, "failed while getting user id", err)
+		newErrResponse(c, http.StatusForbidden, "failed while getting user attributes", err)
 		return
 	}
 
-	if err = h.authService.LogOut(c, userID); err != nil {
+	if err = h.authService.LogOut(c, userAttributes); err != nil {
 		newErrResponse(c, http.StatusInternalServerError, "failed while logging out", err)
 		return
 	}
 
+	h.clearTokenCookies(c)
+
 	c.JSON(http.StatusOK, map[string]any{
 		"status": "logged out",
 	})
 }
+
+func (h *Handler) jwks(c *gin.Context) {
+	c.JSON(http.StatusOK, h.authService.GetJWKS())
+}
+
+func (h *Handler) unlockUser(c *gin.Context) {
+	if err := h.authService.UnlockUser(c, c.Param("id")); err != nil {
+		newErrResponse(c, http.StatusInternalServerError, "failed while unlocking user", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]any{
+		"status": "unlocked",
+	})
+}
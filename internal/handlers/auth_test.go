@@ -6,10 +6,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/HeadGardener/coursework/internal/config"
 	"github.com/HeadGardener/coursework/internal/dto"
 	mock_service "github.com/HeadGardener/coursework/internal/handlers/mocks"
 	"github.com/HeadGardener/coursework/internal/models"
+	"github.com/HeadGardener/coursework/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/assert/v2"
 	"go.uber.org/mock/gomock"
@@ -18,6 +21,8 @@ import (
 func TestSignUpHandler(t *testing.T) {
 	type mockBehavior func(s *mock_service.MockAuthService, user dto.SignUpReq)
 
+	birthDate := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
 	testTable := []struct {
 		name                 string
 		inputBody            string
@@ -31,17 +36,17 @@ func TestSignUpHandler(t *testing.T) {
 			inputBody: `{
     						"username": "user",
           					"name": "test",
-               				"age": 20,
+               				"birth_date": "2000-01-01",
                    			"password": "testPass"
                       	}`,
 			user: dto.SignUpReq{
-				Username: "user",
-				Name:     "test",
-				Age:      20,
-				Password: "testPass",
+				Username:  "user",
+				Name:      "test",
+				BirthDate: "2000-01-01",
+				Password:  "testPass",
 			},
 			mockBehavior: func(s *mock_service.MockAuthService, user dto.SignUpReq) {
-				s.EXPECT().SignUp(gomock.Any(), user.Username, user.Name, user.Age, user.Password).Return("1", nil)
+				s.EXPECT().SignUp(gomock.Any(), user.Username, user.Name, birthDate, user.Region, user.Password).Return("1", nil)
 			},
 			expectedStatusCode:   http.StatusCreated,
 			expectedResponseBody: `{"id":"1"}`,
@@ -51,7 +56,7 @@ func TestSignUpHandler(t *testing.T) {
 			inputBody: `{
     						"username": "user",
           					"name": "tes3t",
-               				"age": 20,
+               				"birth_date": "2000-01-01",
                    			"password": "testPass"
                       	}`,
 			mockBehavior:         func(s *mock_service.MockAuthService, user dto.SignUpReq) {},
@@ -59,33 +64,33 @@ func TestSignUpHandler(t *testing.T) {
 			expectedResponseBody: `{"Msg":"failed while validating sign up request","Error":"invalid name: must contain only letters"}`,
 		},
 		{
-			name: "invalid age",
+			name: "invalid birth date",
 			inputBody: `{
     						"username": "user",
           					"name": "test",
-               				"age": -1,
+               				"birth_date": "2999-01-01",
                    			"password": "testPass"
                       	}`,
 			mockBehavior:         func(s *mock_service.MockAuthService, user dto.SignUpReq) {},
 			expectedStatusCode:   http.StatusBadRequest,
-			expectedResponseBody: `{"Msg":"failed while validating sign up request","Error":"invalid age: can't be less than 0 or greater than 111"}`,
+			expectedResponseBody: `{"Msg":"failed while validating sign up request","Error":"invalid birth date: can't be in the future or more than 111 years ago"}`,
 		},
 		{
 			name: "service failure",
 			inputBody: `{
     						"username": "user",
           					"name": "test",
-               				"age": 20,
+               				"birth_date": "2000-01-01",
                    			"password": "testPass"
                       	}`,
 			user: dto.SignUpReq{
-				Username: "user",
-				Name:     "test",
-				Age:      20,
-				Password: "testPass",
+				Username:  "user",
+				Name:      "test",
+				BirthDate: "2000-01-01",
+				Password:  "testPass",
 			},
 			mockBehavior: func(s *mock_service.MockAuthService, user dto.SignUpReq) {
-				s.EXPECT().SignUp(gomock.Any(), user.Username, user.Name, user.Age, user.Password).Return("", errors.New(""))
+				s.EXPECT().SignUp(gomock.Any(), user.Username, user.Name, birthDate, user.Region, user.Password).Return("", errors.New(""))
 			},
 			expectedStatusCode:   http.StatusInternalServerError,
 			expectedResponseBody: `{"Msg":"failed while signing up","Error":""}`,
@@ -100,7 +105,7 @@ func TestSignUpHandler(t *testing.T) {
 			auth := mock_service.NewMockAuthService(c)
 			tc.mockBehavior(auth, tc.user)
 
-			handler := NewHandler(auth, nil)
+			handler := NewHandler(auth, nil, nil, nil, nil, nil, nil)
 
 			gin.SetMode(gin.ReleaseMode)
 			router := gin.New()
@@ -140,14 +145,37 @@ func TestSignInHandler(t *testing.T) {
 				Password: "testPass",
 			},
 			mockBehavior: func(s *mock_service.MockAuthService, user dto.SignInReq) {
-				s.EXPECT().SignIn(gomock.Any(), user.Username, user.Password).Return(models.Tokens{
-					AccessToken:  "token1",
-					RefreshToken: "token2",
+				s.EXPECT().SignIn(gomock.Any(), user.Username, user.Password, gomock.Any()).Return(models.SignInResult{
+					Tokens: models.Tokens{
+						AccessToken:  "token1",
+						RefreshToken: "token2",
+					},
 				}, nil)
 			},
 			expectedStatusCode:   http.StatusCreated,
 			expectedResponseBody: `{"access_token":"token1","refresh_token":"token2"}`,
 		},
+		{
+			name: "mfa required",
+			inputBody: `{
+    						"username": "user",
+                   			"password": "testPass"
+                      	}`,
+			user: dto.SignInReq{
+				Username: "user",
+				Password: "testPass",
+			},
+			mockBehavior: func(s *mock_service.MockAuthService, user dto.SignInReq) {
+				s.EXPECT().SignIn(gomock.Any(), user.Username, user.Password, gomock.Any()).Return(models.SignInResult{
+					MFARequired: &models.MFARequired{
+						MFAToken:  "mfa",
+						ExpiresAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+					},
+				}, nil)
+			},
+			expectedStatusCode:   http.StatusAccepted,
+			expectedResponseBody: `{"mfa_token":"mfa","expires_at":"2024-01-01T00:00:00Z"}`,
+		},
 		{
 			name: "invalid username",
 			inputBody: `{
@@ -169,11 +197,28 @@ func TestSignInHandler(t *testing.T) {
 				Password: "testPass",
 			},
 			mockBehavior: func(s *mock_service.MockAuthService, user dto.SignInReq) {
-				s.EXPECT().SignIn(gomock.Any(), user.Username, user.Password).Return(models.Tokens{}, errors.New(""))
+				s.EXPECT().SignIn(gomock.Any(), user.Username, user.Password, gomock.Any()).Return(models.SignInResult{}, errors.New(""))
 			},
 			expectedStatusCode:   http.StatusInternalServerError,
 			expectedResponseBody: `{"Msg":"failed while signing in","Error":""}`,
 		},
+		{
+			name: "locked out",
+			inputBody: `{
+    						"username": "user",
+                   			"password": "testPass"
+                      	}`,
+			user: dto.SignInReq{
+				Username: "user",
+				Password: "testPass",
+			},
+			mockBehavior: func(s *mock_service.MockAuthService, user dto.SignInReq) {
+				s.EXPECT().SignIn(gomock.Any(), user.Username, user.Password, gomock.Any()).Return(models.SignInResult{},
+					&service.RetryAfterError{Err: service.ErrTooManyAttempts, RetryAfter: 30 * time.Second})
+			},
+			expectedStatusCode:   http.StatusTooManyRequests,
+			expectedResponseBody: `{"Msg":"failed while signing in","Error":"too many failed sign in attempts: retry after 30s"}`,
+		},
 	}
 
 	for _, tc := range testTable {
@@ -184,7 +229,7 @@ func TestSignInHandler(t *testing.T) {
 			auth := mock_service.NewMockAuthService(c)
 			tc.mockBehavior(auth, tc.user)
 
-			handler := NewHandler(auth, nil)
+			handler := NewHandler(auth, nil, nil, nil, nil, nil, nil)
 
 			gin.SetMode(gin.ReleaseMode)
 			router := gin.New()
@@ -201,3 +246,124 @@ func TestSignInHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestRefreshHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAuthService)
+
+	tokens := models.Tokens{AccessToken: "new-access", RefreshToken: "new-refresh"}
+
+	testTable := []struct {
+		name               string
+		inputBody          string
+		cookieMode         bool
+		cookies            map[string]string
+		csrfHeader         string
+		mockBehavior       mockBehavior
+		expectedStatusCode int
+		expectedCookies    []string
+	}{
+		{
+			name:      "tokens in body",
+			inputBody: `{"access_token":"access","refresh_token":"refresh"}`,
+			mockBehavior: func(s *mock_service.MockAuthService) {
+				s.EXPECT().Refresh(gomock.Any(), "access", "refresh", gomock.Any()).Return(tokens, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:       "tokens in cookies",
+			cookieMode: true,
+			cookies: map[string]string{
+				accessTokenCookie:  "access",
+				refreshTokenCookie: "refresh",
+				csrfTokenCookie:    "csrf",
+			},
+			csrfHeader: "csrf",
+			mockBehavior: func(s *mock_service.MockAuthService) {
+				s.EXPECT().Refresh(gomock.Any(), "access", "refresh", gomock.Any()).Return(tokens, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedCookies:    []string{accessTokenCookie, refreshTokenCookie, csrfTokenCookie},
+		},
+		{
+			name:       "tokens in cookies without csrf token",
+			cookieMode: true,
+			cookies: map[string]string{
+				accessTokenCookie:  "access",
+				refreshTokenCookie: "refresh",
+				csrfTokenCookie:    "csrf",
+			},
+			mockBehavior:       func(s *mock_service.MockAuthService) {},
+			expectedStatusCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			auth := mock_service.NewMockAuthService(c)
+			tc.mockBehavior(auth)
+
+			handler := NewHandler(auth, nil, nil, nil, nil, nil, nil)
+			handler.cookies = config.CookieConfig{
+				Enabled:  true,
+				Secure:   true,
+				SameSite: http.SameSiteStrictMode,
+				MaxAge:   time.Hour,
+			}
+
+			gin.SetMode(gin.ReleaseMode)
+			router := gin.New()
+			router.Use(gin.Recovery())
+			router.POST("/api/auth/refresh", handler.refresh)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "/api/auth/refresh", bytes.NewBufferString(tc.inputBody))
+			if tc.cookieMode {
+				r.Header.Set(authModeHeader, authModeCookie)
+			}
+
+			for name, value := range tc.cookies {
+				r.AddCookie(&http.Cookie{Name: name, Value: value})
+			}
+
+			if tc.csrfHeader != "" {
+				r.Header.Set(csrfHeader, tc.csrfHeader)
+			}
+
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+
+			if tc.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			if !tc.cookieMode {
+				assert.Equal(t, `{"access_token":"new-access","refresh_token":"new-refresh"}`, w.Body.String())
+				assert.Equal(t, 0, len(w.Result().Cookies()))
+
+				return
+			}
+
+			cookies := make(map[string]*http.Cookie)
+			for _, cookie := range w.Result().Cookies() {
+				cookies[cookie.Name] = cookie
+			}
+
+			for _, name := range tc.expectedCookies {
+				cookie, ok := cookies[name]
+				assert.Equal(t, true, ok)
+				assert.Equal(t, true, cookie.Secure)
+				assert.Equal(t, http.SameSiteStrictMode, cookie.SameSite)
+				assert.Equal(t, name != csrfTokenCookie, cookie.HttpOnly)
+			}
+
+			assert.Equal(t, "new-access", cookies[accessTokenCookie].Value)
+			assert.Equal(t, "new-refresh", cookies[refreshTokenCookie].Value)
+			assert.Equal(t, `{"csrf_token":"`+cookies[csrfTokenCookie].Value+`"}`, w.Body.String())
+		})
+	}
+}
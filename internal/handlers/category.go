@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/HeadGardener/coursework/internal/dto"
+	"github.com/HeadGardener/coursework/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) viewCategories(c *gin.Context) {
+	customer, err := getCustomer(c)
+	if err != nil {
+		newErrResponse(c, http.StatusForbidden, "failed while identifying age", err)
+		return
+	}
+
+	tree, err := h.categoryService.Tree(c, customer)
+	if err != nil {
+		newErrResponse(c, http.StatusInternalServerError, "failed while getting categories", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tree)
+}
+
+func (h *Handler) addCategory(c *gin.Context) {
+	var req dto.CategoryRequest
+	if err := c.BindJSON(&req); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while decoding category request", err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while validating category request", err)
+		return
+	}
+
+	id, err := h.categoryService.Create(c, req.Category())
+	if err != nil {
+		newErrResponse(c, categoryErrStatus(err), "failed while adding category", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, map[string]any{
+		"id": id,
+	})
+}
+
+func (h *Handler) updateCategory(c *gin.Context) {
+	categoryID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while checking id", err)
+		return
+	}
+
+	var req dto.CategoryRequest
+	if err = c.BindJSON(&req); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while decoding category request", err)
+		return
+	}
+
+	if err = req.Validate(); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while validating category request", err)
+		return
+	}
+
+	if err = h.categoryService.Update(c, categoryID, req.Category()); err != nil {
+		newErrResponse(c, categoryErrStatus(err), "failed while updating category", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]any{
+		"status": "updated",
+	})
+}
+
+func (h *Handler) deleteCategory(c *gin.Context) {
+	categoryID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while checking id", err)
+		return
+	}
+
+	if err = h.categoryService.Delete(c, categoryID); err != nil {
+		newErrResponse(c, categoryErrStatus(err), "failed while deleting category", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]any{
+		"status": "deleted",
+	})
+}
+
+func categoryErrStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrCategoryNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrParentNotFound),
+		errors.Is(err, service.ErrCategoryCycle):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrCategoryExists),
+		errors.Is(err, service.ErrCategoryInUse):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
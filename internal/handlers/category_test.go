@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mock_service "github.com/HeadGardener/coursework/internal/handlers/mocks"
+	"github.com/HeadGardener/coursework/internal/models"
+	"github.com/HeadGardener/coursework/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAddCategoryHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockCategoryService, category *models.Category)
+
+	parentID := 1
+	spirits := models.AgeCategorySpirits
+
+	testTable := []struct {
+		name                 string
+		inputBody            string
+		category             *models.Category
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:      "ok",
+			inputBody: `{"name":" Bourbon ","parent_id":1,"age_category":"spirits"}`,
+			category:  &models.Category{Name: "Bourbon", ParentID: &parentID, AgeCategory: &spirits},
+			mockBehavior: func(s *mock_service.MockCategoryService, category *models.Category) {
+				s.EXPECT().Create(gomock.Any(), category).Return(3, nil)
+			},
+			expectedStatusCode:   http.StatusCreated,
+			expectedResponseBody: `{"id":3}`,
+		},
+		{
+			name:                 "empty name",
+			inputBody:            `{"name":" "}`,
+			mockBehavior:         func(s *mock_service.MockCategoryService, category *models.Category) {},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"Msg":"failed while validating category request","Error":"invalid name: must be between 1 and 64 characters"}`,
+		},
+		{
+			name:                 "invalid age category",
+			inputBody:            `{"name":"Bourbon","age_category":"adult"}`,
+			mockBehavior:         func(s *mock_service.MockCategoryService, category *models.Category) {},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"Msg":"failed while validating category request","Error":"invalid age category: must be one of soft, beer_wine, spirits"}`,
+		},
+		{
+			name:      "name taken",
+			inputBody: `{"name":"Whiskey"}`,
+			category:  &models.Category{Name: "Whiskey"},
+			mockBehavior: func(s *mock_service.MockCategoryService, category *models.Category) {
+				s.EXPECT().Create(gomock.Any(), category).Return(0, service.ErrCategoryExists)
+			},
+			expectedStatusCode:   http.StatusConflict,
+			expectedResponseBody: `{"Msg":"failed while adding category","Error":"category with this name already exists"}`,
+		},
+		{
+			name:      "unknown parent",
+			inputBody: `{"name":"Bourbon","parent_id":1}`,
+			category:  &models.Category{Name: "Bourbon", ParentID: &parentID},
+			mockBehavior: func(s *mock_service.MockCategoryService, category *models.Category) {
+				s.EXPECT().Create(gomock.Any(), category).Return(0, service.ErrParentNotFound)
+			},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"Msg":"failed while adding category","Error":"parent category not found"}`,
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			category := mock_service.NewMockCategoryService(c)
+			tc.mockBehavior(category, tc.category)
+
+			handler := NewHandler(nil, nil, nil, nil, nil, nil, category)
+
+			gin.SetMode(gin.ReleaseMode)
+			router := gin.New()
+			router.Use(gin.Recovery())
+			router.POST("/api/categories", handler.addCategory)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "/api/categories", bytes.NewBufferString(tc.inputBody))
+
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+			assert.Equal(t, tc.expectedResponseBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"github.com/HeadGardener/coursework/internal/lib/auth"
+	"github.com/HeadGardener/coursework/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	authModeHeader = "X-Auth-Mode"
+	authModeCookie = "cookie"
+	csrfHeader     = "X-CSRF-Token"
+
+	accessTokenCookie  = "access_token"
+	refreshTokenCookie = "refresh_token"
+	csrfTokenCookie    = "csrf_token"
+
+	accessTokenPath  = "/api"
+	refreshTokenPath = "/api/auth"
+	csrfTokenPath    = "/"
+	csrfTokenLen     = 32
+)
+
+var (
+	ErrCSRFTokenMismatch = errors.New("csrf token is missing or doesn't match its cookie")
+)
+
+// cookieMode reports whether the client asked for tokens in cookies, which only
+// browsers do, and the mode is turned on.
+func (h *Handler) cookieMode(c *gin.Context) bool {
+	return h.cookies.Enabled && c.GetHeader(authModeHeader) == authModeCookie
+}
+
+// writeTokens returns the tokens in the body, or in cookie mode puts them into HttpOnly
+// cookies, out of reach of scripts, and returns a new CSRF token instead. The CSRF token
+// is also set as a readable cookie, to be sent back in the X-CSRF-Token header.
+func (h *Handler) writeTokens(c *gin.Context, code int, tokens models.Tokens) {
+	h.writeTokensIn(c, code, tokens, h.cookieMode(c))
+}
+
+// writeTokensIn is writeTokens with the auth mode decided by the caller.
+func (h *Handler) writeTokensIn(c *gin.Context, code int, tokens models.Tokens, cookieMode bool) {
+	if !cookieMode {
+		c.JSON(code, tokens)
+		return
+	}
+
+	csrfToken, err := auth.GenerateRandomToken(csrfTokenLen)
+	if err != nil {
+		newErrResponse(c, http.StatusInternalServerError, "failed while generating csrf token", err)
+		return
+	}
+
+	maxAge := int(h.cookies.MaxAge.Seconds())
+
+	h.setCookie(c, accessTokenCookie, tokens.AccessToken, accessTokenPath, maxAge, true)
+	h.setCookie(c, refreshTokenCookie, tokens.RefreshToken, refreshTokenPath, maxAge, true)
+	h.setCookie(c, csrfTokenCookie, csrfToken, csrfTokenPath, maxAge, false)
+
+	c.JSON(code, map[string]any{
+		"csrf_token": csrfToken,
+	})
+}
+
+func (h *Handler) clearTokenCookies(c *gin.Context) {
+	if !h.cookies.Enabled {
+		return
+	}
+
+	h.setCookie(c, accessTokenCookie, "", accessTokenPath, -1, true)
+	h.setCookie(c, refreshTokenCookie, "", refreshTokenPath, -1, true)
+	h.setCookie(c, csrfTokenCookie, "", csrfTokenPath, -1, false)
+}
+
+func (h *Handler) setCookie(c *gin.Context, name, value, path string, maxAge int, httpOnly bool) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   h.cookies.Domain,
+		MaxAge:   maxAge,
+		Secure:   h.cookies.Secure,
+		HttpOnly: httpOnly,
+		SameSite: h.cookies.SameSite,
+	})
+}
+
+// checkCSRF is the double submit check for requests authenticated by cookies. Another
+// site can make the browser send the cookies, but can't read the CSRF cookie to copy
+// it into the header.
+func checkCSRF(c *gin.Context) error {
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return nil
+	}
+
+	cookie, err := c.Cookie(csrfTokenCookie)
+	if err != nil || cookie == "" {
+		return ErrCSRFTokenMismatch
+	}
+
+	if subtle.ConstantTimeCompare([]byte(cookie), []byte(c.GetHeader(csrfHeader))) != 1 {
+		return ErrCSRFTokenMismatch
+	}
+
+	return nil
+}
@@ -1,22 +1,36 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/HeadGardener/coursework/internal/dto"
 	"github.com/HeadGardener/coursework/internal/models"
+	"github.com/HeadGardener/coursework/internal/service"
 	"github.com/gin-gonic/gin"
 )
 
 func (h *Handler) viewDrinks(c *gin.Context) {
-	adult, err := getIsAdult(c)
+	customer, err := getCustomer(c)
 	if err != nil {
 		newErrResponse(c, http.StatusForbidden, "failed while identifying age", err)
 		return
 	}
 
-	drinks, err := h.drinkService.GetAll(c, adult)
+	var req dto.ListDrinksReq
+	if err = c.ShouldBindQuery(&req); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while decoding list drinks request", err)
+		return
+	}
+
+	query, err := req.Query()
+	if err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while validating list drinks request", err)
+		return
+	}
+
+	drinks, err := h.drinkService.GetAll(c, customer, query)
 	if err != nil {
 		newErrResponse(c, http.StatusInternalServerError, "failed while getting drinks", err)
 		return
@@ -25,6 +39,62 @@ func (h *Handler) viewDrinks(c *gin.Context) {
 	c.JSON(http.StatusOK, drinks)
 }
 
+func (h *Handler) searchDrinks(c *gin.Context) {
+	customer, err := getCustomer(c)
+	if err != nil {
+		newErrResponse(c, http.StatusForbidden, "failed while identifying age", err)
+		return
+	}
+
+	var req dto.SearchDrinksReq
+	if err = c.ShouldBindQuery(&req); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while decoding search drinks request", err)
+		return
+	}
+
+	if err = req.Validate(); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while validating search drinks request", err)
+		return
+	}
+
+	drinks, err := h.drinkService.Search(c, customer, req.Search())
+	if err != nil {
+		newErrResponse(c, http.StatusInternalServerError, "failed while searching drinks", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, drinks)
+}
+
+func (h *Handler) autocompleteDrinks(c *gin.Context) {
+	customer, err := getCustomer(c)
+	if err != nil {
+		newErrResponse(c, http.StatusForbidden, "failed while identifying age", err)
+		return
+	}
+
+	var req dto.AutocompleteDrinksReq
+	if err = c.ShouldBindQuery(&req); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while decoding autocomplete request", err)
+		return
+	}
+
+	if err = req.Validate(); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while validating autocomplete request", err)
+		return
+	}
+
+	prefix, limit := req.Prefix()
+
+	suggestions, err := h.drinkService.Autocomplete(c, customer, prefix, limit)
+	if err != nil {
+		newErrResponse(c, http.StatusInternalServerError, "failed while autocompleting drinks", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, suggestions)
+}
+
 func (h *Handler) viewByID(c *gin.Context) {
 	drinkID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -32,13 +102,13 @@ func (h *Handler) viewByID(c *gin.Context) {
 		return
 	}
 
-	adult, err := getIsAdult(c)
+	customer, err := getCustomer(c)
 	if err != nil {
 		newErrResponse(c, http.StatusForbidden, "failed while identifying age", err)
 		return
 	}
 
-	drinks, err := h.drinkService.GetByID(c, drinkID, adult)
+	drinks, err := h.drinkService.GetByID(c, drinkID, customer)
 	if err != nil {
 		newErrResponse(c, http.StatusInternalServerError, "failed while getting drinks", err)
 		return
@@ -60,16 +130,18 @@ func (h *Handler) addDrink(c *gin.Context) {
 	}
 
 	drink := &models.Drink{
-		Name:   req.Name,
-		Type:   req.Type,
-		Bottle: req.Bottle,
-		Cost:   req.Cost,
-		Soft:   req.Soft,
+		Name:       req.Name,
+		CategoryID: req.CategoryID,
+		Bottle:     req.Bottle,
+		Cost:       req.Cost,
+		ABV:        *req.ABV,
+
+		AgeCategory: models.AgeCategory(req.AgeCategory),
 	}
 
 	id, err := h.drinkService.Add(c, drink)
 	if err != nil {
-		newErrResponse(c, http.StatusInternalServerError, "failed while adding drink", err)
+		newErrResponse(c, drinkErrStatus(err), "failed while adding drink", err)
 		return
 	}
 
@@ -97,14 +169,46 @@ func (h *Handler) updateDrink(c *gin.Context) {
 	}
 
 	drink := &models.Drink{
-		Name:   req.Name,
-		Type:   req.Type,
-		Bottle: req.Bottle,
-		Cost:   req.Cost,
+		Name:       req.Name,
+		CategoryID: req.CategoryID,
+		Bottle:     req.Bottle,
+		Cost:       req.Cost,
+		ABV:        *req.ABV,
+
+		AgeCategory: models.AgeCategory(req.AgeCategory),
+	}
+
+	err = h.drinkService.Update(c, drinkID, drink)
+	if err != nil {
+		newErrResponse(c, drinkErrStatus(err), "failed while updating drink", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]any{
+		"status": "updated",
+	})
+}
+
+func (h *Handler) patchDrink(c *gin.Context) {
+	drinkID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while checking id", err)
+		return
+	}
+
+	var req dto.DrinkPatchRequest
+	if err = c.BindJSON(&req); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while decoding patch drink request", err)
+		return
 	}
 
-	if err := h.drinkService.Update(c, drinkID, drink); err != nil {
-		newErrResponse(c, http.StatusInternalServerError, "failed while updating drink", err)
+	if err = req.Validate(); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while validating patch drink request", err)
+		return
+	}
+
+	if err = h.drinkService.Patch(c, drinkID, req.Patch()); err != nil {
+		newErrResponse(c, drinkErrStatus(err), "failed while patching drink", err)
 		return
 	}
 
@@ -129,3 +233,16 @@ func (h *Handler) deleteDrink(c *gin.Context) {
 		"status": "deleted",
 	})
 }
+
+func drinkErrStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrDrinkNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrAlcoholicSoftDrink),
+		errors.Is(err, service.ErrCategoryNotFound),
+		errors.Is(err, service.ErrInvalidCost):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	mock_service "github.com/HeadGardener/coursework/internal/handlers/mocks"
 	"github.com/HeadGardener/coursework/internal/models"
+	"github.com/HeadGardener/coursework/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/assert/v2"
 	"go.uber.org/mock/gomock"
@@ -29,18 +30,18 @@ func TestAddDrinkHandler(t *testing.T) {
 			name: "ok",
 			inputBody: `{
           					"name": "test",
-               				"type": "test",
+               				"category_id": 1,
                    			"bottle": 100,
-                      		"cost": 100,
-                        	"soft": true
+                      		"cost": {"amount": "4.50", "currency": "EUR"},
+                        	"abv": 0
                       	}`,
 			drink: &models.Drink{
-				ID:     0,
-				Name:   "test",
-				Type:   "test",
-				Bottle: 100,
-				Cost:   100,
-				Soft:   true,
+				ID:         0,
+				Name:       "test",
+				CategoryID: 1,
+				Bottle:     100,
+				Cost:       models.Money{Amount: 450, Currency: "EUR"},
+				ABV:        0,
 			},
 			mockBehavior: func(s *mock_service.MockDrinkService, drink *models.Drink) {
 				s.EXPECT().Add(gomock.Any(), drink).Return(0, nil)
@@ -48,14 +49,26 @@ func TestAddDrinkHandler(t *testing.T) {
 			expectedStatusCode:   http.StatusCreated,
 			expectedResponseBody: `{"id":0}`,
 		},
+		{
+			name: "missing category",
+			inputBody: `{
+          					"name": "test",
+                   			"bottle": 100,
+                      		"cost": {"amount": "4.50", "currency": "EUR"},
+                        	"abv": 0
+                      	}`,
+			mockBehavior:         func(s *mock_service.MockDrinkService, drink *models.Drink) {},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"Msg":"failed while validating drink request","Error":"invalid category_id: must be greater than 0"}`,
+		},
 		{
 			name: "invalid bottle",
 			inputBody: `{
           					"name": "test",
-               				"type": "test",
+               				"category_id": 1,
                    			"bottle": 0,
-                      		"cost": 100,
-                        	"soft": true
+                      		"cost": {"amount": "4.50", "currency": "EUR"},
+                        	"abv": 0
                       	}`,
 			mockBehavior:         func(s *mock_service.MockDrinkService, drink *models.Drink) {},
 			expectedStatusCode:   http.StatusBadRequest,
@@ -65,30 +78,81 @@ func TestAddDrinkHandler(t *testing.T) {
 			name: "invalid cost",
 			inputBody: `{
           					"name": "test",
-               				"type": "test",
+               				"category_id": 1,
                    			"bottle": 100,
-                      		"cost": -1,
-                        	"soft": true
+                      		"cost": {"amount": "-0.01", "currency": "EUR"},
+                        	"abv": 0
                       	}`,
 			mockBehavior:         func(s *mock_service.MockDrinkService, drink *models.Drink) {},
 			expectedStatusCode:   http.StatusBadRequest,
 			expectedResponseBody: `{"Msg":"failed while validating drink request","Error":"invalid cost: cost can't be less than 0"}`,
 		},
+		{
+			name: "missing abv",
+			inputBody: `{
+          					"name": "test",
+               				"category_id": 1,
+                   			"bottle": 100,
+                      		"cost": {"amount": "4.50", "currency": "EUR"}
+                      	}`,
+			mockBehavior:         func(s *mock_service.MockDrinkService, drink *models.Drink) {},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"Msg":"failed while validating drink request","Error":"invalid abv: required"}`,
+		},
+		{
+			name: "cost without currency",
+			inputBody: `{
+          					"name": "test",
+               				"category_id": 1,
+                   			"bottle": 100,
+                      		"cost": {"amount": "4.50"},
+                        	"abv": 0
+                      	}`,
+			mockBehavior:         func(s *mock_service.MockDrinkService, drink *models.Drink) {},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"Msg":"failed while decoding drink request","Error":"unknown currency \"\""}`,
+		},
+		{
+			name: "cost finer than the currency",
+			inputBody: `{
+          					"name": "test",
+               				"category_id": 1,
+                   			"bottle": 100,
+                      		"cost": {"amount": 4.505, "currency": "EUR"},
+                        	"abv": 0
+                      	}`,
+			mockBehavior:         func(s *mock_service.MockDrinkService, drink *models.Drink) {},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"Msg":"failed while decoding drink request","Error":"invalid amount \"4.505\": must be a decimal with at most 2 fraction digits for EUR"}`,
+		},
+		{
+			name: "invalid abv",
+			inputBody: `{
+          					"name": "test",
+               				"category_id": 1,
+                   			"bottle": 100,
+                      		"cost": {"amount": "4.50", "currency": "EUR"},
+                        	"abv": 120
+                      	}`,
+			mockBehavior:         func(s *mock_service.MockDrinkService, drink *models.Drink) {},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"Msg":"failed while validating drink request","Error":"invalid abv: must be between 0 and 100"}`,
+		},
 		{
 			name: "service failure",
 			inputBody: `{
           					"name": "test",
-               				"type": "test",
+               				"category_id": 1,
                    			"bottle": 100,
-                      		"cost": 100,
-                        	"soft": true
+                      		"cost": {"amount": "4.50", "currency": "EUR"},
+                        	"abv": 0
                       	}`,
 			drink: &models.Drink{
-				Name:   "test",
-				Type:   "test",
-				Bottle: 100,
-				Cost:   100,
-				Soft:   true,
+				Name:       "test",
+				CategoryID: 1,
+				Bottle:     100,
+				Cost:       models.Money{Amount: 450, Currency: "EUR"},
+				ABV:        0,
 			},
 			mockBehavior: func(s *mock_service.MockDrinkService, drink *models.Drink) {
 				s.EXPECT().Add(gomock.Any(), drink).Return(0, errors.New(""))
@@ -106,7 +170,7 @@ func TestAddDrinkHandler(t *testing.T) {
 			drink := mock_service.NewMockDrinkService(c)
 			tc.mockBehavior(drink, tc.drink)
 
-			handler := NewHandler(nil, drink)
+			handler := NewHandler(nil, drink, nil, nil, nil, nil, nil)
 
 			gin.SetMode(gin.ReleaseMode)
 			router := gin.New()
@@ -123,3 +187,359 @@ func TestAddDrinkHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestPatchDrinkHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockDrinkService)
+
+	name, zero, eur := "pils", "0", models.Currency("EUR")
+
+	testTable := []struct {
+		name                 string
+		inputBody            string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:      "ok",
+			inputBody: `{"name": "pils", "cost": {"amount": 0}, "age_category": null}`,
+			mockBehavior: func(s *mock_service.MockDrinkService) {
+				s.EXPECT().Patch(gomock.Any(), 1, models.DrinkPatch{
+					Name:             &name,
+					CostAmount:       &zero,
+					ResetAgeCategory: true,
+				}).Return(nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"status":"updated"}`,
+		},
+		{
+			name:      "currency only",
+			inputBody: `{"cost": {"currency": "EUR"}}`,
+			mockBehavior: func(s *mock_service.MockDrinkService) {
+				s.EXPECT().Patch(gomock.Any(), 1, models.DrinkPatch{Currency: &eur}).Return(nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"status":"updated"}`,
+		},
+		{
+			name:                 "null name",
+			inputBody:            `{"name": null}`,
+			mockBehavior:         func(s *mock_service.MockDrinkService) {},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"Msg":"failed while validating patch drink request","Error":"invalid name: can't be null"}`,
+		},
+		{
+			name:      "not found",
+			inputBody: `{}`,
+			mockBehavior: func(s *mock_service.MockDrinkService) {
+				s.EXPECT().Patch(gomock.Any(), 1, models.DrinkPatch{}).Return(service.ErrDrinkNotFound)
+			},
+			expectedStatusCode:   http.StatusNotFound,
+			expectedResponseBody: `{"Msg":"failed while patching drink","Error":"drink not found"}`,
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			drink := mock_service.NewMockDrinkService(c)
+			tc.mockBehavior(drink)
+
+			handler := NewHandler(nil, drink, nil, nil, nil, nil, nil)
+
+			gin.SetMode(gin.ReleaseMode)
+			router := gin.New()
+			router.Use(gin.Recovery())
+			router.PATCH("/api/drinks/:id", handler.patchDrink)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("PATCH", "/api/drinks/1", bytes.NewBufferString(tc.inputBody))
+
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+			assert.Equal(t, tc.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestViewDrinksHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockDrinkService, query models.DrinkQuery)
+
+	minCost, soft := 100, true
+
+	testTable := []struct {
+		name                 string
+		target               string
+		query                models.DrinkQuery
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:   "defaults",
+			target: "/api/drinks",
+			query:  models.DrinkQuery{Sort: models.DrinkSortID, Limit: 20},
+			mockBehavior: func(s *mock_service.MockDrinkService, query models.DrinkQuery) {
+				s.EXPECT().GetAll(gomock.Any(), gomock.Any(), query).Return(models.DrinkPage{
+					Drinks:     []models.Drink{},
+					NextCursor: "next",
+				}, nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"drinks":[],"next_cursor":"next"}`,
+		},
+		{
+			name:   "filters",
+			target: "/api/drinks?category=2&category=4&currency=EUR&min_cost=100&soft=true&sort=-cost&limit=5",
+			query: models.DrinkQuery{
+				Categories: []int{2, 4},
+				Currency:   models.Currency("EUR"),
+				MinCost:    &minCost,
+				Soft:       &soft,
+				Sort:       models.DrinkSortCost,
+				Desc:       true,
+				Limit:      5,
+			},
+			mockBehavior: func(s *mock_service.MockDrinkService, query models.DrinkQuery) {
+				s.EXPECT().GetAll(gomock.Any(), gomock.Any(), query).Return(models.DrinkPage{
+					Drinks: []models.Drink{},
+				}, nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"drinks":[]}`,
+		},
+		{
+			name:                 "invalid sort",
+			target:               "/api/drinks?sort=abv",
+			mockBehavior:         func(s *mock_service.MockDrinkService, query models.DrinkQuery) {},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"Msg":"failed while validating list drinks request","Error":"invalid sort: must be one of id, name, cost, optionally prefixed with -"}`,
+		},
+		{
+			name:                 "cursor of another sort",
+			target:               "/api/drinks?sort=name&cursor=" + models.DrinkCursor{Sort: models.DrinkSortCost, ID: 1}.Encode(),
+			mockBehavior:         func(s *mock_service.MockDrinkService, query models.DrinkQuery) {},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"Msg":"failed while validating list drinks request","Error":"invalid cursor: issued for another sort order"}`,
+		},
+		{
+			name:                 "invalid cost range",
+			target:               "/api/drinks?min_cost=200&max_cost=100",
+			mockBehavior:         func(s *mock_service.MockDrinkService, query models.DrinkQuery) {},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"Msg":"failed while validating list drinks request","Error":"invalid cost range: min_cost can't be greater than max_cost"}`,
+		},
+		{
+			name:                 "cost filter without currency",
+			target:               "/api/drinks?min_cost=100",
+			mockBehavior:         func(s *mock_service.MockDrinkService, query models.DrinkQuery) {},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"Msg":"failed while validating list drinks request","Error":"invalid currency: required to filter or sort by cost"}`,
+		},
+		{
+			name:                 "cost sort without currency",
+			target:               "/api/drinks?sort=-cost",
+			mockBehavior:         func(s *mock_service.MockDrinkService, query models.DrinkQuery) {},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"Msg":"failed while validating list drinks request","Error":"invalid currency: required to filter or sort by cost"}`,
+		},
+		{
+			name:                 "unknown currency",
+			target:               "/api/drinks?currency=XYZ",
+			mockBehavior:         func(s *mock_service.MockDrinkService, query models.DrinkQuery) {},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"Msg":"failed while validating list drinks request","Error":"invalid currency: must be an ISO 4217 code"}`,
+		},
+		{
+			name:                 "malformed limit",
+			target:               "/api/drinks?limit=many",
+			mockBehavior:         func(s *mock_service.MockDrinkService, query models.DrinkQuery) {},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"Msg":"failed while decoding list drinks request","Error":"strconv.ParseInt: parsing \"many\": invalid syntax"}`,
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			drink := mock_service.NewMockDrinkService(c)
+			tc.mockBehavior(drink, tc.query)
+
+			handler := NewHandler(nil, drink, nil, nil, nil, nil, nil)
+
+			gin.SetMode(gin.ReleaseMode)
+			router := gin.New()
+			router.Use(gin.Recovery())
+			router.GET("/api/drinks", func(c *gin.Context) {
+				c.Set(customerCtx, models.Customer{})
+			}, handler.viewDrinks)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", tc.target, nil)
+
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+			assert.Equal(t, tc.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestSearchDrinksHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockDrinkService, search models.DrinkSearch)
+
+	testTable := []struct {
+		name                 string
+		target               string
+		search               models.DrinkSearch
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:   "ok",
+			target: "/api/drinks/search?q=+jager+",
+			search: models.DrinkSearch{Query: "jager", Page: 1, PerPage: 20},
+			mockBehavior: func(s *mock_service.MockDrinkService, search models.DrinkSearch) {
+				s.EXPECT().Search(gomock.Any(), gomock.Any(), search).Return(models.DrinkSearchPage{
+					Drinks:  []models.Drink{},
+					Page:    search.Page,
+					PerPage: search.PerPage,
+				}, nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"drinks":[],"total":0,"page":1,"per_page":20}`,
+		},
+		{
+			name:                 "empty query",
+			target:               "/api/drinks/search?q=+",
+			mockBehavior:         func(s *mock_service.MockDrinkService, search models.DrinkSearch) {},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"Msg":"failed while validating search drinks request","Error":"invalid q: can't be empty"}`,
+		},
+		{
+			name:                 "invalid per page",
+			target:               "/api/drinks/search?q=makers&per_page=500",
+			mockBehavior:         func(s *mock_service.MockDrinkService, search models.DrinkSearch) {},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"Msg":"failed while validating search drinks request","Error":"invalid per_page: must be between 1 and 100"}`,
+		},
+		{
+			name:                 "page too deep",
+			target:               "/api/drinks/search?q=makers&page=9223372036854775807",
+			mockBehavior:         func(s *mock_service.MockDrinkService, search models.DrinkSearch) {},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"Msg":"failed while validating search drinks request","Error":"invalid page: must be between 1 and 100"}`,
+		},
+		{
+			name:   "service failure",
+			target: "/api/drinks/search?q=makers&page=2",
+			search: models.DrinkSearch{Query: "makers", Page: 2, PerPage: 20},
+			mockBehavior: func(s *mock_service.MockDrinkService, search models.DrinkSearch) {
+				s.EXPECT().Search(gomock.Any(), gomock.Any(), search).Return(models.DrinkSearchPage{}, errors.New(""))
+			},
+			expectedStatusCode:   http.StatusInternalServerError,
+			expectedResponseBody: `{"Msg":"failed while searching drinks","Error":""}`,
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			drink := mock_service.NewMockDrinkService(c)
+			tc.mockBehavior(drink, tc.search)
+
+			handler := NewHandler(nil, drink, nil, nil, nil, nil, nil)
+
+			gin.SetMode(gin.ReleaseMode)
+			router := gin.New()
+			router.Use(gin.Recovery())
+			router.GET("/api/drinks/search", func(c *gin.Context) {
+				c.Set(customerCtx, models.Customer{})
+			}, handler.searchDrinks)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", tc.target, nil)
+
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+			assert.Equal(t, tc.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestAutocompleteDrinksHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockDrinkService)
+
+	testTable := []struct {
+		name                 string
+		target               string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:   "ok",
+			target: "/api/drinks/search/autocomplete?q=jag",
+			mockBehavior: func(s *mock_service.MockDrinkService) {
+				s.EXPECT().Autocomplete(gomock.Any(), gomock.Any(), "jag", 10).Return([]models.DrinkSuggestion{
+					{ID: 1, Name: "Jägermeister"},
+				}, nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `[{"id":1,"name":"Jägermeister"}]`,
+		},
+		{
+			name:   "custom limit",
+			target: "/api/drinks/search/autocomplete?q=mak&limit=3",
+			mockBehavior: func(s *mock_service.MockDrinkService) {
+				s.EXPECT().Autocomplete(gomock.Any(), gomock.Any(), "mak", 3).Return([]models.DrinkSuggestion{}, nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `[]`,
+		},
+		{
+			name:                 "invalid limit",
+			target:               "/api/drinks/search/autocomplete?q=mak&limit=50",
+			mockBehavior:         func(s *mock_service.MockDrinkService) {},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"Msg":"failed while validating autocomplete request","Error":"invalid limit: must be between 1 and 20"}`,
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			drink := mock_service.NewMockDrinkService(c)
+			tc.mockBehavior(drink)
+
+			handler := NewHandler(nil, drink, nil, nil, nil, nil, nil)
+
+			gin.SetMode(gin.ReleaseMode)
+			router := gin.New()
+			router.Use(gin.Recovery())
+			router.GET("/api/drinks/search/autocomplete", func(c *gin.Context) {
+				c.Set(customerCtx, models.Customer{})
+			}, handler.autocompleteDrinks)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", tc.target, nil)
+
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+			assert.Equal(t, tc.expectedResponseBody, w.Body.String())
+		})
+	}
+}
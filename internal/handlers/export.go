@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/HeadGardener/coursework/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+const exportContentType = "application/zip"
+
+func (h *Handler) requestOwnExport(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrResponse(c, http.StatusForbidden, "failed while getting user id", err)
+		return
+	}
+
+	h.requestExport(c, userID)
+}
+
+func (h *Handler) requestUserExport(c *gin.Context) {
+	h.requestExport(c, c.Param("id"))
+}
+
+func (h *Handler) requestExport(c *gin.Context, userID string) {
+	export, token, err := h.exportService.RequestExport(c, userID)
+	if err != nil {
+		newErrResponse(c, exportErrStatus(err), "failed while requesting export", err)
+		return
+	}
+
+	export.DownloadURL = fmt.Sprintf("/api/exports/%s/download?token=%s", export.ID, url.QueryEscape(token))
+
+	c.JSON(http.StatusAccepted, export)
+}
+
+func (h *Handler) viewOwnExport(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrResponse(c, http.StatusForbidden, "failed while getting user id", err)
+		return
+	}
+
+	h.viewExport(c, userID, c.Param("id"))
+}
+
+func (h *Handler) viewUserExport(c *gin.Context) {
+	h.viewExport(c, c.Param("id"), c.Param("job"))
+}
+
+func (h *Handler) viewExport(c *gin.Context, userID, jobID string) {
+	export, err := h.exportService.GetExport(c, userID, jobID)
+	if err != nil {
+		newErrResponse(c, exportErrStatus(err), "failed while getting export", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, export)
+}
+
+func (h *Handler) downloadExport(c *gin.Context) {
+	archive, err := h.exportService.Download(c, c.Param("id"), c.Query("token"))
+	if err != nil {
+		newErrResponse(c, exportErrStatus(err), "failed while downloading export", err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="export-%s.zip"`, c.Param("id")))
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, exportContentType, archive)
+}
+
+func exportErrStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrUserNotFound),
+		errors.Is(err, service.ErrExportNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrExportNotReady):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
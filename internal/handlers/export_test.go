@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mock_service "github.com/HeadGardener/coursework/internal/handlers/mocks"
+	"github.com/HeadGardener/coursework/internal/lib/auth"
+	"github.com/HeadGardener/coursework/internal/models"
+	"github.com/HeadGardener/coursework/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRequestExportHandler(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	createdAt := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+
+	exportService := mock_service.NewMockExportService(c)
+	exportService.EXPECT().RequestExport(gomock.Any(), "1").Return(models.ExportInfo{
+		ID:        "job",
+		Status:    models.ExportPending,
+		CreatedAt: createdAt,
+		ExpiresAt: createdAt.Add(24 * time.Hour),
+	}, "token", nil)
+
+	handler := NewHandler(nil, nil, exportService, nil, nil, nil, nil)
+
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.Use(func(c *gin.Context) {
+		c.Set(userCtx, auth.UserAttributes{ID: "1"})
+	})
+	router.POST("/api/users/me/export", handler.requestOwnExport)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/api/users/me/export", nil)
+
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, `{"id":"job","status":"pending","created_at":"2026-10-18T12:00:00Z",`+
+		`"expires_at":"2026-10-19T12:00:00Z","download_url":"/api/exports/job/download?token=token"}`, w.Body.String())
+}
+
+func TestDownloadExportHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockExportService)
+
+	testTable := []struct {
+		name                 string
+		query                string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:  "ok",
+			query: "?token=token",
+			mockBehavior: func(s *mock_service.MockExportService) {
+				s.EXPECT().Download(gomock.Any(), "job", "token").Return([]byte("zip"), nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: "zip",
+		},
+		{
+			name:  "not ready",
+			query: "?token=token",
+			mockBehavior: func(s *mock_service.MockExportService) {
+				s.EXPECT().Download(gomock.Any(), "job", "token").Return(nil, service.ErrExportNotReady)
+			},
+			expectedStatusCode:   http.StatusConflict,
+			expectedResponseBody: `{"Msg":"failed while downloading export","Error":"export is not ready yet"}`,
+		},
+		{
+			name:  "expired link",
+			query: "?token=old",
+			mockBehavior: func(s *mock_service.MockExportService) {
+				s.EXPECT().Download(gomock.Any(), "job", "old").Return(nil, service.ErrExportNotFound)
+			},
+			expectedStatusCode:   http.StatusNotFound,
+			expectedResponseBody: `{"Msg":"failed while downloading export","Error":"export not found or expired"}`,
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			exportService := mock_service.NewMockExportService(c)
+			tc.mockBehavior(exportService)
+
+			handler := NewHandler(nil, nil, exportService, nil, nil, nil, nil)
+
+			gin.SetMode(gin.ReleaseMode)
+			router := gin.New()
+			router.Use(gin.Recovery())
+			router.GET("/api/exports/:id/download", handler.downloadExport)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "/api/exports/job/download"+tc.query, nil)
+
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+			assert.Equal(t, tc.expectedResponseBody, w.Body.String())
+		})
+	}
+}
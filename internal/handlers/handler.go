@@ -3,7 +3,9 @@ package handlers
 import (
 	"context"
 	"net/http"
+	"time"
 
+	"github.com/HeadGardener/coursework/internal/config"
 	"github.com/HeadGardener/coursework/internal/lib/auth"
 	"github.com/HeadGardener/coursework/internal/models"
 
@@ -11,53 +13,217 @@ import (
 )
 
 type AuthService interface {
-	SignUp(ctx context.Context, username, name string, age int, password string) (string, error)
-	SignIn(ctx context.Context, username, password string) (models.Tokens, error)
-	ParseAccessToken(token string) (auth.UserAttributes, error)
-	Refresh(ctx context.Context, accessToken, refreshToken string) (models.Tokens, error)
-	LogOut(ctx context.Context, userID string) error
+	SignUp(ctx context.Context, username, name string, birthDate time.Time, region, password string) (string, error)
+	SignIn(ctx context.Context, username, password string, client models.ClientInfo) (models.SignInResult, error)
+	CompleteSignIn(ctx context.Context, mfaToken, code string, client models.ClientInfo) (models.Tokens, error)
+	ParseAccessToken(ctx context.Context, token string) (auth.UserAttributes, error)
+	GetJWKS() auth.JWKS
+	Refresh(ctx context.Context, accessToken, refreshToken string, client models.ClientInfo) (models.Tokens, error)
+	LogOut(ctx context.Context, userAttr auth.UserAttributes) error
+	GetSessions(ctx context.Context, userID, currentSessionID string) ([]models.SessionInfo, error)
+	RevokeSession(ctx context.Context, userID, sessionID string) error
+	RevokeOtherSessions(ctx context.Context, userID, currentSessionID string) error
+	ChangePassword(ctx context.Context, userAttr auth.UserAttributes, oldPassword, newPassword string) error
+	RequestPasswordReset(ctx context.Context, username string) error
+	ResetPassword(ctx context.Context, token, newPassword string) error
+	EnrollTOTP(ctx context.Context, userID string) (models.TOTPEnrollment, error)
+	ConfirmTOTP(ctx context.Context, userID, code string) ([]string, error)
+	DisableTOTP(ctx context.Context, userID, code string) error
+	UnlockUser(ctx context.Context, userID string) error
+	ListUsers(ctx context.Context, filter models.UserFilter) (models.UserPage, error)
+	GetUser(ctx context.Context, userID string) (models.UserInfo, error)
+	SetUserRoles(ctx context.Context, userID string, roles []string) error
+	SetUserDisabled(ctx context.Context, actorID, userID string, disabled bool) error
+	ForceLogout(ctx context.Context, userID string) error
+	UpdateProfile(ctx context.Context, userID, username, name string) (models.UserInfo, error)
+	DeleteAccount(ctx context.Context, userAttr auth.UserAttributes, password string, client models.ClientInfo) error
 }
 
 type DrinkService interface {
-	GetAll(ctx context.Context, adult bool) ([]models.Drink, error)
-	GetByID(ctx context.Context, id int, adult bool) (models.Drink, error)
+	GetAll(ctx context.Context, customer models.Customer, query models.DrinkQuery) (models.DrinkPage, error)
+	Search(ctx context.Context, customer models.Customer, search models.DrinkSearch) (models.DrinkSearchPage, error)
+	Autocomplete(ctx context.Context, customer models.Customer, prefix string, limit int) ([]models.DrinkSuggestion, error)
+	GetByID(ctx context.Context, id int, customer models.Customer) (models.Drink, error)
 	Add(ctx context.Context, drink *models.Drink) (int, error)
 	Update(ctx context.Context, id int, drink *models.Drink) error
+	Patch(ctx context.Context, id int, patch models.DrinkPatch) error
 	Delete(ctx context.Context, id int) error
 }
 
+type CategoryService interface {
+	Tree(ctx context.Context, customer models.Customer) ([]*models.CategoryNode, error)
+	Create(ctx context.Context, category *models.Category) (int, error)
+	Update(ctx context.Context, id int, category *models.Category) error
+	Delete(ctx context.Context, id int) error
+}
+
+type ExportService interface {
+	RequestExport(ctx context.Context, userID string) (models.ExportInfo, string, error)
+	GetExport(ctx context.Context, userID, jobID string) (models.ExportInfo, error)
+	Download(ctx context.Context, jobID, token string) ([]byte, error)
+}
+
+type APIKeyService interface {
+	Create(ctx context.Context, userID, name string, scopes []models.Permission, expiresAt time.Time) (models.CreatedAPIKey, error)
+	List(ctx context.Context, userID string) ([]models.APIKeyInfo, error)
+	Revoke(ctx context.Context, keyID string) error
+	Authenticate(ctx context.Context, key string) (auth.UserAttributes, error)
+}
+
+type OAuthService interface {
+	RegisterClient(ctx context.Context, name string, redirectURIs, grantTypes []string, scopes []models.Permission,
+		public bool) (models.RegisteredOAuthClient, error)
+	ListClients(ctx context.Context) ([]models.OAuthClientInfo, error)
+	DeleteClient(ctx context.Context, clientID string) error
+	PrepareAuthorization(ctx context.Context, req models.AuthorizationRequest) (models.Consent, error)
+	Authorize(ctx context.Context, userID string, req models.AuthorizationRequest, approved bool) (string, error)
+	Exchange(ctx context.Context, req models.TokenRequest) (models.OAuthToken, error)
+	Introspect(ctx context.Context, clientID, clientSecret, token string) (models.Introspection, error)
+	Revoke(ctx context.Context, clientID, clientSecret, token string) error
+}
+
+type OIDCService interface {
+	Providers() []string
+	StartLogin(ctx context.Context, provider, userID string, cookieMode bool) (authURL, binding string, err error)
+	CompleteLogin(ctx context.Context, provider, code, state, binding string,
+		client models.ClientInfo) (models.SignInResult, error)
+}
+
 type Handler struct {
-	authService  AuthService
-	drinkService DrinkService
+	authService     AuthService
+	drinkService    DrinkService
+	exportService   ExportService
+	apiKeyService   APIKeyService
+	oauthService    OAuthService
+	oidcService     OIDCService
+	categoryService CategoryService
+	cookies         config.CookieConfig
 }
 
-func NewHandler(authService AuthService, drinkService DrinkService) *Handler {
+func NewHandler(authService AuthService, drinkService DrinkService, exportService ExportService,
+	apiKeyService APIKeyService, oauthService OAuthService, oidcService OIDCService,
+	categoryService CategoryService) *Handler {
 	return &Handler{
-		authService:  authService,
-		drinkService: drinkService,
+		authService:     authService,
+		drinkService:    drinkService,
+		exportService:   exportService,
+		apiKeyService:   apiKeyService,
+		oauthService:    oauthService,
+		oidcService:     oidcService,
+		categoryService: categoryService,
 	}
 }
 
-func (h *Handler) InitRoutes() http.Handler {
+func (h *Handler) InitRoutes(conf config.HandlerConfig) http.Handler {
+	h.cookies = conf.Cookies
+
 	router := gin.New()
 
+	var privileged []gin.HandlerFunc
+	if conf.RequireAdminMFA {
+		privileged = append(privileged, h.requireMFA)
+	}
+
+	router.GET("/.well-known/jwks.json", h.jwks)
+
 	api := router.Group("/api")
 	{
 		auth := api.Group("/auth")
 		{
 			auth.POST("/sign-up", h.signUp)
 			auth.POST("/sign-in", h.signIn)
+			auth.POST("/sign-in/2fa", h.completeSignIn)
 			auth.POST("/refresh", h.refresh)
-			auth.PUT("/logout", h.identifyUser, h.logout)
+			auth.PUT("/logout", h.identifyUser, h.requireFirstParty, h.logout)
+			auth.PUT("/password", h.identifyUser, h.requireFirstParty, h.changePassword)
+			auth.POST("/password/reset", h.requestPasswordReset)
+			auth.POST("/password/reset/confirm", h.resetPassword)
+
+			auth.GET("/authorize", h.identifyUser, h.requireFirstParty, h.viewConsent)
+			auth.POST("/authorize", h.identifyUser, h.requireFirstParty, h.authorize)
+			auth.POST("/token", h.issueOAuthToken)
+			auth.POST("/introspect", h.introspectToken)
+			auth.POST("/revoke", h.revokeOAuthToken)
+
+			sso := auth.Group("/oidc")
+			{
+				sso.GET("", h.listOIDCProviders)
+				sso.GET("/:provider/login", h.startOIDCLogin)
+				sso.POST("/:provider/link", h.identifyUser, h.requireFirstParty, h.requireRecentAuth, h.startOIDCLink)
+				sso.GET("/:provider/callback", h.completeOIDCLogin)
+			}
+
+			twoFactor := auth.Group("/2fa", h.identifyUser, h.requireFirstParty)
+			{
+				twoFactor.POST("/enroll", h.enrollTOTP)
+				twoFactor.POST("/confirm", h.confirmTOTP)
+				twoFactor.POST("/disable", h.disableTOTP)
+			}
+
+			sessions := auth.Group("/sessions", h.identifyUser, h.requireFirstParty)
+			{
+				sessions.GET("", h.viewSessions)
+				sessions.DELETE("", h.revokeOtherSessions)
+				sessions.DELETE("/:id", h.revokeSession)
+			}
+		}
+
+		api.GET("/exports/:id/download", h.downloadExport)
+
+		users := api.Group("/users", h.identifyUser, h.requireFirstParty)
+		{
+			users.GET("/me", h.viewProfile)
+			users.PATCH("/me", h.updateProfile)
+			users.DELETE("/me", h.deleteAccount)
+			users.POST("/me/export", h.requestOwnExport)
+			users.GET("/me/export/:id", h.viewOwnExport)
+		}
+
+		admin := api.Group("/admin", append([]gin.HandlerFunc{h.identifyUser}, privileged...)...)
+		{
+			admin.GET("/users", h.requirePermission(models.PermUsersRead), h.listUsers)
+			admin.GET("/users/:id", h.requirePermission(models.PermUsersRead), h.viewUser)
+			admin.PUT("/users/:id/roles", h.requirePermission(models.PermUsersManage), h.setUserRoles)
+			admin.POST("/users/:id/disable", h.requirePermission(models.PermUsersManage), h.disableUser)
+			admin.POST("/users/:id/enable", h.requirePermission(models.PermUsersManage), h.enableUser)
+			admin.POST("/users/:id/logout", h.requirePermission(models.PermUsersManage), h.forceLogout)
+			admin.POST("/users/:id/unlock", h.requirePermission(models.PermUsersUnlock), h.unlockUser)
+			admin.POST("/users/:id/export", h.requirePermission(models.PermUsersManage), h.requestUserExport)
+			admin.GET("/users/:id/export/:job", h.requirePermission(models.PermUsersManage), h.viewUserExport)
+			admin.GET("/api-keys", h.requirePermission(models.PermAPIKeysManage), h.listAPIKeys)
+			admin.POST("/api-keys", h.requirePermission(models.PermAPIKeysManage), h.createAPIKey)
+			admin.DELETE("/api-keys/:id", h.requirePermission(models.PermAPIKeysManage), h.revokeAPIKey)
+			admin.GET("/oauth-clients", h.requirePermission(models.PermOAuthClientsManage), h.listOAuthClients)
+			admin.POST("/oauth-clients", h.requirePermission(models.PermOAuthClientsManage), h.registerOAuthClient)
+			admin.DELETE("/oauth-clients/:id", h.requirePermission(models.PermOAuthClientsManage), h.deleteOAuthClient)
 		}
 
 		drinks := api.Group("/drinks", h.identifyUser, h.checkAge)
 		{
 			drinks.GET("/", h.viewDrinks)
+			drinks.GET("/search", h.searchDrinks)
+			drinks.GET("/search/autocomplete", h.autocompleteDrinks)
 			drinks.GET("/:id", h.viewByID)
-			drinks.POST("/", h.identifyRole, h.addDrink)
-			drinks.PUT("/:id", h.identifyRole, h.updateDrink)
-			drinks.DELETE("/:id", h.identifyRole, h.deleteDrink)
+
+			drinksAdmin := drinks.Group("", privileged...)
+			{
+				drinksAdmin.POST("/", h.requirePermission(models.PermDrinksCreate), h.addDrink)
+				drinksAdmin.PUT("/:id", h.requirePermission(models.PermDrinksUpdate), h.updateDrink)
+				drinksAdmin.PATCH("/:id", h.requirePermission(models.PermDrinksUpdate), h.patchDrink)
+				drinksAdmin.DELETE("/:id", h.requirePermission(models.PermDrinksDelete), h.deleteDrink)
+			}
+		}
+
+		categories := api.Group("/categories", h.identifyUser, h.checkAge)
+		{
+			categories.GET("", h.viewCategories)
+
+			categoriesAdmin := categories.Group("", privileged...)
+			{
+				categoriesAdmin.POST("", h.requirePermission(models.PermCategoriesManage), h.addCategory)
+				categoriesAdmin.PUT("/:id", h.requirePermission(models.PermCategoriesManage), h.updateCategory)
+				categoriesAdmin.DELETE("/:id", h.requirePermission(models.PermCategoriesManage), h.deleteCategory)
+			}
 		}
 	}
 
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/HeadGardener/coursework/internal/dto"
+	"github.com/HeadGardener/coursework/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) enrollTOTP(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrResponse(c, http.StatusForbidden, "failed while getting user id", err)
+		return
+	}
+
+	enrollment, err := h.authService.EnrollTOTP(c, userID)
+	if err != nil {
+		if errors.Is(err, service.ErrMFAAlreadyEnabled) {
+			newErrResponse(c, http.StatusConflict, "failed while enrolling totp", err)
+			return
+		}
+
+		newErrResponse(c, http.StatusInternalServerError, "failed while enrolling totp", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, enrollment)
+}
+
+func (h *Handler) confirmTOTP(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrResponse(c, http.StatusForbidden, "failed while getting user id", err)
+		return
+	}
+
+	var req dto.MFACodeReq
+	if err = c.BindJSON(&req); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while decoding mfa code request", err)
+		return
+	}
+
+	if err = req.Validate(); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while validating mfa code request", err)
+		return
+	}
+
+	recoveryCodes, err := h.authService.ConfirmTOTP(c, userID, req.Code)
+	if err != nil {
+		newErrResponse(c, mfaErrStatus(err), "failed while confirming totp", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]any{
+		"recovery_codes": recoveryCodes,
+	})
+}
+
+func (h *Handler) disableTOTP(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrResponse(c, http.StatusForbidden, "failed while getting user id", err)
+		return
+	}
+
+	var req dto.MFACodeReq
+	if err = c.BindJSON(&req); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while decoding mfa code request", err)
+		return
+	}
+
+	if err = req.Validate(); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while validating mfa code request", err)
+		return
+	}
+
+	if err = h.authService.DisableTOTP(c, userID, req.Code); err != nil {
+		newErrResponse(c, mfaErrStatus(err), "failed while disabling totp", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]any{
+		"status": "disabled",
+	})
+}
+
+func mfaErrStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidMFACode):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrMFAAlreadyEnabled),
+		errors.Is(err, service.ErrMFANotEnrolled),
+		errors.Is(err, service.ErrMFANotEnabled):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
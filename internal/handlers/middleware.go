@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/HeadGardener/coursework/internal/lib/auth"
 	"github.com/HeadGardener/coursework/internal/models"
@@ -12,25 +13,37 @@ import (
 )
 
 const (
-	userCtx string = "userAtr"
-	isAdult string = "is_adult"
+	userCtx     string = "userAtr"
+	customerCtx string = "customer"
 )
 
 const (
 	headerPartsLen = 2
+	maxAuthAge     = 10 * time.Minute
 )
 
 var (
 	ErrUserCtxNotExist   = errors.New("userCtx not exists")
 	ErrNotUserAttributes = errors.New("userCtx value is not of type UserAttributes")
-	ErrInvalidRole       = errors.New("user not admin")
-	ErrNotBool           = errors.New("value is not of bool type")
+	ErrPermissionDenied  = errors.New("permission denied")
+	ErrNotCustomer       = errors.New("value is not of type Customer")
+	ErrMFARequired       = errors.New("two-factor authentication required")
+	ErrNotUser           = errors.New("token doesn't belong to a user")
+	ErrReauthRequired    = errors.New("signed in too long ago, sign in again")
+	ErrNotFirstParty     = errors.New("api keys and tokens issued to clients can't be used here")
 )
 
+// identifyUser authenticates the request by the Authorization header. Without it,
+// the access token cookie of the browser auth mode is used.
 func (h *Handler) identifyUser(c *gin.Context) {
 	header := c.GetHeader("Authorization")
 
 	if header == "" {
+		if token, err := c.Cookie(accessTokenCookie); h.cookies.Enabled && err == nil && token != "" {
+			h.identifyByCookie(c, token)
+			return
+		}
+
 		newErrResponse(c, http.StatusUnauthorized, "failed while identifying user",
 			errors.New("empty auth header"))
 		return
@@ -39,24 +52,29 @@ func (h *Handler) identifyUser(c *gin.Context) {
 	headerParts := strings.Split(header, " ")
 	if len(headerParts) != headerPartsLen {
 		newErrResponse(c, http.StatusUnauthorized, "failed while identifying user",
-			errors.New("invalid auth header, must be like `Bearer token`"))
+			errors.New("invalid auth header, must be like `Bearer token` or `ApiKey key`"))
 		return
 	}
 
-	if headerParts[0] != "Bearer" {
+	switch headerParts[0] {
+	case "Bearer":
+		h.identifyByToken(c, headerParts[1])
+	case "ApiKey":
+		h.identifyByAPIKey(c, headerParts[1])
+	default:
 		newErrResponse(c, http.StatusUnauthorized, "failed while identifying user",
-			fmt.Errorf("invalid auth header %s, must be Bearer", headerParts[0]))
-		return
+			fmt.Errorf("invalid auth header %s, must be Bearer or ApiKey", headerParts[0]))
 	}
+}
 
-	token := headerParts[1]
+func (h *Handler) identifyByToken(c *gin.Context, token string) {
 	if token == "" {
 		newErrResponse(c, http.StatusUnauthorized, "failed while identifying user",
 			errors.New("jwt token is empty"))
 		return
 	}
 
-	userAttributes, err := h.authService.ParseAccessToken(token)
+	userAttributes, err := h.authService.ParseAccessToken(c, token)
 	if err != nil {
 		newErrResponse(c, http.StatusUnauthorized, "failed while parsing token", err)
 		return
@@ -65,29 +83,100 @@ func (h *Handler) identifyUser(c *gin.Context) {
 	c.Set(userCtx, userAttributes)
 }
 
-func (h *Handler) identifyRole(c *gin.Context) {
+func (h *Handler) identifyByCookie(c *gin.Context, token string) {
+	if err := checkCSRF(c); err != nil {
+		newErrResponse(c, http.StatusForbidden, "failed while checking csrf token", err)
+		return
+	}
+
+	h.identifyByToken(c, token)
+}
+
+// identifyByAPIKey lets services authenticate with a key instead of a token, the
+// request then carries the same user attributes.
+func (h *Handler) identifyByAPIKey(c *gin.Context, key string) {
+	if key == "" {
+		newErrResponse(c, http.StatusUnauthorized, "failed while identifying user",
+			errors.New("api key is empty"))
+		return
+	}
+
+	userAttributes, err := h.apiKeyService.Authenticate(c, key)
+	if err != nil {
+		newErrResponse(c, http.StatusUnauthorized, "failed while checking api key", err)
+		return
+	}
+
+	c.Set(userCtx, userAttributes)
+}
+
+// requirePermission lets through only users whose token grants permission.
+func (h *Handler) requirePermission(permission models.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userAttributes, err := getUserAttributes(c)
+		if err != nil {
+			newErrResponse(c, http.StatusForbidden, "invalid user ctx", err)
+			return
+		}
+
+		if !userAttributes.HasPermission(permission) {
+			newErrResponse(c, http.StatusForbidden, "failed while checking permissions",
+				fmt.Errorf("%w: %s", ErrPermissionDenied, permission))
+		}
+	}
+}
+
+// requireMFA lets through only tokens issued after a second factor was verified.
+func (h *Handler) requireMFA(c *gin.Context) {
 	userAttributes, err := getUserAttributes(c)
 	if err != nil {
 		newErrResponse(c, http.StatusForbidden, "invalid user ctx", err)
+		return
 	}
 
-	if userAttributes.Role != models.RoleAdmin {
-		newErrResponse(c, http.StatusForbidden, "invalid user role", ErrInvalidRole)
+	if !userAttributes.MFA {
+		newErrResponse(c, http.StatusForbidden, "failed while checking second factor", ErrMFARequired)
 	}
 }
 
-func (h *Handler) checkAge(c *gin.Context) {
+// requireFirstParty keeps API keys and OAuth client tokens away from consent and account
+// management, where their scopes don't apply and they would act with the user's full rights.
+func (h *Handler) requireFirstParty(c *gin.Context) {
 	userAttributes, err := getUserAttributes(c)
 	if err != nil {
 		newErrResponse(c, http.StatusForbidden, "invalid user ctx", err)
+		return
+	}
+
+	if userAttributes.APIKey || userAttributes.ClientID != "" {
+		newErrResponse(c, http.StatusForbidden, "failed while checking credentials", ErrNotFirstParty)
 	}
+}
 
-	if userAttributes.Age < models.AdultAge {
-		c.Set(isAdult, false)
-		c.Next()
+// requireRecentAuth lets through only tokens of sessions signed in within maxAuthAge,
+// refreshing a token doesn't count as signing in.
+func (h *Handler) requireRecentAuth(c *gin.Context) {
+	userAttributes, err := getUserAttributes(c)
+	if err != nil {
+		newErrResponse(c, http.StatusForbidden, "invalid user ctx", err)
+		return
 	}
 
-	c.Set(isAdult, true)
+	if userAttributes.AuthTime.IsZero() || time.Since(userAttributes.AuthTime) > maxAuthAge {
+		newErrResponse(c, http.StatusForbidden, "failed while checking sign in time", ErrReauthRequired)
+	}
+}
+
+// checkAge passes the token's adult-since times on to drink filtering, which
+// compares them with the time of the request.
+func (h *Handler) checkAge(c *gin.Context) {
+	userAttributes, err := getUserAttributes(c)
+	if err != nil {
+		newErrResponse(c, http.StatusForbidden, "invalid user ctx", err)
+		return
+	}
+
+	c.Set(customerCtx, userAttributes.Customer())
 }
 
 func getUserID(c *gin.Context) (string, error) {
@@ -96,21 +185,33 @@ func getUserID(c *gin.Context) (string, error) {
 		return "", err
 	}
 
+	// client credentials tokens act on behalf of a client, not a user
+	if userAttributes.ID == "" {
+		return "", ErrNotUser
+	}
+
 	return userAttributes.ID, nil
 }
 
-func getIsAdult(c *gin.Context) (bool, error) {
-	v, ok := c.Get(isAdult)
+func getClientInfo(c *gin.Context) models.ClientInfo {
+	return models.ClientInfo{
+		UserAgent: c.Request.UserAgent(),
+		IP:        c.ClientIP(),
+	}
+}
+
+func getCustomer(c *gin.Context) (models.Customer, error) {
+	v, ok := c.Get(customerCtx)
 	if !ok {
-		return false, ErrUserCtxNotExist
+		return models.Customer{}, ErrUserCtxNotExist
 	}
 
-	adult, ok := v.(bool)
+	customer, ok := v.(models.Customer)
 	if !ok {
-		return false, ErrNotBool
+		return models.Customer{}, ErrNotCustomer
 	}
 
-	return adult, nil
+	return customer, nil
 }
 
 func getUserAttributes(c *gin.Context) (auth.UserAttributes, error) {
@@ -5,10 +5,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	mock_service "github.com/HeadGardener/coursework/internal/handlers/mocks"
 	"github.com/HeadGardener/coursework/internal/lib/auth"
 	"github.com/HeadGardener/coursework/internal/models"
+	"github.com/HeadGardener/coursework/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/assert/v2"
 	"go.uber.org/mock/gomock"
@@ -32,10 +34,8 @@ func TestIdentifyUserMiddleware(t *testing.T) {
 			headerValue: "Bearer token",
 			token:       "token",
 			mockBehavior: func(s *mock_service.MockAuthService, token string) {
-				s.EXPECT().ParseAccessToken(token).Return(auth.UserAttributes{
-					ID:   "1",
-					Role: models.RoleUser,
-					Age:  20,
+				s.EXPECT().ParseAccessToken(gomock.Any(), token).Return(auth.UserAttributes{
+					ID: "1",
 				}, nil)
 			},
 			expectedStatusCode:   200,
@@ -54,7 +54,7 @@ func TestIdentifyUserMiddleware(t *testing.T) {
 			headerValue:          "Barer token",
 			mockBehavior:         func(s *mock_service.MockAuthService, token string) {},
 			expectedStatusCode:   401,
-			expectedResponseBody: `{"Msg":"failed while identifying user","Error":"invalid auth header Barer, must be Bearer"}`,
+			expectedResponseBody: `{"Msg":"failed while identifying user","Error":"invalid auth header Barer, must be Bearer or ApiKey"}`,
 		},
 		{
 			name:                 "no token",
@@ -70,7 +70,7 @@ func TestIdentifyUserMiddleware(t *testing.T) {
 			headerValue: "Bearer token",
 			token:       "token",
 			mockBehavior: func(s *mock_service.MockAuthService, token string) {
-				s.EXPECT().ParseAccessToken(token).Return(auth.UserAttributes{}, errors.New(""))
+				s.EXPECT().ParseAccessToken(gomock.Any(), token).Return(auth.UserAttributes{}, errors.New(""))
 			},
 			expectedStatusCode:   401,
 			expectedResponseBody: `{"Msg":"failed while parsing token","Error":""}`,
@@ -85,7 +85,7 @@ func TestIdentifyUserMiddleware(t *testing.T) {
 			authService := mock_service.NewMockAuthService(c)
 			tc.mockBehavior(authService, tc.token)
 
-			handler := NewHandler(authService, nil)
+			handler := NewHandler(authService, nil, nil, nil, nil, nil, nil)
 
 			gin.SetMode(gin.ReleaseMode)
 			router := gin.New()
@@ -106,3 +106,457 @@ func TestIdentifyUserMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestIdentifyUserAPIKeyMiddleware(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAPIKeyService, key string)
+
+	testTable := []struct {
+		name                 string
+		headerValue          string
+		key                  string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:        "ok",
+			headerValue: "ApiKey 0a1b2c3d.secret",
+			key:         "0a1b2c3d.secret",
+			mockBehavior: func(s *mock_service.MockAPIKeyService, key string) {
+				s.EXPECT().Authenticate(gomock.Any(), key).Return(auth.UserAttributes{
+					ID: "1",
+				}, nil)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `"1"`,
+		},
+		{
+			name:                 "no key",
+			headerValue:          "ApiKey ",
+			mockBehavior:         func(s *mock_service.MockAPIKeyService, key string) {},
+			expectedStatusCode:   401,
+			expectedResponseBody: `{"Msg":"failed while identifying user","Error":"api key is empty"}`,
+		},
+		{
+			name:        "revoked key",
+			headerValue: "ApiKey 0a1b2c3d.secret",
+			key:         "0a1b2c3d.secret",
+			mockBehavior: func(s *mock_service.MockAPIKeyService, key string) {
+				s.EXPECT().Authenticate(gomock.Any(), key).Return(auth.UserAttributes{}, service.ErrAPIKeyRevoked)
+			},
+			expectedStatusCode:   401,
+			expectedResponseBody: `{"Msg":"failed while checking api key","Error":"api key has been revoked"}`,
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			apiKeyService := mock_service.NewMockAPIKeyService(c)
+			tc.mockBehavior(apiKeyService, tc.key)
+
+			handler := NewHandler(nil, nil, nil, apiKeyService, nil, nil, nil)
+
+			gin.SetMode(gin.ReleaseMode)
+			router := gin.New()
+			router.Use(gin.Recovery())
+			router.Use(handler.identifyUser)
+			router.POST("/protected", gin.HandlerFunc(func(c *gin.Context) {
+				c.JSON(http.StatusOK, "1")
+			}))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "/protected", nil)
+			r.Header.Set("Authorization", tc.headerValue)
+
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+			assert.Equal(t, tc.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestIdentifyUserCookieMiddleware(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAuthService)
+
+	testTable := []struct {
+		name                 string
+		method               string
+		cookiesEnabled       bool
+		authHeader           string
+		cookies              map[string]string
+		csrfHeader           string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:           "safe method",
+			method:         "GET",
+			cookiesEnabled: true,
+			cookies:        map[string]string{accessTokenCookie: "token"},
+			mockBehavior: func(s *mock_service.MockAuthService) {
+				s.EXPECT().ParseAccessToken(gomock.Any(), "token").Return(auth.UserAttributes{ID: "1"}, nil)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `"1"`,
+		},
+		{
+			name:           "unsafe method with csrf token",
+			method:         "POST",
+			cookiesEnabled: true,
+			cookies:        map[string]string{accessTokenCookie: "token", csrfTokenCookie: "csrf"},
+			csrfHeader:     "csrf",
+			mockBehavior: func(s *mock_service.MockAuthService) {
+				s.EXPECT().ParseAccessToken(gomock.Any(), "token").Return(auth.UserAttributes{ID: "1"}, nil)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `"1"`,
+		},
+		{
+			name:                 "unsafe method without csrf token",
+			method:               "POST",
+			cookiesEnabled:       true,
+			cookies:              map[string]string{accessTokenCookie: "token", csrfTokenCookie: "csrf"},
+			mockBehavior:         func(s *mock_service.MockAuthService) {},
+			expectedStatusCode:   403,
+			expectedResponseBody: `{"Msg":"failed while checking csrf token","Error":"csrf token is missing or doesn't match its cookie"}`,
+		},
+		{
+			name:                 "unsafe method with wrong csrf token",
+			method:               "DELETE",
+			cookiesEnabled:       true,
+			cookies:              map[string]string{accessTokenCookie: "token", csrfTokenCookie: "csrf"},
+			csrfHeader:           "other",
+			mockBehavior:         func(s *mock_service.MockAuthService) {},
+			expectedStatusCode:   403,
+			expectedResponseBody: `{"Msg":"failed while checking csrf token","Error":"csrf token is missing or doesn't match its cookie"}`,
+		},
+		{
+			name:           "header takes precedence",
+			method:         "POST",
+			cookiesEnabled: true,
+			authHeader:     "Bearer header-token",
+			cookies:        map[string]string{accessTokenCookie: "token"},
+			mockBehavior: func(s *mock_service.MockAuthService) {
+				s.EXPECT().ParseAccessToken(gomock.Any(), "header-token").Return(auth.UserAttributes{ID: "1"}, nil)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `"1"`,
+		},
+		{
+			name:                 "cookie mode disabled",
+			method:               "GET",
+			cookies:              map[string]string{accessTokenCookie: "token"},
+			mockBehavior:         func(s *mock_service.MockAuthService) {},
+			expectedStatusCode:   401,
+			expectedResponseBody: `{"Msg":"failed while identifying user","Error":"empty auth header"}`,
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			authService := mock_service.NewMockAuthService(c)
+			tc.mockBehavior(authService)
+
+			handler := NewHandler(authService, nil, nil, nil, nil, nil, nil)
+			handler.cookies.Enabled = tc.cookiesEnabled
+
+			gin.SetMode(gin.ReleaseMode)
+			router := gin.New()
+			router.Use(gin.Recovery())
+			router.Use(handler.identifyUser)
+			router.Handle(tc.method, "/protected", gin.HandlerFunc(func(c *gin.Context) {
+				c.JSON(http.StatusOK, "1")
+			}))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tc.method, "/protected", nil)
+			if tc.authHeader != "" {
+				r.Header.Set("Authorization", tc.authHeader)
+			}
+
+			for name, value := range tc.cookies {
+				r.AddCookie(&http.Cookie{Name: name, Value: value})
+			}
+
+			if tc.csrfHeader != "" {
+				r.Header.Set(csrfHeader, tc.csrfHeader)
+			}
+
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+			assert.Equal(t, tc.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestRequireMFAMiddleware(t *testing.T) {
+	testTable := []struct {
+		name                 string
+		userAttributes       auth.UserAttributes
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:                 "ok",
+			userAttributes:       auth.UserAttributes{ID: "1", Roles: []string{models.RoleAdmin}, MFA: true},
+			expectedStatusCode:   200,
+			expectedResponseBody: `"1"`,
+		},
+		{
+			name:                 "no second factor",
+			userAttributes:       auth.UserAttributes{ID: "1", Roles: []string{models.RoleAdmin}},
+			expectedStatusCode:   403,
+			expectedResponseBody: `{"Msg":"failed while checking second factor","Error":"two-factor authentication required"}`,
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := NewHandler(nil, nil, nil, nil, nil, nil, nil)
+
+			gin.SetMode(gin.ReleaseMode)
+			router := gin.New()
+			router.Use(gin.Recovery())
+			router.Use(func(c *gin.Context) {
+				c.Set(userCtx, tc.userAttributes)
+			})
+			router.Use(handler.requireMFA)
+			router.POST("/protected", gin.HandlerFunc(func(c *gin.Context) {
+				c.JSON(http.StatusOK, "1")
+			}))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "/protected", nil)
+
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+			assert.Equal(t, tc.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestRequireFirstPartyMiddleware(t *testing.T) {
+	testTable := []struct {
+		name                 string
+		userAttributes       auth.UserAttributes
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:                 "ok",
+			userAttributes:       auth.UserAttributes{ID: "1", SessionID: "session"},
+			expectedStatusCode:   200,
+			expectedResponseBody: `"1"`,
+		},
+		{
+			name:                 "api key",
+			userAttributes:       auth.UserAttributes{ID: "1", TokenID: "key", APIKey: true},
+			expectedStatusCode:   403,
+			expectedResponseBody: `{"Msg":"failed while checking credentials","Error":"api keys and tokens issued to clients can't be used here"}`,
+		},
+		{
+			name:                 "oauth client token",
+			userAttributes:       auth.UserAttributes{ID: "1", SessionID: "session", ClientID: "partner"},
+			expectedStatusCode:   403,
+			expectedResponseBody: `{"Msg":"failed while checking credentials","Error":"api keys and tokens issued to clients can't be used here"}`,
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := NewHandler(nil, nil, nil, nil, nil, nil, nil)
+
+			gin.SetMode(gin.ReleaseMode)
+			router := gin.New()
+			router.Use(gin.Recovery())
+			router.Use(func(c *gin.Context) {
+				c.Set(userCtx, tc.userAttributes)
+			})
+			router.Use(handler.requireFirstParty)
+			router.POST("/protected", gin.HandlerFunc(func(c *gin.Context) {
+				c.JSON(http.StatusOK, "1")
+			}))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "/protected", nil)
+
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+			assert.Equal(t, tc.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestRequireRecentAuthMiddleware(t *testing.T) {
+	testTable := []struct {
+		name                 string
+		userAttributes       auth.UserAttributes
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:                 "ok",
+			userAttributes:       auth.UserAttributes{ID: "1", AuthTime: time.Now().Add(-time.Minute)},
+			expectedStatusCode:   200,
+			expectedResponseBody: `"1"`,
+		},
+		{
+			name:                 "signed in too long ago",
+			userAttributes:       auth.UserAttributes{ID: "1", AuthTime: time.Now().Add(-time.Hour)},
+			expectedStatusCode:   403,
+			expectedResponseBody: `{"Msg":"failed while checking sign in time","Error":"signed in too long ago, sign in again"}`,
+		},
+		{
+			name:                 "no sign in time",
+			userAttributes:       auth.UserAttributes{ID: "1"},
+			expectedStatusCode:   403,
+			expectedResponseBody: `{"Msg":"failed while checking sign in time","Error":"signed in too long ago, sign in again"}`,
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := NewHandler(nil, nil, nil, nil, nil, nil, nil)
+
+			gin.SetMode(gin.ReleaseMode)
+			router := gin.New()
+			router.Use(gin.Recovery())
+			router.Use(func(c *gin.Context) {
+				c.Set(userCtx, tc.userAttributes)
+			})
+			router.Use(handler.requireRecentAuth)
+			router.POST("/protected", gin.HandlerFunc(func(c *gin.Context) {
+				c.JSON(http.StatusOK, "1")
+			}))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "/protected", nil)
+
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+			assert.Equal(t, tc.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestCheckAgeMiddleware(t *testing.T) {
+	testTable := []struct {
+		name                 string
+		userAttributes       any
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "ok",
+			userAttributes: auth.UserAttributes{
+				ID: "1",
+				AdultSince: map[models.AgeCategory]time.Time{
+					models.AgeCategoryBeerWine: time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC),
+				},
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"AdultSince":{"beer_wine":"2016-01-01T00:00:00Z"}}`,
+		},
+		{
+			name:                 "invalid user ctx",
+			userAttributes:       "1",
+			expectedStatusCode:   403,
+			expectedResponseBody: `{"Msg":"invalid user ctx","Error":"userCtx value is not of type UserAttributes"}`,
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := NewHandler(nil, nil, nil, nil, nil, nil, nil)
+
+			gin.SetMode(gin.ReleaseMode)
+			router := gin.New()
+			router.Use(gin.Recovery())
+			router.Use(func(c *gin.Context) {
+				c.Set(userCtx, tc.userAttributes)
+			})
+			router.Use(handler.checkAge)
+			router.GET("/drinks", gin.HandlerFunc(func(c *gin.Context) {
+				customer, _ := getCustomer(c)
+				c.JSON(http.StatusOK, customer)
+			}))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "/drinks", nil)
+
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+			assert.Equal(t, tc.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestRequirePermissionMiddleware(t *testing.T) {
+	testTable := []struct {
+		name                 string
+		userAttributes       auth.UserAttributes
+		permission           models.Permission
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "ok",
+			userAttributes: auth.UserAttributes{
+				ID:          "1",
+				Roles:       []string{models.RoleBartender},
+				Permissions: []models.Permission{models.PermDrinksUpdate},
+			},
+			permission:           models.PermDrinksUpdate,
+			expectedStatusCode:   200,
+			expectedResponseBody: `"1"`,
+		},
+		{
+			name: "missing permission",
+			userAttributes: auth.UserAttributes{
+				ID:          "1",
+				Roles:       []string{models.RoleBartender},
+				Permissions: []models.Permission{models.PermDrinksUpdate},
+			},
+			permission:           models.PermDrinksDelete,
+			expectedStatusCode:   403,
+			expectedResponseBody: `{"Msg":"failed while checking permissions","Error":"permission denied: drinks:delete"}`,
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := NewHandler(nil, nil, nil, nil, nil, nil, nil)
+
+			gin.SetMode(gin.ReleaseMode)
+			router := gin.New()
+			router.Use(gin.Recovery())
+			router.Use(func(c *gin.Context) {
+				c.Set(userCtx, tc.userAttributes)
+			})
+			router.POST("/protected", handler.requirePermission(tc.permission), gin.HandlerFunc(func(c *gin.Context) {
+				c.JSON(http.StatusOK, "1")
+			}))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "/protected", nil)
+
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+			assert.Equal(t, tc.expectedResponseBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/HeadGardener/coursework/internal/dto"
+	"github.com/HeadGardener/coursework/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) viewConsent(c *gin.Context) {
+	var req dto.AuthorizeReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while decoding authorization request", err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while validating authorization request", err)
+		return
+	}
+
+	consent, err := h.oauthService.PrepareAuthorization(c, req.Request())
+	if err != nil {
+		newErrResponse(c, authorizeErrStatus(err), "failed while checking authorization request", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, consent)
+}
+
+func (h *Handler) authorize(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrResponse(c, http.StatusForbidden, "failed while getting user id", err)
+		return
+	}
+
+	var req dto.ConsentReq
+	if err = c.BindJSON(&req); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while decoding authorization request", err)
+		return
+	}
+
+	if err = req.Validate(); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while validating authorization request", err)
+		return
+	}
+
+	redirectTo, err := h.oauthService.Authorize(c, userID, req.Request(), req.Approve)
+	if err != nil {
+		newErrResponse(c, authorizeErrStatus(err), "failed while authorizing client", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]any{
+		"redirect_to": redirectTo,
+	})
+}
+
+func (h *Handler) issueOAuthToken(c *gin.Context) {
+	var req dto.TokenReq
+	if err := c.ShouldBind(&req); err != nil {
+		newOAuthErrResponse(c, http.StatusBadRequest, "invalid_request", err)
+		return
+	}
+
+	req.ClientID, req.ClientSecret = clientCredentials(c, req.ClientID, req.ClientSecret)
+
+	if err := req.Validate(); err != nil {
+		newOAuthErrResponse(c, http.StatusBadRequest, "invalid_request", err)
+		return
+	}
+
+	token, err := h.oauthService.Exchange(c, req.Request())
+	if err != nil {
+		code, oauthErr := oauthErrCode(err)
+		newOAuthErrResponse(c, code, oauthErr, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+	c.JSON(http.StatusOK, token)
+}
+
+func (h *Handler) introspectToken(c *gin.Context) {
+	var req dto.TokenActionReq
+	if err := c.ShouldBind(&req); err != nil {
+		newOAuthErrResponse(c, http.StatusBadRequest, "invalid_request", err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		newOAuthErrResponse(c, http.StatusBadRequest, "invalid_request", err)
+		return
+	}
+
+	clientID, clientSecret := clientCredentials(c, req.ClientID, req.ClientSecret)
+
+	introspection, err := h.oauthService.Introspect(c, clientID, clientSecret, req.Token)
+	if err != nil {
+		code, oauthErr := oauthErrCode(err)
+		newOAuthErrResponse(c, code, oauthErr, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, introspection)
+}
+
+func (h *Handler) revokeOAuthToken(c *gin.Context) {
+	var req dto.TokenActionReq
+	if err := c.ShouldBind(&req); err != nil {
+		newOAuthErrResponse(c, http.StatusBadRequest, "invalid_request", err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		newOAuthErrResponse(c, http.StatusBadRequest, "invalid_request", err)
+		return
+	}
+
+	clientID, clientSecret := clientCredentials(c, req.ClientID, req.ClientSecret)
+
+	if err := h.oauthService.Revoke(c, clientID, clientSecret, req.Token); err != nil {
+		code, oauthErr := oauthErrCode(err)
+		newOAuthErrResponse(c, code, oauthErr, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]any{
+		"status": "revoked",
+	})
+}
+
+func (h *Handler) listOAuthClients(c *gin.Context) {
+	clients, err := h.oauthService.ListClients(c)
+	if err != nil {
+		newErrResponse(c, http.StatusInternalServerError, "failed while listing oauth clients", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, clients)
+}
+
+func (h *Handler) registerOAuthClient(c *gin.Context) {
+	var req dto.CreateOAuthClientReq
+	if err := c.BindJSON(&req); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while decoding register oauth client request", err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while validating register oauth client request", err)
+		return
+	}
+
+	client, err := h.oauthService.RegisterClient(c, req.Name, req.RedirectURIs, req.GrantTypes, req.ScopeList(), req.Public)
+	if err != nil {
+		newErrResponse(c, http.StatusInternalServerError, "failed while registering oauth client", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, client)
+}
+
+func (h *Handler) deleteOAuthClient(c *gin.Context) {
+	if err := h.oauthService.DeleteClient(c, c.Param("id")); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrClientNotFound) {
+			status = http.StatusNotFound
+		}
+
+		newErrResponse(c, status, "failed while deleting oauth client", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]any{
+		"status": "deleted",
+	})
+}
+
+// clientCredentials prefers HTTP Basic authentication over the form parameters,
+// as RFC 6749 recommends. Basic credentials are form encoded first.
+func clientCredentials(c *gin.Context, clientID, clientSecret string) (string, string) {
+	user, password, ok := c.Request.BasicAuth()
+	if !ok {
+		return clientID, clientSecret
+	}
+
+	if id, err := url.QueryUnescape(user); err == nil {
+		user = id
+	}
+
+	if secret, err := url.QueryUnescape(password); err == nil {
+		password = secret
+	}
+
+	return user, password
+}
+
+func oauthErrCode(err error) (int, string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidClient):
+		return http.StatusUnauthorized, "invalid_client"
+	case errors.Is(err, service.ErrInvalidGrant),
+		errors.Is(err, service.ErrRefreshTokenReused):
+		return http.StatusBadRequest, "invalid_grant"
+	case errors.Is(err, service.ErrUnauthorizedClient):
+		return http.StatusBadRequest, "unauthorized_client"
+	case errors.Is(err, service.ErrUnsupportedGrantType):
+		return http.StatusBadRequest, "unsupported_grant_type"
+	case errors.Is(err, service.ErrInvalidScope):
+		return http.StatusBadRequest, "invalid_scope"
+	case errors.Is(err, service.ErrInvalidOAuthRequest):
+		return http.StatusBadRequest, "invalid_request"
+	default:
+		return http.StatusInternalServerError, "server_error"
+	}
+}
+
+// authorizeErrStatus maps errors of the consent endpoints. They are called by our own
+// frontend, not by the client, so an unknown client is a bad request there.
+func authorizeErrStatus(err error) int {
+	code, _ := oauthErrCode(err)
+	if code == http.StatusUnauthorized {
+		return http.StatusBadRequest
+	}
+
+	return code
+}
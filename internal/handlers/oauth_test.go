@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mock_service "github.com/HeadGardener/coursework/internal/handlers/mocks"
+	"github.com/HeadGardener/coursework/internal/models"
+	"github.com/HeadGardener/coursework/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"go.uber.org/mock/gomock"
+)
+
+func TestIssueOAuthTokenHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockOAuthService)
+
+	testTable := []struct {
+		name                 string
+		inputBody            string
+		basicAuth            []string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:      "client credentials",
+			inputBody: "grant_type=client_credentials&scope=drinks%3Aupdate",
+			basicAuth: []string{"client", "secret"},
+			mockBehavior: func(s *mock_service.MockOAuthService) {
+				s.EXPECT().Exchange(gomock.Any(), models.TokenRequest{
+					GrantType:    models.GrantClientCredentials,
+					Scopes:       []models.Permission{models.PermDrinksUpdate},
+					ClientID:     "client",
+					ClientSecret: "secret",
+				}).Return(models.OAuthToken{
+					AccessToken: "token",
+					TokenType:   "Bearer",
+					ExpiresIn:   900,
+					Scope:       "drinks:update",
+				}, nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"access_token":"token","token_type":"Bearer","expires_in":900,"scope":"drinks:update"}`,
+		},
+		{
+			name:      "invalid client",
+			inputBody: "grant_type=client_credentials",
+			basicAuth: []string{"client", "wrong"},
+			mockBehavior: func(s *mock_service.MockOAuthService) {
+				s.EXPECT().Exchange(gomock.Any(), gomock.Any()).Return(models.OAuthToken{}, service.ErrInvalidClient)
+			},
+			expectedStatusCode:   http.StatusUnauthorized,
+			expectedResponseBody: `{"error":"invalid_client","error_description":"client authentication failed"}`,
+		},
+		{
+			name:      "invalid grant",
+			inputBody: "grant_type=authorization_code&code=used&code_verifier=verifier&client_id=client",
+			mockBehavior: func(s *mock_service.MockOAuthService) {
+				s.EXPECT().Exchange(gomock.Any(), gomock.Any()).Return(models.OAuthToken{}, service.ErrInvalidGrant)
+			},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"error":"invalid_grant","error_description":"invalid authorization grant"}`,
+		},
+		{
+			name:                 "no grant type",
+			inputBody:            "client_id=client",
+			mockBehavior:         func(s *mock_service.MockOAuthService) {},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"error":"invalid_request","error_description":"invalid grant_type: can't be empty"}`,
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			oauthService := mock_service.NewMockOAuthService(c)
+			tc.mockBehavior(oauthService)
+
+			handler := NewHandler(nil, nil, nil, nil, oauthService, nil, nil)
+
+			gin.SetMode(gin.ReleaseMode)
+			router := gin.New()
+			router.Use(gin.Recovery())
+			router.POST("/api/auth/token", handler.issueOAuthToken)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "/api/auth/token", bytes.NewBufferString(tc.inputBody))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tc.basicAuth != nil {
+				r.SetBasicAuth(tc.basicAuth[0], tc.basicAuth[1])
+			}
+
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+			assert.Equal(t, tc.expectedResponseBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/HeadGardener/coursework/internal/dto"
+	"github.com/HeadGardener/coursework/internal/lib/oidc"
+	"github.com/HeadGardener/coursework/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	oidcBindingCookie = "oidc_binding"
+	oidcBindingPath   = "/api/auth/oidc"
+	oidcBindingMaxAge = 10 * time.Minute
+)
+
+func (h *Handler) listOIDCProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, map[string]any{
+		"providers": h.oidcService.Providers(),
+	})
+}
+
+func (h *Handler) startOIDCLogin(c *gin.Context) {
+	authURL, binding, err := h.oidcService.StartLogin(c, c.Param("provider"), "", h.cookieMode(c))
+	if err != nil {
+		newErrResponse(c, oidcErrStatus(err), "failed while starting single sign-on", err)
+		return
+	}
+
+	h.setOIDCBinding(c, binding, int(oidcBindingMaxAge.Seconds()))
+
+	c.JSON(http.StatusOK, map[string]any{
+		"authorization_url": authURL,
+	})
+}
+
+func (h *Handler) startOIDCLink(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrResponse(c, http.StatusForbidden, "failed while getting user id", err)
+		return
+	}
+
+	authURL, binding, err := h.oidcService.StartLogin(c, c.Param("provider"), userID, h.cookieMode(c))
+	if err != nil {
+		newErrResponse(c, oidcErrStatus(err), "failed while starting identity linking", err)
+		return
+	}
+
+	h.setOIDCBinding(c, binding, int(oidcBindingMaxAge.Seconds()))
+
+	c.JSON(http.StatusOK, map[string]any{
+		"authorization_url": authURL,
+	})
+}
+
+func (h *Handler) completeOIDCLogin(c *gin.Context) {
+	var req dto.OIDCCallbackReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while decoding single sign-on callback", err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while validating single sign-on callback", err)
+		return
+	}
+
+	// a missing cookie leaves the binding empty, which never matches
+	binding, _ := c.Cookie(oidcBindingCookie)
+	h.setOIDCBinding(c, "", -1)
+
+	result, err := h.oidcService.CompleteLogin(c, c.Param("provider"), req.Code, req.State, binding, getClientInfo(c))
+	if err != nil {
+		newErrResponse(c, oidcErrStatus(err), "failed while completing single sign-on", err)
+		return
+	}
+
+	if result.MFARequired != nil {
+		c.JSON(http.StatusAccepted, result.MFARequired)
+		return
+	}
+
+	// the redirect from the provider can't carry the auth mode header, the mode
+	// was chosen when the login started
+	h.writeTokensIn(c, http.StatusCreated, result.Tokens, h.cookies.Enabled && result.CookieMode)
+}
+
+// setOIDCBinding sets the cookie that binds a login to the browser. It is sent along
+// with the provider's redirect back, which SameSite=Lax allows for top-level navigation.
+func (h *Handler) setOIDCBinding(c *gin.Context, binding string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     oidcBindingCookie,
+		Value:    binding,
+		Path:     oidcBindingPath,
+		Domain:   h.cookies.Domain,
+		MaxAge:   maxAge,
+		Secure:   h.cookies.Secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func oidcErrStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrUnknownProvider):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrInvalidOIDCState):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrSSOFailed),
+		errors.Is(err, service.ErrIdentityNotLinked):
+		return http.StatusUnauthorized
+	case errors.Is(err, service.ErrUserDisabled):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrIdentityConflict):
+		return http.StatusConflict
+	case errors.Is(err, oidc.ErrDiscovery):
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/HeadGardener/coursework/internal/config"
+	mock_service "github.com/HeadGardener/coursework/internal/handlers/mocks"
+	"github.com/HeadGardener/coursework/internal/models"
+	"github.com/HeadGardener/coursework/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCompleteOIDCLoginHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockOIDCService)
+
+	testTable := []struct {
+		name                 string
+		query                string
+		noBinding            bool
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:  "ok",
+			query: "?code=code&state=state",
+			mockBehavior: func(s *mock_service.MockOIDCService) {
+				s.EXPECT().CompleteLogin(gomock.Any(), "corp", "code", "state", "binding", gomock.Any()).Return(models.SignInResult{
+					Tokens: models.Tokens{AccessToken: "access", RefreshToken: "refresh"},
+				}, nil)
+			},
+			expectedStatusCode:   http.StatusCreated,
+			expectedResponseBody: `{"access_token":"access","refresh_token":"refresh"}`,
+		},
+		{
+			name:  "mfa required",
+			query: "?code=code&state=state",
+			mockBehavior: func(s *mock_service.MockOIDCService) {
+				s.EXPECT().CompleteLogin(gomock.Any(), "corp", "code", "state", "binding", gomock.Any()).Return(models.SignInResult{
+					MFARequired: &models.MFARequired{
+						MFAToken:  "mfa",
+						ExpiresAt: time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC),
+					},
+				}, nil)
+			},
+			expectedStatusCode:   http.StatusAccepted,
+			expectedResponseBody: `{"mfa_token":"mfa","expires_at":"2026-10-18T12:00:00Z"}`,
+		},
+		{
+			name:                 "denied at the provider",
+			query:                "?error=access_denied&state=state",
+			mockBehavior:         func(s *mock_service.MockOIDCService) {},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"Msg":"failed while validating single sign-on callback","Error":"identity provider returned access_denied: "}`,
+		},
+		{
+			name:  "expired state",
+			query: "?code=code&state=state",
+			mockBehavior: func(s *mock_service.MockOIDCService) {
+				s.EXPECT().CompleteLogin(gomock.Any(), "corp", "code", "state", "binding", gomock.Any()).
+					Return(models.SignInResult{}, service.ErrInvalidOIDCState)
+			},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"Msg":"failed while completing single sign-on","Error":"invalid or expired login state"}`,
+		},
+		{
+			name:      "started in another browser",
+			query:     "?code=code&state=state",
+			noBinding: true,
+			mockBehavior: func(s *mock_service.MockOIDCService) {
+				s.EXPECT().CompleteLogin(gomock.Any(), "corp", "code", "state", "", gomock.Any()).
+					Return(models.SignInResult{}, service.ErrInvalidOIDCState)
+			},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"Msg":"failed while completing single sign-on","Error":"invalid or expired login state"}`,
+		},
+		{
+			name:  "identity not linked",
+			query: "?code=code&state=state",
+			mockBehavior: func(s *mock_service.MockOIDCService) {
+				s.EXPECT().CompleteLogin(gomock.Any(), "corp", "code", "state", "binding", gomock.Any()).
+					Return(models.SignInResult{}, service.ErrIdentityNotLinked)
+			},
+			expectedStatusCode:   http.StatusUnauthorized,
+			expectedResponseBody: `{"Msg":"failed while completing single sign-on","Error":"identity isn't linked to any account"}`,
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			oidcService := mock_service.NewMockOIDCService(c)
+			tc.mockBehavior(oidcService)
+
+			handler := NewHandler(nil, nil, nil, nil, nil, oidcService, nil)
+
+			gin.SetMode(gin.ReleaseMode)
+			router := gin.New()
+			router.Use(gin.Recovery())
+			router.GET("/api/auth/oidc/:provider/callback", handler.completeOIDCLogin)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "/api/auth/oidc/corp/callback"+tc.query, nil)
+			if !tc.noBinding {
+				r.AddCookie(&http.Cookie{Name: oidcBindingCookie, Value: "binding"})
+			}
+
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+			assert.Equal(t, tc.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestCompleteOIDCLoginCookieMode(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	oidcService := mock_service.NewMockOIDCService(c)
+	oidcService.EXPECT().CompleteLogin(gomock.Any(), "corp", "code", "state", "binding", gomock.Any()).Return(models.SignInResult{
+		Tokens:     models.Tokens{AccessToken: "access", RefreshToken: "refresh"},
+		CookieMode: true,
+	}, nil)
+
+	handler := NewHandler(nil, nil, nil, nil, nil, oidcService, nil)
+	handler.cookies = config.CookieConfig{
+		Enabled:  true,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+		MaxAge:   time.Hour,
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.GET("/api/auth/oidc/:provider/callback", handler.completeOIDCLogin)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/api/auth/oidc/corp/callback?code=code&state=state", nil)
+	r.AddCookie(&http.Cookie{Name: oidcBindingCookie, Value: "binding"})
+
+	router.ServeHTTP(w, r)
+
+	cookies := make(map[string]*http.Cookie)
+	for _, cookie := range w.Result().Cookies() {
+		cookies[cookie.Name] = cookie
+	}
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "access", cookies[accessTokenCookie].Value)
+	assert.Equal(t, "refresh", cookies[refreshTokenCookie].Value)
+	assert.Equal(t, `{"csrf_token":"`+cookies[csrfTokenCookie].Value+`"}`, w.Body.String())
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/HeadGardener/coursework/internal/dto"
+	"github.com/HeadGardener/coursework/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) changePassword(c *gin.Context) {
+	userAttributes, err := getUserAttributes(c)
+	if err != nil {
+		newErrResponse(c, http.StatusForbidden, "failed while getting user attributes", err)
+		return
+	}
+
+	var req dto.ChangePasswordReq
+	if err = c.BindJSON(&req); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while decoding change password request", err)
+		return
+	}
+
+	if err = req.Validate(); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while validating change password request", err)
+		return
+	}
+
+	if err = h.authService.ChangePassword(c, userAttributes, req.OldPassword, req.NewPassword); err != nil {
+		if errors.Is(err, service.ErrInvalidPassword) {
+			newErrResponse(c, http.StatusForbidden, "failed while changing password", err)
+			return
+		}
+
+		newErrResponse(c, http.StatusInternalServerError, "failed while changing password", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]any{
+		"status": "password changed",
+	})
+}
+
+func (h *Handler) requestPasswordReset(c *gin.Context) {
+	var req dto.PasswordResetReq
+	if err := c.BindJSON(&req); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while decoding password reset request", err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while validating password reset request", err)
+		return
+	}
+
+	if err := h.authService.RequestPasswordReset(c, req.Username); err != nil {
+		newErrResponse(c, http.StatusInternalServerError, "failed while requesting password reset", err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, map[string]any{
+		"status": "if the account exists, a reset token has been sent",
+	})
+}
+
+func (h *Handler) resetPassword(c *gin.Context) {
+	var req dto.ConfirmPasswordResetReq
+	if err := c.BindJSON(&req); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while decoding confirm password reset request", err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while validating confirm password reset request", err)
+		return
+	}
+
+	if err := h.authService.ResetPassword(c, req.Token, req.NewPassword); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while resetting password", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]any{
+		"status": "password reset",
+	})
+}
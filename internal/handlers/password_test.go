@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mock_service "github.com/HeadGardener/coursework/internal/handlers/mocks"
+	"github.com/HeadGardener/coursework/internal/lib/auth"
+	"github.com/HeadGardener/coursework/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"go.uber.org/mock/gomock"
+)
+
+func TestChangePasswordHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAuthService, userAttr auth.UserAttributes)
+
+	userAttr := auth.UserAttributes{
+		ID:        "1",
+		SessionID: "2",
+	}
+
+	testTable := []struct {
+		name                 string
+		inputBody            string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "ok",
+			inputBody: `{
+							"old_password": "oldPass1",
+							"new_password": "newPass1"
+						}`,
+			mockBehavior: func(s *mock_service.MockAuthService, userAttr auth.UserAttributes) {
+				s.EXPECT().ChangePassword(gomock.Any(), userAttr, "oldPass1", "newPass1").Return(nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"status":"password changed"}`,
+		},
+		{
+			name: "invalid new password",
+			inputBody: `{
+							"old_password": "oldPass1",
+							"new_password": "short"
+						}`,
+			mockBehavior:         func(s *mock_service.MockAuthService, userAttr auth.UserAttributes) {},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"Msg":"failed while validating change password request","Error":"invalid new password: must contain only letters and numbers"}`,
+		},
+		{
+			name: "wrong old password",
+			inputBody: `{
+							"old_password": "wrongPass",
+							"new_password": "newPass1"
+						}`,
+			mockBehavior: func(s *mock_service.MockAuthService, userAttr auth.UserAttributes) {
+				s.EXPECT().ChangePassword(gomock.Any(), userAttr, "wrongPass", "newPass1").Return(service.ErrInvalidPassword)
+			},
+			expectedStatusCode:   http.StatusForbidden,
+			expectedResponseBody: `{"Msg":"failed while changing password","Error":"invalid password"}`,
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			authService := mock_service.NewMockAuthService(c)
+			tc.mockBehavior(authService, userAttr)
+
+			handler := NewHandler(authService, nil, nil, nil, nil, nil, nil)
+
+			gin.SetMode(gin.ReleaseMode)
+			router := gin.New()
+			router.Use(gin.Recovery())
+			router.Use(func(c *gin.Context) {
+				c.Set(userCtx, userAttr)
+			})
+			router.PUT("/api/auth/password", handler.changePassword)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("PUT", "/api/auth/password", bytes.NewBufferString(tc.inputBody))
+
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+			assert.Equal(t, tc.expectedResponseBody, w.Body.String())
+		})
+	}
+}
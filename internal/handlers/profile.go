@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/HeadGardener/coursework/internal/dto"
+	"github.com/HeadGardener/coursework/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) viewProfile(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrResponse(c, http.StatusForbidden, "failed while getting user id", err)
+		return
+	}
+
+	user, err := h.authService.GetUser(c, userID)
+	if err != nil {
+		newErrResponse(c, userErrStatus(err), "failed while getting profile", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+func (h *Handler) updateProfile(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrResponse(c, http.StatusForbidden, "failed while getting user id", err)
+		return
+	}
+
+	var req dto.UpdateProfileReq
+	if err = c.BindJSON(&req); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while decoding update profile request", err)
+		return
+	}
+
+	if err = req.Validate(); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while validating update profile request", err)
+		return
+	}
+
+	user, err := h.authService.UpdateProfile(c, userID, req.Username, req.Name)
+	if err != nil {
+		newErrResponse(c, userErrStatus(err), "failed while updating profile", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+func (h *Handler) deleteAccount(c *gin.Context) {
+	userAttributes, err := getUserAttributes(c)
+	if err != nil {
+		newErrResponse(c, http.StatusForbidden, "failed while getting user attributes", err)
+		return
+	}
+
+	var req dto.DeleteAccountReq
+	if err = c.BindJSON(&req); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while decoding delete account request", err)
+		return
+	}
+
+	if err = req.Validate(); err != nil {
+		newErrResponse(c, http.StatusBadRequest, "failed while validating delete account request", err)
+		return
+	}
+
+	if err = h.authService.DeleteAccount(c, userAttributes, req.Password, getClientInfo(c)); err != nil {
+		var retryErr *service.RetryAfterError
+		if errors.As(err, &retryErr) {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryErr.RetryAfter.Seconds()))))
+			newErrResponse(c, http.StatusTooManyRequests, "failed while deleting account", err)
+			return
+		}
+
+		if errors.Is(err, service.ErrInvalidPassword) {
+			newErrResponse(c, http.StatusForbidden, "failed while deleting account", err)
+			return
+		}
+
+		newErrResponse(c, userErrStatus(err), "failed while deleting account", err)
+		return
+	}
+
+	h.clearTokenCookies(c)
+
+	c.JSON(http.StatusOK, map[string]any{
+		"status": "deleted",
+	})
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mock_service "github.com/HeadGardener/coursework/internal/handlers/mocks"
+	"github.com/HeadGardener/coursework/internal/lib/auth"
+	"github.com/HeadGardener/coursework/internal/models"
+	"github.com/HeadGardener/coursework/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"go.uber.org/mock/gomock"
+)
+
+func TestUpdateProfileHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAuthService)
+
+	testTable := []struct {
+		name                 string
+		inputBody            string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:      "ok",
+			inputBody: `{"name": "Bob"}`,
+			mockBehavior: func(s *mock_service.MockAuthService) {
+				s.EXPECT().UpdateProfile(gomock.Any(), "1", "", "Bob").
+					Return(models.UserInfo{ID: "1", Username: "bob", Name: "Bob", Roles: []string{"user"}}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: `{"id":"1","username":"bob","name":"Bob","birth_date":"0001-01-01T00:00:00Z","region":"",` +
+				`"roles":["user"],"disabled":false,"totp_enabled":false,"created_at":"0001-01-01T00:00:00Z"}`,
+		},
+		{
+			name:      "username taken",
+			inputBody: `{"username": "alice"}`,
+			mockBehavior: func(s *mock_service.MockAuthService) {
+				s.EXPECT().UpdateProfile(gomock.Any(), "1", "alice", "").Return(models.UserInfo{}, service.ErrUsernameTaken)
+			},
+			expectedStatusCode:   http.StatusConflict,
+			expectedResponseBody: `{"Msg":"failed while updating profile","Error":"username is already taken"}`,
+		},
+		{
+			name:                 "invalid name",
+			inputBody:            `{"name": "Bob1"}`,
+			mockBehavior:         func(s *mock_service.MockAuthService) {},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"Msg":"failed while validating update profile request","Error":"invalid name: must contain only letters"}`,
+		},
+		{
+			name:                 "nothing to update",
+			inputBody:            `{}`,
+			mockBehavior:         func(s *mock_service.MockAuthService) {},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"Msg":"failed while validating update profile request","Error":"invalid profile: nothing to update"}`,
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			authService := mock_service.NewMockAuthService(c)
+			tc.mockBehavior(authService)
+
+			handler := NewHandler(authService, nil, nil, nil, nil, nil, nil)
+
+			gin.SetMode(gin.ReleaseMode)
+			router := gin.New()
+			router.Use(gin.Recovery())
+			router.Use(func(c *gin.Context) {
+				c.Set(userCtx, auth.UserAttributes{ID: "1"})
+			})
+			router.PATCH("/api/users/me", handler.updateProfile)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("PATCH", "/api/users/me", bytes.NewBufferString(tc.inputBody))
+
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+			assert.Equal(t, tc.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestDeleteAccountHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAuthService, userAttr auth.UserAttributes)
+
+	userAttr := auth.UserAttributes{
+		ID:        "1",
+		SessionID: "2",
+	}
+
+	testTable := []struct {
+		name                 string
+		inputBody            string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:      "ok",
+			inputBody: `{"password": "qwerty123"}`,
+			mockBehavior: func(s *mock_service.MockAuthService, userAttr auth.UserAttributes) {
+				s.EXPECT().DeleteAccount(gomock.Any(), userAttr, "qwerty123", gomock.Any()).Return(nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"status":"deleted"}`,
+		},
+		{
+			name:      "wrong password",
+			inputBody: `{"password": "wrongPass"}`,
+			mockBehavior: func(s *mock_service.MockAuthService, userAttr auth.UserAttributes) {
+				s.EXPECT().DeleteAccount(gomock.Any(), userAttr, "wrongPass", gomock.Any()).Return(service.ErrInvalidPassword)
+			},
+			expectedStatusCode:   http.StatusForbidden,
+			expectedResponseBody: `{"Msg":"failed while deleting account","Error":"invalid password"}`,
+		},
+		{
+			name:      "locked out",
+			inputBody: `{"password": "wrongPass"}`,
+			mockBehavior: func(s *mock_service.MockAuthService, userAttr auth.UserAttributes) {
+				s.EXPECT().DeleteAccount(gomock.Any(), userAttr, "wrongPass", gomock.Any()).Return(&service.RetryAfterError{
+					Err:        service.ErrTooManyAttempts,
+					RetryAfter: 30 * time.Second,
+				})
+			},
+			expectedStatusCode:   http.StatusTooManyRequests,
+			expectedResponseBody: `{"Msg":"failed while deleting account","Error":"too many failed sign in attempts: retry after 30s"}`,
+		},
+		{
+			name:                 "no password",
+			inputBody:            `{}`,
+			mockBehavior:         func(s *mock_service.MockAuthService, userAttr auth.UserAttributes) {},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"Msg":"failed while validating delete account request","Error":"invalid password: can't be empty"}`,
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			authService := mock_service.NewMockAuthService(c)
+			tc.mockBehavior(authService, userAttr)
+
+			handler := NewHandler(authService, nil, nil, nil, nil, nil, nil)
+
+			gin.SetMode(gin.ReleaseMode)
+			router := gin.New()
+			router.Use(gin.Recovery())
+			router.Use(func(c *gin.Context) {
+				c.Set(userCtx, userAttr)
+			})
+			router.DELETE("/api/users/me", handler.deleteAccount)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("DELETE", "/api/users/me", bytes.NewBufferString(tc.inputBody))
+
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+			assert.Equal(t, tc.expectedResponseBody, w.Body.String())
+		})
+	}
+}
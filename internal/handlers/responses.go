@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"log"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
@@ -19,3 +20,23 @@ func newErrResponse(c *gin.Context, code int, msg string, err error) {
 		Error: err.Error(),
 	})
 }
+
+// oauthResponse is the error format of RFC 6749, OAuth client libraries expect it
+// from the token, introspection and revocation endpoints.
+type oauthResponse struct {
+	Error       string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+func newOAuthErrResponse(c *gin.Context, code int, oauthErr string, err error) {
+	log.Printf("[ERROR] oauth %s: %s", oauthErr, err.Error())
+
+	if code == http.StatusUnauthorized {
+		c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+
+	c.AbortWithStatusJSON(code, oauthResponse{
+		Error:       oauthErr,
+		Description: err.Error(),
+	})
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/HeadGardener/coursework/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) viewSessions(c *gin.Context) {
+	userAttributes, err := getUserAttributes(c)
+	if err != nil {
+		newErrResponse(c, http.StatusForbidden, "failed while getting user attributes", err)
+		return
+	}
+
+	sessions, err := h.authService.GetSessions(c, userAttributes.ID, userAttributes.SessionID)
+	if err != nil {
+		newErrResponse(c, http.StatusInternalServerError, "failed while getting sessions", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+func (h *Handler) revokeSession(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrResponse(c, http.StatusForbidden, "failed while getting user id", err)
+		return
+	}
+
+	if err = h.authService.RevokeSession(c, userID, c.Param("id")); err != nil {
+		if errors.Is(err, service.ErrSessionNotFound) {
+			newErrResponse(c, http.StatusNotFound, "failed while revoking session", err)
+			return
+		}
+
+		newErrResponse(c, http.StatusInternalServerError, "failed while revoking session", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]any{
+		"status": "revoked",
+	})
+}
+
+func (h *Handler) revokeOtherSessions(c *gin.Context) {
+	userAttributes, err := getUserAttributes(c)
+	if err != nil {
+		newErrResponse(c, http.StatusForbidden, "failed while getting user attributes", err)
+		return
+	}
+
+	if err = h.authService.RevokeOtherSessions(c, userAttributes.ID, userAttributes.SessionID); err != nil {
+		newErrResponse(c, http.StatusInternalServerError, "failed while revoking sessions", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]any{
+		"status": "revoked",
+	})
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mock_service "github.com/HeadGardener/coursework/internal/handlers/mocks"
+	"github.com/HeadGardener/coursework/internal/lib/auth"
+	"github.com/HeadGardener/coursework/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRevokeSessionHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAuthService, userID, sessionID string)
+
+	testTable := []struct {
+		name                 string
+		userID               string
+		sessionID            string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:      "ok",
+			userID:    "1",
+			sessionID: "2",
+			mockBehavior: func(s *mock_service.MockAuthService, userID, sessionID string) {
+				s.EXPECT().RevokeSession(gomock.Any(), userID, sessionID).Return(nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"status":"revoked"}`,
+		},
+		{
+			name:      "not found",
+			userID:    "1",
+			sessionID: "3",
+			mockBehavior: func(s *mock_service.MockAuthService, userID, sessionID string) {
+				s.EXPECT().RevokeSession(gomock.Any(), userID, sessionID).Return(service.ErrSessionNotFound)
+			},
+			expectedStatusCode:   http.StatusNotFound,
+			expectedResponseBody: `{"Msg":"failed while revoking session","Error":"session not found"}`,
+		},
+		{
+			name:      "service failure",
+			userID:    "1",
+			sessionID: "2",
+			mockBehavior: func(s *mock_service.MockAuthService, userID, sessionID string) {
+				s.EXPECT().RevokeSession(gomock.Any(), userID, sessionID).Return(errors.New(""))
+			},
+			expectedStatusCode:   http.StatusInternalServerError,
+			expectedResponseBody: `{"Msg":"failed while revoking session","Error":""}`,
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			authService := mock_service.NewMockAuthService(c)
+			tc.mockBehavior(authService, tc.userID, tc.sessionID)
+
+			handler := NewHandler(authService, nil, nil, nil, nil, nil, nil)
+
+			gin.SetMode(gin.ReleaseMode)
+			router := gin.New()
+			router.Use(gin.Recovery())
+			router.Use(func(c *gin.Context) {
+				c.Set(userCtx, auth.UserAttributes{ID: tc.userID, SessionID: "current"})
+			})
+			router.DELETE("/api/auth/sessions/:id", handler.revokeSession)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("DELETE", "/api/auth/sessions/"+tc.sessionID, nil)
+
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+			assert.Equal(t, tc.expectedResponseBody, w.Body.String())
+		})
+	}
+}
@@ -4,65 +4,140 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"errors"
+	"sort"
 	"time"
 
 	"github.com/HeadGardener/coursework/internal/config"
 	"github.com/HeadGardener/coursework/internal/models"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUnknownKey           = errors.New("token is signed with an unknown key")
+	ErrInvalidSigningMethod = errors.New("invalid signing method")
 )
 
 type TokenManager struct {
-	SecretKey       []byte
-	AccessTokenTTL  time.Duration
-	InitialLen      int
-	RefreshTokenTTL time.Duration
+	signingKey       *SigningKey
+	verificationKeys map[string]*SigningKey
+	AccessTokenTTL   time.Duration
+	InitialLen       int
+	RefreshTokenTTL  time.Duration
+	ResetTokenTTL    time.Duration
 }
 
 type UserAttributes struct {
-	ID   string          `json:"id"`
-	Role models.UserRole `json:"user_role"`
-	Age  uint8           `json:"age"`
+	ID          string                           `json:"id"`
+	SessionID   string                           `json:"session_id"`
+	TokenID     string                           `json:"token_id"`
+	Roles       []string                         `json:"roles"`
+	Permissions []models.Permission              `json:"permissions"`
+	AdultSince  map[models.AgeCategory]time.Time `json:"adult_since"`
+	MFA         bool                             `json:"mfa"`
+	AuthTime    time.Time                        `json:"auth_time"`
+	ClientID    string                           `json:"client_id"`
+	APIKey      bool                             `json:"api_key"`
+	ExpiresAt   time.Time                        `json:"expires_at"`
+}
+
+func (a UserAttributes) HasPermission(permission models.Permission) bool {
+	return models.Access{Permissions: a.Permissions}.HasPermission(permission)
+}
+
+// Customer returns what drink filtering needs to know about the user. Tokens without
+// the adult_since claim give a customer of unknown age, who is served soft drinks only.
+func (a UserAttributes) Customer() models.Customer {
+	return models.Customer{
+		AdultSince: a.AdultSince,
+	}
 }
 
 type tokenClaims struct {
 	jwt.RegisteredClaims
-	UserID string          `json:"id"`
-	Role   models.UserRole `json:"user_role"`
-	Age    uint8           `json:"age"`
+	UserID      string                                  `json:"id"`
+	SessionID   string                                  `json:"sid"`
+	Roles       []string                                `json:"roles,omitempty"`
+	Permissions []models.Permission                     `json:"perms,omitempty"`
+	AdultSince  map[models.AgeCategory]*jwt.NumericDate `json:"adult_since,omitempty"`
+	MFA         bool                                    `json:"mfa,omitempty"`
+	AuthTime    *jwt.NumericDate                        `json:"auth_time,omitempty"`
+	ClientID    string                                  `json:"cid,omitempty"`
 }
 
-func NewTokenManager(conf *config.TokensConfig) *TokenManager {
-	return &TokenManager{
-		SecretKey:       []byte(conf.SecretKey),
-		AccessTokenTTL:  conf.AccessTokenTTL,
-		InitialLen:      conf.InitialLen,
-		RefreshTokenTTL: conf.RefreshTokenTTL,
+// NewTokenManager signs access tokens with the key from SigningKeyFile, or with the
+// HMAC SecretKey if no key file is configured. Keys from VerificationKeyFiles are only
+// used to verify tokens, which lets a rotated key keep working until its tokens expire.
+func NewTokenManager(conf *config.TokensConfig) (*TokenManager, error) {
+	tm := &TokenManager{
+		verificationKeys: make(map[string]*SigningKey),
+		AccessTokenTTL:   conf.AccessTokenTTL,
+		InitialLen:       conf.InitialLen,
+		RefreshTokenTTL:  conf.RefreshTokenTTL,
+		ResetTokenTTL:    conf.ResetTokenTTL,
+	}
+
+	if conf.SecretKey != "" {
+		tm.signingKey = NewHMACKey(conf.SecretKey)
+		tm.verificationKeys[tm.signingKey.ID] = tm.signingKey
+	}
+
+	if conf.SigningKeyFile != "" {
+		key, err := LoadSigningKey(conf.SigningKeyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		tm.signingKey = key
+		tm.verificationKeys[key.ID] = key
 	}
+
+	for _, path := range conf.VerificationKeyFiles {
+		key, err := LoadVerificationKey(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := tm.verificationKeys[key.ID]; !ok {
+			tm.verificationKeys[key.ID] = key
+		}
+	}
+
+	if tm.signingKey == nil {
+		return nil, errors.New("neither signing key file nor secret key is set")
+	}
+
+	return tm, nil
 }
 
-func (tm *TokenManager) GenerateAccessToken(userID string, role models.UserRole, age uint8) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS512, &tokenClaims{
+// GenerateAccessToken signs a new token for userAttr, TokenID and ExpiresAt are set by the manager.
+func (tm *TokenManager) GenerateAccessToken(userAttr UserAttributes) (string, error) {
+	token := jwt.NewWithClaims(tm.signingKey.Method, &tokenClaims{
 		jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(tm.AccessTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
-		userID,
-		role,
-		age,
+		userAttr.ID,
+		userAttr.SessionID,
+		userAttr.Roles,
+		userAttr.Permissions,
+		adultSinceClaim(userAttr.AdultSince),
+		userAttr.MFA,
+		numericDate(userAttr.AuthTime),
+		userAttr.ClientID,
 	})
 
-	return token.SignedString(tm.SecretKey)
+	if tm.signingKey.ID != "" {
+		token.Header["kid"] = tm.signingKey.ID
+	}
+
+	return token.SignedString(tm.signingKey.Private)
 }
 
 func (tm *TokenManager) ParseAccessToken(accessToken string) (UserAttributes, error) {
-	token, err := jwt.ParseWithClaims(accessToken, &tokenClaims{}, func(token *jwt.Token) (any, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("invalid signing method")
-		}
-
-		return tm.SecretKey, nil
-	})
+	token, err := jwt.ParseWithClaims(accessToken, &tokenClaims{}, tm.verificationKey)
 	if err != nil {
 		return UserAttributes{}, err
 	}
@@ -72,23 +147,11 @@ func (tm *TokenManager) ParseAccessToken(accessToken string) (UserAttributes, er
 		return UserAttributes{}, errors.New("token claims are not of type *tokenClaims")
 	}
 
-	userAttributes := UserAttributes{
-		ID:   claims.UserID,
-		Role: claims.Role,
-		Age:  claims.Age,
-	}
-
-	return userAttributes, nil
+	return claims.userAttributes(), nil
 }
 
 func (tm *TokenManager) ParseAccessTokenWithoutExpirationTime(accessToken string) (UserAttributes, error) {
-	token, err := jwt.ParseWithClaims(accessToken, &tokenClaims{}, func(token *jwt.Token) (any, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("invalid signing method")
-		}
-
-		return tm.SecretKey, nil
-	})
+	token, err := jwt.ParseWithClaims(accessToken, &tokenClaims{}, tm.verificationKey)
 	if err != nil && !errors.Is(err, jwt.ErrTokenExpired) {
 		return UserAttributes{}, err
 	}
@@ -98,13 +161,7 @@ func (tm *TokenManager) ParseAccessTokenWithoutExpirationTime(accessToken string
 		return UserAttributes{}, errors.New("token claims are not of type *tokenClaims")
 	}
 
-	userAttributes := UserAttributes{
-		ID:   claims.UserID,
-		Role: claims.Role,
-		Age:  claims.Age,
-	}
-
-	return userAttributes, nil
+	return claims.userAttributes(), nil
 }
 
 func (tm *TokenManager) GenerateRefreshToken() (string, error) {
@@ -120,6 +177,115 @@ func (tm *TokenManager) GenerateRefreshToken() (string, error) {
 	return token, nil
 }
 
+// GenerateRandomToken returns n random bytes encoded as URL-safe base64.
+func GenerateRandomToken(n int) (string, error) {
+	b := make([]byte, n)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
 func (tm *TokenManager) GetRefreshTokenTTL() time.Duration {
 	return tm.RefreshTokenTTL
 }
+
+func (tm *TokenManager) GenerateResetToken() (string, error) {
+	return GenerateRandomToken(tm.InitialLen)
+}
+
+func (tm *TokenManager) GetResetTokenTTL() time.Duration {
+	return tm.ResetTokenTTL
+}
+
+func (tm *TokenManager) GetAccessTokenTTL() time.Duration {
+	return tm.AccessTokenTTL
+}
+
+// JWKS returns the public keys tokens can be verified with. HMAC keys are never published.
+func (tm *TokenManager) JWKS() JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+
+	for _, key := range tm.verificationKeys {
+		if jwk, ok := key.JWK(); ok {
+			jwks.Keys = append(jwks.Keys, jwk)
+		}
+	}
+
+	sort.Slice(jwks.Keys, func(i, j int) bool {
+		return jwks.Keys[i].Kid < jwks.Keys[j].Kid
+	})
+
+	return jwks
+}
+
+func (tm *TokenManager) verificationKey(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	key, ok := tm.verificationKeys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, ErrInvalidSigningMethod
+	}
+
+	return key.Public, nil
+}
+
+func (c *tokenClaims) userAttributes() UserAttributes {
+	userAttributes := UserAttributes{
+		ID:          c.UserID,
+		SessionID:   c.SessionID,
+		TokenID:     c.ID,
+		Roles:       c.Roles,
+		Permissions: c.Permissions,
+		MFA:         c.MFA,
+		ClientID:    c.ClientID,
+	}
+
+	if len(c.AdultSince) > 0 {
+		userAttributes.AdultSince = make(map[models.AgeCategory]time.Time, len(c.AdultSince))
+		for category, since := range c.AdultSince {
+			if since != nil {
+				userAttributes.AdultSince[category] = since.Time
+			}
+		}
+	}
+
+	if c.AuthTime != nil {
+		userAttributes.AuthTime = c.AuthTime.Time
+	}
+
+	if c.ExpiresAt != nil {
+		userAttributes.ExpiresAt = c.ExpiresAt.Time
+	}
+
+	return userAttributes
+}
+
+func numericDate(t time.Time) *jwt.NumericDate {
+	if t.IsZero() {
+		return nil
+	}
+
+	return jwt.NewNumericDate(t)
+}
+
+// adultSinceClaim turns per category adult-since times into the adult_since claim,
+// which carries what drinks the user may be served without revealing the birth date.
+func adultSinceClaim(adultSince map[models.AgeCategory]time.Time) map[models.AgeCategory]*jwt.NumericDate {
+	if len(adultSince) == 0 {
+		return nil
+	}
+
+	claim := make(map[models.AgeCategory]*jwt.NumericDate, len(adultSince))
+	for category, since := range adultSince {
+		claim[category] = jwt.NewNumericDate(since)
+	}
+
+	return claim
+}
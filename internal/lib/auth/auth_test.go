@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HeadGardener/coursework/internal/config"
+	"github.com/HeadGardener/coursework/internal/models"
+	"github.com/go-playground/assert/v2"
+)
+
+func writeKey(t *testing.T, key any) string {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "key.pem")
+	if err = os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestKeyRotation(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	oldKeyFile := writeKey(t, edKey)
+	newKeyFile := writeKey(t, rsaKey)
+
+	oldManager, err := NewTokenManager(&config.TokensConfig{
+		SigningKeyFile: oldKeyFile,
+		AccessTokenTTL: time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newManager, err := NewTokenManager(&config.TokensConfig{
+		SigningKeyFile:       newKeyFile,
+		VerificationKeyFiles: []string{oldKeyFile},
+		AccessTokenTTL:       time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	oldToken, err := oldManager.GenerateAccessToken(UserAttributes{ID: "user", SessionID: "session", Roles: []string{models.RoleUser}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newToken, err := newManager.GenerateAccessToken(UserAttributes{ID: "user", SessionID: "session", Roles: []string{models.RoleUser}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	userAttr, err := newManager.ParseAccessToken(oldToken)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "user", userAttr.ID)
+
+	_, err = newManager.ParseAccessToken(newToken)
+	assert.Equal(t, nil, err)
+
+	_, err = oldManager.ParseAccessToken(newToken)
+	assert.NotEqual(t, nil, err)
+
+	jwks := newManager.JWKS()
+	assert.Equal(t, 2, len(jwks.Keys))
+}
+
+func TestHMACKeyIsNotPublished(t *testing.T) {
+	tm, err := NewTokenManager(&config.TokensConfig{
+		SecretKey:      "secret",
+		AccessTokenTTL: time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 0, len(tm.JWKS().Keys))
+}
+
+func TestAdultSinceClaim(t *testing.T) {
+	tm, err := NewTokenManager(&config.TokensConfig{
+		SecretKey:      "secret",
+		AccessTokenTTL: time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	adultSince := time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	token, err := tm.GenerateAccessToken(UserAttributes{
+		ID:         "user",
+		AdultSince: map[models.AgeCategory]time.Time{models.AgeCategorySpirits: adultSince},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.Split(token, ".")[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, false, strings.Contains(string(payload), "birth"))
+
+	userAttr, err := tm.ParseAccessToken(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, true, adultSince.Equal(userAttr.AdultSince[models.AgeCategorySpirits]))
+	assert.Equal(t, 1, len(userAttr.AdultSince))
+}
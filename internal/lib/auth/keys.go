@@ -0,0 +1,186 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	kidLen = 16
+)
+
+var (
+	ErrUnsupportedKey = errors.New("unsupported key type: only RSA and Ed25519 keys are supported")
+)
+
+// SigningKey is a key access tokens are signed or verified with. Verification-only
+// keys, kept around while tokens signed by a rotated key are still alive, have no Private part.
+type SigningKey struct {
+	ID      string
+	Method  jwt.SigningMethod
+	Private crypto.PrivateKey
+	Public  crypto.PublicKey
+}
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+func NewHMACKey(secret string) *SigningKey {
+	return &SigningKey{
+		Method:  jwt.SigningMethodHS512,
+		Private: []byte(secret),
+		Public:  []byte(secret),
+	}
+}
+
+// LoadSigningKey reads a PKCS#8 (or PKCS#1 for RSA) private key from a PEM file.
+func LoadSigningKey(path string) (*SigningKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	private, err := parsePrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing key %s: %w", path, err)
+	}
+
+	signer, ok := private.(crypto.Signer)
+	if !ok {
+		return nil, ErrUnsupportedKey
+	}
+
+	key, err := newSigningKey(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	key.Private = private
+
+	return key, nil
+}
+
+// LoadVerificationKey reads a PKIX public key from a PEM file. A private key file
+// is accepted as well, only its public part is kept.
+func LoadVerificationKey(path string) (*SigningKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	public, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		private, privErr := parsePrivateKey(block.Bytes)
+		if privErr != nil {
+			return nil, fmt.Errorf("invalid verification key %s: %w", path, err)
+		}
+
+		signer, ok := private.(crypto.Signer)
+		if !ok {
+			return nil, ErrUnsupportedKey
+		}
+
+		public = signer.Public()
+	}
+
+	return newSigningKey(public)
+}
+
+func (k *SigningKey) JWK() (JWK, bool) {
+	switch public := k.Public.(type) {
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Kid: k.ID,
+			Use: "sig",
+			Alg: k.Method.Alg(),
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(public),
+		}, true
+
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			Kid: k.ID,
+			Use: "sig",
+			Alg: k.Method.Alg(),
+			N:   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+		}, true
+
+	default:
+		// symmetric keys must never be published
+		return JWK{}, false
+	}
+}
+
+func newSigningKey(public crypto.PublicKey) (*SigningKey, error) {
+	var method jwt.SigningMethod
+
+	switch public.(type) {
+	case ed25519.PublicKey:
+		method = jwt.SigningMethodEdDSA
+	case *rsa.PublicKey:
+		method = jwt.SigningMethodRS256
+	default:
+		return nil, ErrUnsupportedKey
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(der)
+
+	return &SigningKey{
+		ID:     base64.RawURLEncoding.EncodeToString(sum[:])[:kidLen],
+		Method: method,
+		Public: public,
+	}, nil
+}
+
+func parsePrivateKey(der []byte) (crypto.PrivateKey, error) {
+	if key, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		return key, nil
+	}
+
+	return x509.ParsePKCS1PrivateKey(der)
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found in %s", path)
+	}
+
+	return block, nil
+}
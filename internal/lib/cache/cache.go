@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	sweepEvery = 1024
+)
+
+type item[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// Cache is an in-process key-value store where each entry lives for its own TTL.
+// Expired entries are dropped lazily on Get and by a sweep every sweepEvery writes.
+type Cache[K comparable, V any] struct {
+	mu     sync.Mutex
+	items  map[K]item[V]
+	writes int
+}
+
+func New[K comparable, V any]() *Cache[K, V] {
+	return &Cache[K, V]{
+		items: make(map[K]item[V]),
+	}
+}
+
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	it, ok := c.items[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+
+	if time.Now().After(it.expiresAt) {
+		delete(c.items, key)
+
+		var zero V
+		return zero, false
+	}
+
+	return it.value, true
+}
+
+func (c *Cache[K, V]) Set(key K, value V, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items[key] = item[V]{
+		value:     value,
+		expiresAt: time.Now().Add(ttl),
+	}
+
+	c.writes++
+	if c.writes >= sweepEvery {
+		c.writes = 0
+		c.sweep()
+	}
+}
+
+func (c *Cache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.items, key)
+}
+
+func (c *Cache[K, V]) sweep() {
+	now := time.Now()
+	for k, it := range c.items {
+		if now.After(it.expiresAt) {
+			delete(c.items, k)
+		}
+	}
+}
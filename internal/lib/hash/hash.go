@@ -1,21 +1,20 @@
 package hash
 
 import (
-	"golang.org/x/crypto/bcrypt"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 )
 
-const (
-	cost = 14
-)
-
-func GetStringHash(password string) string {
-	passwordHash, _ := bcrypt.GenerateFromPassword([]byte(password), cost)
+// GetTokenHash hashes high-entropy random tokens (refresh tokens and the like).
+// Unlike passwords they don't need a slow hash, and a deterministic one lets
+// the hash be used as a lookup key.
+func GetTokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
 
-	return string(passwordHash)
+	return hex.EncodeToString(sum[:])
 }
 
-func CompareHashAndString(passHash []byte, password string) bool {
-	err := bcrypt.CompareHashAndPassword(passHash, []byte(password))
-
-	return err == nil
+func CompareTokenHash(tokenHash, token string) bool {
+	return subtle.ConstantTimeCompare([]byte(tokenHash), []byte(GetTokenHash(token))) == 1
 }
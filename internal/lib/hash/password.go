@@ -0,0 +1,148 @@
+package hash
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	argon2idID = "argon2id"
+	phcParts   = 6
+
+	// maxArgon2Memory in KiB and maxArgon2Iterations bound the cost of verifying
+	// a stored hash, unless the configured parameters are higher.
+	maxArgon2Memory     = 1024 * 1024
+	maxArgon2Iterations = 16
+)
+
+var (
+	ErrUnknownHashFormat = errors.New("unknown password hash format")
+	ErrInvalidHash       = errors.New("invalid password hash")
+)
+
+type Argon2Params struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLen     uint32
+	KeyLen      uint32
+}
+
+// DefaultArgon2Params follow the OWASP recommendation for Argon2id.
+var DefaultArgon2Params = Argon2Params{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 2,
+	SaltLen:     16,
+	KeyLen:      32,
+}
+
+// PasswordHasher hashes passwords with Argon2id into the PHC string format,
+// e.g. $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>. It still verifies bcrypt
+// hashes from before the switch, reporting them as needing a rehash.
+type PasswordHasher struct {
+	params Argon2Params
+}
+
+func NewPasswordHasher(params Argon2Params) *PasswordHasher {
+	return &PasswordHasher{params: params}
+}
+
+func (h *PasswordHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.params.SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.params.Iterations, h.params.Memory, h.params.Parallelism, h.params.KeyLen)
+
+	return fmt.Sprintf("$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2idID, argon2.Version,
+		h.params.Memory, h.params.Iterations, h.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// Compare reports whether password matches passwordHash and whether the hash
+// was made with an old algorithm or parameters and should be replaced.
+func (h *PasswordHasher) Compare(passwordHash, password string) (ok, needsRehash bool, err error) {
+	switch {
+	case strings.HasPrefix(passwordHash, "$"+argon2idID+"$"):
+		return h.compareArgon2id(passwordHash, password)
+
+	case strings.HasPrefix(passwordHash, "$2a$"),
+		strings.HasPrefix(passwordHash, "$2b$"),
+		strings.HasPrefix(passwordHash, "$2y$"):
+		err = bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, false, nil
+		}
+
+		if err != nil {
+			return false, false, err
+		}
+
+		return true, true, nil
+
+	default:
+		return false, false, ErrUnknownHashFormat
+	}
+}
+
+func (h *PasswordHasher) compareArgon2id(passwordHash, password string) (ok, needsRehash bool, err error) {
+	parts := strings.Split(passwordHash, "$")
+	if len(parts) != phcParts {
+		return false, false, ErrInvalidHash
+	}
+
+	var version int
+	if _, err = fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return false, false, ErrInvalidHash
+	}
+
+	var params Argon2Params
+	if _, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return false, false, ErrInvalidHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, false, ErrInvalidHash
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return false, false, ErrInvalidHash
+	}
+
+	params.SaltLen = uint32(len(salt))
+	params.KeyLen = uint32(len(key))
+
+	if !h.acceptable(params) {
+		return false, false, ErrInvalidHash
+	}
+
+	otherKey := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLen)
+	if subtle.ConstantTimeCompare(key, otherKey) != 1 {
+		return false, false, nil
+	}
+
+	return true, version != argon2.Version || params != h.params, nil
+}
+
+// acceptable checks the parameters read from a stored hash before hashing with them.
+// Zero parallelism would panic, huge memory or iterations would let a planted hash
+// exhaust the server, and an empty key would match any password.
+func (h *PasswordHasher) acceptable(params Argon2Params) bool {
+	return params.Parallelism >= 1 &&
+		params.Iterations >= 1 && params.Iterations <= max(maxArgon2Iterations, h.params.Iterations) &&
+		params.Memory >= 8*uint32(params.Parallelism) && params.Memory <= max(maxArgon2Memory, h.params.Memory) &&
+		params.KeyLen > 0
+}
@@ -0,0 +1,121 @@
+package hash
+
+import (
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var testParams = Argon2Params{
+	Memory:      1024,
+	Iterations:  1,
+	Parallelism: 1,
+	SaltLen:     16,
+	KeyLen:      32,
+}
+
+func TestPasswordHasher(t *testing.T) {
+	hasher := NewPasswordHasher(testParams)
+
+	argonHash, err := hasher.Hash("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	oldParamsHash, err := NewPasswordHasher(Argon2Params{
+		Memory:      512,
+		Iterations:  1,
+		Parallelism: 1,
+		SaltLen:     16,
+		KeyLen:      32,
+	}).Hash("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testTable := []struct {
+		name                string
+		hash                string
+		password            string
+		expectedOK          bool
+		expectedNeedsRehash bool
+		expectedError       error
+	}{
+		{
+			name:       "argon2id",
+			hash:       argonHash,
+			password:   "password",
+			expectedOK: true,
+		},
+		{
+			name:     "argon2id wrong password",
+			hash:     argonHash,
+			password: "wrong",
+		},
+		{
+			name:                "argon2id old params",
+			hash:                oldParamsHash,
+			password:            "password",
+			expectedOK:          true,
+			expectedNeedsRehash: true,
+		},
+		{
+			name:                "bcrypt",
+			hash:                string(bcryptHash),
+			password:            "password",
+			expectedOK:          true,
+			expectedNeedsRehash: true,
+		},
+		{
+			name:     "bcrypt wrong password",
+			hash:     string(bcryptHash),
+			password: "wrong",
+		},
+		{
+			name:          "unknown format",
+			hash:          "plain",
+			password:      "plain",
+			expectedError: ErrUnknownHashFormat,
+		},
+		{
+			name:          "malformed argon2id",
+			hash:          "$argon2id$v=19$m=1024$salt",
+			password:      "password",
+			expectedError: ErrInvalidHash,
+		},
+		{
+			name:          "argon2id without parallelism",
+			hash:          "$argon2id$v=19$m=1024,t=1,p=0$c2FsdHNhbHRzYWx0c2FsdA$a2V5",
+			password:      "password",
+			expectedError: ErrInvalidHash,
+		},
+		{
+			name:          "argon2id with huge memory",
+			hash:          "$argon2id$v=19$m=4294967295,t=1,p=1$c2FsdHNhbHRzYWx0c2FsdA$a2V5",
+			password:      "password",
+			expectedError: ErrInvalidHash,
+		},
+		{
+			name:          "argon2id with empty key",
+			hash:          "$argon2id$v=19$m=1024,t=1,p=1$c2FsdHNhbHRzYWx0c2FsdA$",
+			password:      "password",
+			expectedError: ErrInvalidHash,
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			ok, needsRehash, err := hasher.Compare(tc.hash, tc.password)
+
+			assert.Equal(t, tc.expectedError, err)
+			assert.Equal(t, tc.expectedOK, ok)
+			assert.Equal(t, tc.expectedNeedsRehash, needsRehash)
+		})
+	}
+}
@@ -0,0 +1,330 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HeadGardener/coursework/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	discoveryPath   = "/.well-known/openid-configuration"
+	metadataTTL     = time.Hour
+	keysRefetchWait = time.Minute
+	clockSkew       = time.Minute
+	httpTimeout     = 10 * time.Second
+	maxResponseSize = 1 << 20
+)
+
+var (
+	ErrInvalidIDToken = errors.New("invalid id token")
+	ErrTokenExchange  = errors.New("failed to exchange authorization code")
+	ErrDiscovery      = errors.New("failed to fetch provider metadata")
+)
+
+// signingMethods are the ID token algorithms accepted. Symmetric ones are left out,
+// the client secret must never be usable to forge an ID token.
+var signingMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// Discovery is the part of the provider metadata the relying party needs.
+type Discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Claims are the ID token claims used to find or provision the user.
+type Claims struct {
+	jwt.RegisteredClaims
+	AuthorizedParty   string   `json:"azp,omitempty"`
+	Nonce             string   `json:"nonce,omitempty"`
+	PreferredUsername string   `json:"preferred_username,omitempty"`
+	Name              string   `json:"name,omitempty"`
+	GivenName         string   `json:"given_name,omitempty"`
+	Birthdate         string   `json:"birthdate,omitempty"`
+	AMR               []string `json:"amr,omitempty"`
+}
+
+type tokenResponse struct {
+	IDToken string `json:"id_token"`
+}
+
+// Provider is an OpenID Connect provider the service is registered at as a confidential
+// client. Its metadata and signing keys are fetched lazily and cached.
+type Provider struct {
+	conf   config.OIDCProviderConfig
+	client *http.Client
+
+	mu          sync.Mutex
+	discovery   *Discovery
+	fetchedAt   time.Time
+	keys        map[string]crypto.PublicKey
+	keysFetched time.Time
+}
+
+func NewProvider(conf config.OIDCProviderConfig) *Provider {
+	return &Provider{
+		conf:   conf,
+		client: &http.Client{Timeout: httpTimeout},
+	}
+}
+
+func (p *Provider) Name() string {
+	return p.conf.Name
+}
+
+// AutoProvision reports whether unknown subjects get a new account.
+func (p *Provider) AutoProvision() bool {
+	return p.conf.AutoProvision
+}
+
+// TrustMFA reports whether a second factor the ID token claims in amr is taken
+// as done, the provider has to be trusted to enforce it.
+func (p *Provider) TrustMFA() bool {
+	return p.conf.TrustMFA
+}
+
+// AuthCodeURL returns the address of the provider's login page. The code is bound
+// to the PKCE challenge and the ID token will carry nonce.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	discovery, err := p.getDiscovery(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := url.Parse(discovery.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid authorization endpoint: %w", ErrDiscovery, err)
+	}
+
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.conf.ClientID)
+	query.Set("redirect_uri", p.conf.RedirectURL)
+	query.Set("scope", strings.Join(p.conf.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+	authURL.RawQuery = query.Encode()
+
+	return authURL.String(), nil
+}
+
+// Exchange redeems the authorization code at the token endpoint and returns the
+// claims of the validated ID token.
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Claims, error) {
+	discovery, err := p.getDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.conf.RedirectURL},
+		"code_verifier": {codeVerifier},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTokenExchange, err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(p.conf.ClientID), url.QueryEscape(p.conf.ClientSecret))
+
+	var token tokenResponse
+	if err = p.do(req, &token); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTokenExchange, err)
+	}
+
+	if token.IDToken == "" {
+		return nil, fmt.Errorf("%w: no id token in the response", ErrTokenExchange)
+	}
+
+	return p.VerifyIDToken(ctx, token.IDToken, nonce)
+}
+
+// VerifyIDToken checks the signature, issuer, audience, lifetime and nonce of the token
+// as OpenID Connect Core 3.1.3.7 requires.
+func (p *Provider) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (*Claims, error) {
+	discovery, err := p.getDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims Claims
+
+	_, err = jwt.ParseWithClaims(rawIDToken, &claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.getKey(ctx, kid)
+	},
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithIssuer(discovery.Issuer),
+		jwt.WithAudience(p.conf.ClientID),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(clockSkew),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidIDToken, err)
+	}
+
+	if claims.ExpiresAt == nil {
+		return nil, fmt.Errorf("%w: no expiration time", ErrInvalidIDToken)
+	}
+
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: no subject", ErrInvalidIDToken)
+	}
+
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != p.conf.ClientID {
+		return nil, fmt.Errorf("%w: issued to another party", ErrInvalidIDToken)
+	}
+
+	if claims.Nonce != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+
+	return &claims, nil
+}
+
+// getDiscovery returns the cached metadata or fetches it anew. The lock isn't held
+// during the request, so a slow provider doesn't stall every login waiting on it.
+func (p *Provider) getDiscovery(ctx context.Context) (*Discovery, error) {
+	p.mu.Lock()
+	discovery, fetchedAt := p.discovery, p.fetchedAt
+	p.mu.Unlock()
+
+	if discovery != nil && time.Since(fetchedAt) < metadataTTL {
+		return discovery, nil
+	}
+
+	discovery, err := p.fetchDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	p.discovery = discovery
+	p.fetchedAt = time.Now()
+	p.mu.Unlock()
+
+	return discovery, nil
+}
+
+func (p *Provider) fetchDiscovery(ctx context.Context) (*Discovery, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(p.conf.Issuer, "/")+discoveryPath, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDiscovery, err)
+	}
+
+	var discovery Discovery
+	if err = p.do(req, &discovery); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDiscovery, err)
+	}
+
+	// the issuer must match exactly, otherwise another provider could vouch for it
+	if discovery.Issuer != p.conf.Issuer {
+		return nil, fmt.Errorf("%w: issuer %q doesn't match %q", ErrDiscovery, discovery.Issuer, p.conf.Issuer)
+	}
+
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, fmt.Errorf("%w: endpoints are missing", ErrDiscovery)
+	}
+
+	return &discovery, nil
+}
+
+// getKey returns the signing key with the given id. An unknown id makes the key
+// set be fetched again, since the provider may have rotated its keys, but not more
+// often than keysRefetchWait. The fetch is claimed under the lock and made without it.
+func (p *Provider) getKey(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	key, ok := p.lookupKey(kid)
+	refetch := !ok && time.Since(p.keysFetched) >= keysRefetchWait
+	if refetch {
+		p.keysFetched = time.Now()
+	}
+	jwksURI := p.discovery.JWKSURI
+	p.mu.Unlock()
+
+	if ok {
+		return key, nil
+	}
+
+	if !refetch {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwksURI, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var set jwks
+	if err = p.do(req, &set); err != nil {
+		return nil, fmt.Errorf("failed to fetch signing keys: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.keys = set.publicKeys()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// lookupKey finds a key by id. Tokens without a kid are accepted only while
+// the provider publishes a single key.
+func (p *Provider) lookupKey(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+
+	key, ok := p.keys[kid]
+
+	return key, ok
+}
+
+func (p *Provider) do(req *http.Request, v any) error {
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return err
+	}
+
+	// the body may echo secrets or internals of the provider, it's logged but kept
+	// out of the error, which can reach the client
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("[ERROR] %s responded with %d: %s", req.URL.Host, resp.StatusCode, body)
+		return fmt.Errorf("%s responded with %d", req.URL.Host, resp.StatusCode)
+	}
+
+	return json.Unmarshal(body, v)
+}
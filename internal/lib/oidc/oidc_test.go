@@ -0,0 +1,206 @@
+package oidc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HeadGardener/coursework/internal/config"
+	"github.com/HeadGardener/coursework/internal/lib/oidc/oidctest"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testRedirectURL = "https://app.example.com/oidc/callback"
+	testVerifier    = "verifier-verifier-verifier-verifier-verifier"
+)
+
+func newTestProvider(t *testing.T) (*Provider, *oidctest.Server) {
+	t.Helper()
+
+	idp, err := oidctest.NewServer("coursework", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(idp.Close)
+
+	return NewProvider(config.OIDCProviderConfig{
+		Name:         "corp",
+		Issuer:       idp.Issuer(),
+		ClientID:     idp.ClientID,
+		ClientSecret: idp.ClientSecret,
+		RedirectURL:  testRedirectURL,
+		Scopes:       []string{"openid", "profile"},
+	}), idp
+}
+
+func challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	provider, idp := newTestProvider(t)
+	idp.AddUser(oidctest.User{Subject: "alice-sub", PreferredUsername: "alice", Birthdate: "1990-05-01"})
+
+	ctx := context.Background()
+
+	authURL, err := provider.AuthCodeURL(ctx, "state", "nonce", challenge(testVerifier))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	callback, err := idp.Authorize(authURL, "alice-sub")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "state", callback.Query().Get("state"))
+
+	_, err = provider.Exchange(ctx, callback.Query().Get("code"), "wrong-verifier", "nonce")
+	assert.Equal(t, true, errors.Is(err, ErrTokenExchange))
+	assert.Equal(t, false, strings.Contains(err.Error(), "invalid_grant"))
+
+	authURL, err = provider.AuthCodeURL(ctx, "state", "nonce", challenge(testVerifier))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	callback, err = idp.Authorize(authURL, "alice-sub")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := provider.Exchange(ctx, callback.Query().Get("code"), testVerifier, "nonce")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "alice-sub", claims.Subject)
+	assert.Equal(t, "alice", claims.PreferredUsername)
+	assert.Equal(t, "1990-05-01", claims.Birthdate)
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	provider, idp := newTestProvider(t)
+	user := oidctest.User{Subject: "alice-sub"}
+
+	testTable := []struct {
+		name      string
+		claims    func() jwt.MapClaims
+		expectErr bool
+	}{
+		{
+			name: "ok",
+			claims: func() jwt.MapClaims {
+				return idp.Claims(user, "nonce")
+			},
+		},
+		{
+			name: "wrong nonce",
+			claims: func() jwt.MapClaims {
+				return idp.Claims(user, "other")
+			},
+			expectErr: true,
+		},
+		{
+			name: "wrong audience",
+			claims: func() jwt.MapClaims {
+				claims := idp.Claims(user, "nonce")
+				claims["aud"] = "other-client"
+
+				return claims
+			},
+			expectErr: true,
+		},
+		{
+			name: "another authorized party",
+			claims: func() jwt.MapClaims {
+				claims := idp.Claims(user, "nonce")
+				claims["aud"] = []string{idp.ClientID, "other-client"}
+				claims["azp"] = "other-client"
+
+				return claims
+			},
+			expectErr: true,
+		},
+		{
+			name: "wrong issuer",
+			claims: func() jwt.MapClaims {
+				claims := idp.Claims(user, "nonce")
+				claims["iss"] = "https://evil.example.com"
+
+				return claims
+			},
+			expectErr: true,
+		},
+		{
+			name: "expired",
+			claims: func() jwt.MapClaims {
+				claims := idp.Claims(user, "nonce")
+				claims["exp"] = time.Now().Add(-time.Hour).Unix()
+
+				return claims
+			},
+			expectErr: true,
+		},
+		{
+			name: "no expiration",
+			claims: func() jwt.MapClaims {
+				claims := idp.Claims(user, "nonce")
+				delete(claims, "exp")
+
+				return claims
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			idToken, err := idp.SignIDToken(test.claims())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			claims, err := provider.VerifyIDToken(context.Background(), idToken, "nonce")
+			if test.expectErr {
+				assert.Equal(t, true, errors.Is(err, ErrInvalidIDToken))
+				return
+			}
+
+			assert.Equal(t, nil, err)
+			assert.Equal(t, "alice-sub", claims.Subject)
+		})
+	}
+}
+
+func TestVerifyIDTokenRejectsHMAC(t *testing.T) {
+	provider, idp := newTestProvider(t)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, idp.Claims(oidctest.User{Subject: "alice-sub"}, "nonce"))
+
+	idToken, err := token.SignedString([]byte(idp.ClientSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = provider.VerifyIDToken(context.Background(), idToken, "nonce")
+	assert.Equal(t, true, errors.Is(err, ErrInvalidIDToken))
+}
+
+func TestIssuerMismatch(t *testing.T) {
+	_, idp := newTestProvider(t)
+
+	provider := NewProvider(config.OIDCProviderConfig{
+		Name:        "corp",
+		Issuer:      idp.Issuer() + "/",
+		ClientID:    idp.ClientID,
+		RedirectURL: testRedirectURL,
+	})
+
+	_, err := provider.AuthCodeURL(context.Background(), "state", "nonce", challenge(testVerifier))
+	assert.Equal(t, true, errors.Is(err, ErrDiscovery))
+}
@@ -0,0 +1,56 @@
+package totp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+)
+
+// test vectors from RFC 6238 appendix B, SHA1 variant truncated to 6 digits
+func TestCode(t *testing.T) {
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+	testTable := []struct {
+		time int64
+		code string
+	}{
+		{time: 59, code: "287082"},
+		{time: 1111111109, code: "081804"},
+		{time: 1111111111, code: "050471"},
+		{time: 1234567890, code: "005924"},
+		{time: 2000000000, code: "279037"},
+	}
+
+	for _, tc := range testTable {
+		code, err := Code(secret, time.Unix(tc.time, 0))
+		assert.Equal(t, nil, err)
+		assert.Equal(t, tc.code, code)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+
+	code, err := Code(secret, now.Add(-Period))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, ok := Validate(secret, code, now)
+	assert.Equal(t, true, ok)
+
+	code, err = Code(secret, now.Add(-3*Period))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, ok = Validate(secret, code, now)
+	assert.Equal(t, false, ok)
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// UserFilter selects a page of users, Query matches username or name.
+type UserFilter struct {
+	Query   string
+	Page    int
+	PerPage int
+}
+
+// UserInfo is the account data shown to admins and to the user themselves, without secrets.
+type UserInfo struct {
+	ID          string    `json:"id"`
+	Username    string    `json:"username"`
+	Name        string    `json:"name"`
+	BirthDate   time.Time `json:"birth_date"`
+	Region      string    `json:"region"`
+	Roles       []string  `json:"roles"`
+	Disabled    bool      `json:"disabled"`
+	TOTPEnabled bool      `json:"totp_enabled"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type UserPage struct {
+	Users   []UserInfo `json:"users"`
+	Total   int        `json:"total"`
+	Page    int        `json:"page"`
+	PerPage int        `json:"per_page"`
+}
+
+func (u *User) Info(roles []string) UserInfo {
+	return UserInfo{
+		ID:          u.ID,
+		Username:    u.Username,
+		Name:        u.Name,
+		BirthDate:   u.BirthDate,
+		Region:      u.Region,
+		Roles:       roles,
+		Disabled:    u.Disabled,
+		TOTPEnabled: u.TOTPEnabled,
+		CreatedAt:   u.CreatedAt,
+	}
+}
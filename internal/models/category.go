@@ -0,0 +1,122 @@
+package models
+
+// Category groups drinks, e.g. spirits → whiskey → bourbon. AgeCategory is the default
+// for new drinks in it and its subcategories, nil inherits the parent's.
+type Category struct {
+	ID          int          `db:"id" json:"id"`
+	Name        string       `db:"name" json:"name"`
+	ParentID    *int         `db:"parent_id" json:"parent_id"`
+	AgeCategory *AgeCategory `db:"age_category" json:"age_category"`
+}
+
+// CategoryNode is a category with its subcategories. DrinkCount includes the drinks
+// of all subcategories.
+type CategoryNode struct {
+	Category
+	DrinkCount int             `json:"drink_count"`
+	Children   []*CategoryNode `json:"children"`
+}
+
+// BuildCategoryTree arranges categories into trees and sums up the drink counts,
+// keyed by category ID, from the leaves to the roots.
+func BuildCategoryTree(categories []Category, counts map[int]int) []*CategoryNode {
+	nodes := make(map[int]*CategoryNode, len(categories))
+	for _, category := range categories {
+		nodes[category.ID] = &CategoryNode{
+			Category: category,
+			Children: []*CategoryNode{},
+		}
+	}
+
+	roots := []*CategoryNode{}
+
+	for _, category := range categories {
+		node := nodes[category.ID]
+
+		if category.ParentID == nil || nodes[*category.ParentID] == nil {
+			roots = append(roots, node)
+			continue
+		}
+
+		parent := nodes[*category.ParentID]
+		parent.Children = append(parent.Children, node)
+	}
+
+	for _, root := range roots {
+		countDrinks(root, counts)
+	}
+
+	return roots
+}
+
+func countDrinks(node *CategoryNode, counts map[int]int) int {
+	node.DrinkCount = counts[node.ID]
+	for _, child := range node.Children {
+		node.DrinkCount += countDrinks(child, counts)
+	}
+
+	return node.DrinkCount
+}
+
+// DefaultAgeCategory returns the age category of the category with the given ID,
+// inherited from the nearest ancestor that has one. It reports false for an unknown ID.
+func DefaultAgeCategory(categories []Category, id int) (AgeCategory, bool) {
+	byID := make(map[int]Category, len(categories))
+	for _, c := range categories {
+		byID[c.ID] = c
+	}
+
+	current, ok := byID[id]
+	if !ok {
+		return "", false
+	}
+
+	// a visited set guards against a cycle in broken data
+	visited := make(map[int]bool, len(categories))
+
+	for !visited[current.ID] {
+		visited[current.ID] = true
+
+		if current.AgeCategory != nil {
+			return *current.AgeCategory, true
+		}
+
+		if current.ParentID == nil {
+			break
+		}
+
+		if current, ok = byID[*current.ParentID]; !ok {
+			break
+		}
+	}
+
+	return "", true
+}
+
+// IsDescendant reports whether the category with the given ID is ancestorID itself
+// or lies below it.
+func IsDescendant(categories []Category, id, ancestorID int) bool {
+	parents := make(map[int]*int, len(categories))
+	for _, c := range categories {
+		parents[c.ID] = c.ParentID
+	}
+
+	visited := make(map[int]bool, len(categories))
+
+	for !visited[id] {
+		if id == ancestorID {
+			return true
+		}
+
+		visited[id] = true
+
+		parentID, ok := parents[id]
+		if !ok || parentID == nil {
+			return false
+		}
+
+		id = *parentID
+	}
+
+	return false
+}
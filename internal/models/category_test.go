@@ -0,0 +1,90 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+)
+
+func testCategories() []Category {
+	spirits, whiskey := 1, 2
+	spiritsAge := AgeCategorySpirits
+	softAge := AgeCategorySoft
+
+	return []Category{
+		{ID: 1, Name: "Spirits", AgeCategory: &spiritsAge},
+		{ID: 2, Name: "Whiskey", ParentID: &spirits},
+		{ID: 3, Name: "Bourbon", ParentID: &whiskey},
+		{ID: 4, Name: "Soft drinks", AgeCategory: &softAge},
+		{ID: 5, Name: "Other"},
+	}
+}
+
+func TestBuildCategoryTree(t *testing.T) {
+	tree := BuildCategoryTree(testCategories(), map[int]int{1: 1, 2: 2, 3: 4, 4: 3})
+
+	assert.Equal(t, 3, len(tree))
+
+	spirits := tree[0]
+	assert.Equal(t, "Spirits", spirits.Name)
+	assert.Equal(t, 7, spirits.DrinkCount)
+	assert.Equal(t, 1, len(spirits.Children))
+
+	whiskey := spirits.Children[0]
+	assert.Equal(t, 6, whiskey.DrinkCount)
+	assert.Equal(t, "Bourbon", whiskey.Children[0].Name)
+	assert.Equal(t, 4, whiskey.Children[0].DrinkCount)
+	assert.Equal(t, 0, len(whiskey.Children[0].Children))
+
+	assert.Equal(t, 3, tree[1].DrinkCount)
+	assert.Equal(t, 0, tree[2].DrinkCount)
+}
+
+func TestDefaultAgeCategory(t *testing.T) {
+	testTable := []struct {
+		name             string
+		id               int
+		expectedCategory AgeCategory
+		expectedOK       bool
+	}{
+		{
+			name:             "own default",
+			id:               4,
+			expectedCategory: AgeCategorySoft,
+			expectedOK:       true,
+		},
+		{
+			name:             "inherited from grandparent",
+			id:               3,
+			expectedCategory: AgeCategorySpirits,
+			expectedOK:       true,
+		},
+		{
+			name:       "no default",
+			id:         5,
+			expectedOK: true,
+		},
+		{
+			name: "unknown category",
+			id:   42,
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			category, ok := DefaultAgeCategory(testCategories(), tc.id)
+
+			assert.Equal(t, tc.expectedCategory, category)
+			assert.Equal(t, tc.expectedOK, ok)
+		})
+	}
+}
+
+func TestIsDescendant(t *testing.T) {
+	categories := testCategories()
+
+	assert.Equal(t, true, IsDescendant(categories, 3, 1))
+	assert.Equal(t, true, IsDescendant(categories, 2, 2))
+	assert.Equal(t, false, IsDescendant(categories, 1, 3))
+	assert.Equal(t, false, IsDescendant(categories, 4, 1))
+}
@@ -1,10 +1,158 @@
 package models
 
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
 type Drink struct {
-	ID     int    `db:"id"`
-	Name   string `db:"name"`
-	Type   string `db:"type"`
-	Bottle int    `db:"bottle"`
-	Cost   int    `db:"cost"`
-	Soft   bool   `db:"is_soft"`
+	ID         int     `db:"id"`
+	Name       string  `db:"name"`
+	CategoryID int     `db:"category_id"`
+	Bottle     int     `db:"bottle"`
+	Cost       Money   `db:"cost"`
+	ABV        float64 `db:"abv"`
+
+	AgeCategory AgeCategory `db:"age_category"`
+}
+
+// DrinkPatch changes the fields of a drink that aren't nil. CostAmount is a decimal read
+// in the currency the drink ends up with. ResetAgeCategory derives the age category
+// anew, as if the drink was added without one.
+type DrinkPatch struct {
+	Name             *string
+	CategoryID       *int
+	Bottle           *int
+	CostAmount       *string
+	Currency         *Currency
+	ABV              *float64
+	AgeCategory      *AgeCategory
+	ResetAgeCategory bool
+}
+
+// DrinkFilter selects drinks a customer may be served. Drinks with ABV up to
+// SoftDrinkMaxABV count as soft whatever their category is.
+type DrinkFilter struct {
+	AgeCategories   []AgeCategory
+	SoftDrinkMaxABV float64
+}
+
+type DrinkSort string
+
+const (
+	DrinkSortID   DrinkSort = "id"
+	DrinkSortName DrinkSort = "name"
+	DrinkSortCost DrinkSort = "cost"
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+func (s DrinkSort) Valid() bool {
+	switch s {
+	case DrinkSortID, DrinkSortName, DrinkSortCost:
+		return true
+	default:
+		return false
+	}
+}
+
+// DrinkQuery narrows down and orders the catalog. Nil bounds, an empty Currency and
+// empty Categories don't filter anything, a category matches its subcategories too.
+// Cost bounds are in minor units of Currency. Soft picks either soft or alcoholic drinks. A page starts right after
+// the drink After points to.
+type DrinkQuery struct {
+	Categories []int
+	Currency   Currency
+	MinCost    *int
+	MaxCost    *int
+	MinBottle  *int
+	MaxBottle  *int
+	Soft       *bool
+	Sort       DrinkSort
+	Desc       bool
+	Limit      int
+	After      *DrinkCursor
+}
+
+// DrinkCursor is the position of the last drink of a page in the order it was
+// listed in. It's only valid for the same sort.
+type DrinkCursor struct {
+	Sort DrinkSort `json:"s"`
+	Desc bool      `json:"d,omitempty"`
+	ID   int       `json:"id"`
+	Name string    `json:"n,omitempty"`
+	Cost int64     `json:"c,omitempty"`
+}
+
+type DrinkPage struct {
+	Drinks     []Drink `json:"drinks"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+}
+
+// DrinkSearch selects a page of drinks whose name or category matches Query, the best
+// matches first.
+type DrinkSearch struct {
+	Query   string
+	Page    int
+	PerPage int
+}
+
+type DrinkSearchPage struct {
+	Drinks  []Drink `json:"drinks"`
+	Total   int     `json:"total"`
+	Page    int     `json:"page"`
+	PerPage int     `json:"per_page"`
+}
+
+// DrinkSuggestion is an autocomplete entry.
+type DrinkSuggestion struct {
+	ID   int    `db:"id" json:"id"`
+	Name string `db:"name" json:"name"`
+}
+
+func NewDrinkCursor(query DrinkQuery, last Drink) DrinkCursor {
+	cursor := DrinkCursor{
+		Sort: query.Sort,
+		Desc: query.Desc,
+		ID:   last.ID,
+	}
+
+	switch query.Sort {
+	case DrinkSortName:
+		cursor.Name = last.Name
+	case DrinkSortCost:
+		cursor.Cost = last.Cost.Amount
+	}
+
+	return cursor
+}
+
+// Encode returns the cursor as an opaque URL-safe string.
+func (c DrinkCursor) Encode() string {
+	b, _ := json.Marshal(c)
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// ParseDrinkCursor decodes a cursor and checks it was issued for the same order.
+func ParseDrinkCursor(s string, sort DrinkSort, desc bool) (DrinkCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return DrinkCursor{}, ErrInvalidCursor
+	}
+
+	var cursor DrinkCursor
+	if err = json.Unmarshal(b, &cursor); err != nil {
+		return DrinkCursor{}, ErrInvalidCursor
+	}
+
+	if cursor.Sort != sort || cursor.Desc != desc {
+		return DrinkCursor{}, fmt.Errorf("%w: issued for another sort order", ErrInvalidCursor)
+	}
+
+	return cursor, nil
 }
@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+type ExportStatus string
+
+const (
+	ExportPending ExportStatus = "pending"
+	ExportReady   ExportStatus = "ready"
+	ExportFailed  ExportStatus = "failed"
+)
+
+// ExportJob is a request for a personal data archive. The archive is built in the
+// background and can be downloaded with the job's token until ExpiresAt.
+type ExportJob struct {
+	ID          string       `json:"id"`
+	UserID      string       `json:"user_id"`
+	TokenHash   string       `json:"token_hash"`
+	Status      ExportStatus `json:"status"`
+	Error       string       `json:"error,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	CompletedAt time.Time    `json:"completed_at"`
+	ExpiresAt   time.Time    `json:"expires_at"`
+}
+
+// ExportInfo is the public view of an export job. DownloadURL is only known
+// right after the job is created, since just the token hash is stored.
+type ExportInfo struct {
+	ID          string       `json:"id"`
+	Status      ExportStatus `json:"status"`
+	CreatedAt   time.Time    `json:"created_at"`
+	CompletedAt *time.Time   `json:"completed_at,omitempty"`
+	ExpiresAt   time.Time    `json:"expires_at"`
+	DownloadURL string       `json:"download_url,omitempty"`
+}
+
+// ExportManifest describes the archive, it is stored in it as manifest.json.
+type ExportManifest struct {
+	UserID      string    `json:"user_id"`
+	GeneratedAt time.Time `json:"generated_at"`
+	Files       []string  `json:"files"`
+}
+
+func (j *ExportJob) Info() ExportInfo {
+	info := ExportInfo{
+		ID:        j.ID,
+		Status:    j.Status,
+		CreatedAt: j.CreatedAt,
+		ExpiresAt: j.ExpiresAt,
+	}
+
+	if !j.CompletedAt.IsZero() {
+		completedAt := j.CompletedAt
+		info.CompletedAt = &completedAt
+	}
+
+	return info
+}
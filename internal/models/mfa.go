@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// MFAChallenge is stored between the password step and the second factor step of sign in.
+type MFAChallenge struct {
+	UserID    string `json:"user_id"`
+	UserAgent string `json:"user_agent"`
+	IP        string `json:"ip"`
+}
+
+// MFARequired is returned from sign in instead of tokens when the user has 2FA enabled.
+type MFARequired struct {
+	MFAToken  string    `json:"mfa_token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// SignInResult holds either tokens or an MFA challenge. CookieMode is set for
+// single sign-on started in the browser auth mode, since the provider's redirect
+// back can't ask for it.
+type SignInResult struct {
+	Tokens      Tokens
+	MFARequired *MFARequired
+	CookieMode  bool
+}
+
+type TOTPEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
@@ -0,0 +1,146 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrUnknownCurrency = errors.New("unknown currency")
+	ErrInvalidAmount   = errors.New("invalid amount")
+)
+
+// Currency is an ISO 4217 alphabetic code.
+type Currency string
+
+// currencyExponents holds the number of minor unit digits of the supported currencies.
+var currencyExponents = map[Currency]int{
+	"AUD": 2, "BGN": 2, "BHD": 3, "BRL": 2, "BYN": 2, "CAD": 2, "CHF": 2, "CNY": 2,
+	"CZK": 2, "DKK": 2, "EUR": 2, "GBP": 2, "HKD": 2, "HUF": 2, "ILS": 2, "INR": 2,
+	"ISK": 0, "JPY": 0, "KRW": 0, "KWD": 3, "KZT": 2, "MXN": 2, "NOK": 2, "NZD": 2,
+	"OMR": 3, "PLN": 2, "RON": 2, "RUB": 2, "SEK": 2, "SGD": 2, "TRY": 2, "UAH": 2,
+	"USD": 2, "ZAR": 2,
+}
+
+func (c Currency) Valid() bool {
+	_, ok := currencyExponents[c]
+	return ok
+}
+
+// Exponent returns the number of digits after the decimal point, e.g. 2 for EUR.
+func (c Currency) Exponent() int {
+	return currencyExponents[c]
+}
+
+// Money is an exact amount in minor units of its currency, 4.50 EUR is {450, "EUR"}.
+// It never goes through floating point.
+type Money struct {
+	Amount   int64    `db:"amount"`
+	Currency Currency `db:"currency"`
+}
+
+// ParseMoney reads a decimal amount like "4.50" in the given currency. More fraction
+// digits than the currency has are an error rather than being rounded.
+func ParseMoney(amount string, currency Currency) (Money, error) {
+	if !currency.Valid() {
+		return Money{}, fmt.Errorf("%w %q", ErrUnknownCurrency, currency)
+	}
+
+	digits, negative := strings.CutPrefix(amount, "-")
+	whole, fraction, hasPoint := strings.Cut(digits, ".")
+
+	exponent := currency.Exponent()
+	if whole == "" || (hasPoint && fraction == "") || len(fraction) > exponent ||
+		!isDigits(whole) || !isDigits(fraction) {
+		return Money{}, fmt.Errorf("%w %q: must be a decimal with at most %d fraction digits for %s",
+			ErrInvalidAmount, amount, exponent, currency)
+	}
+
+	minor, err := strconv.ParseInt(whole+fraction+strings.Repeat("0", exponent-len(fraction)), 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w %q: %w", ErrInvalidAmount, amount, err)
+	}
+
+	if negative {
+		minor = -minor
+	}
+
+	return Money{Amount: minor, Currency: currency}, nil
+}
+
+// Decimal returns the amount with as many fraction digits as the currency has, e.g. "4.50".
+func (m Money) Decimal() string {
+	digits := strconv.FormatInt(m.Amount, 10)
+
+	sign := ""
+	if m.Amount < 0 {
+		sign, digits = "-", digits[1:]
+	}
+
+	exponent := m.Currency.Exponent()
+	if exponent == 0 {
+		return sign + digits
+	}
+
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+
+	return sign + digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+func (m Money) String() string {
+	return m.Decimal() + " " + string(m.Currency)
+}
+
+type moneyJSON struct {
+	Amount   json.RawMessage `json:"amount"`
+	Currency Currency        `json:"currency"`
+}
+
+// MarshalJSON renders the amount as a decimal string, so clients don't lose precision
+// parsing it as a float.
+func (m Money) MarshalJSON() ([]byte, error) {
+	amount, err := json.Marshal(m.Decimal())
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(moneyJSON{Amount: amount, Currency: m.Currency})
+}
+
+// UnmarshalJSON accepts the amount either as a decimal string or as a JSON number,
+// both are read digit by digit.
+func (m *Money) UnmarshalJSON(b []byte) error {
+	var v moneyJSON
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+
+	amount := string(v.Amount)
+	if s, err := strconv.Unquote(amount); err == nil {
+		amount = s
+	}
+
+	money, err := ParseMoney(amount, v.Currency)
+	if err != nil {
+		return err
+	}
+
+	*m = money
+
+	return nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
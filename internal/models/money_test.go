@@ -0,0 +1,111 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+)
+
+func TestParseMoney(t *testing.T) {
+	testTable := []struct {
+		name          string
+		amount        string
+		currency      Currency
+		expectedMoney Money
+		expectedError error
+	}{
+		{
+			name:          "cents",
+			amount:        "4.50",
+			currency:      "EUR",
+			expectedMoney: Money{Amount: 450, Currency: "EUR"},
+		},
+		{
+			name:          "fewer fraction digits",
+			amount:        "4.5",
+			currency:      "EUR",
+			expectedMoney: Money{Amount: 450, Currency: "EUR"},
+		},
+		{
+			name:          "whole units",
+			amount:        "12",
+			currency:      "USD",
+			expectedMoney: Money{Amount: 1200, Currency: "USD"},
+		},
+		{
+			name:          "no minor units",
+			amount:        "500",
+			currency:      "JPY",
+			expectedMoney: Money{Amount: 500, Currency: "JPY"},
+		},
+		{
+			name:          "three fraction digits",
+			amount:        "-1.005",
+			currency:      "KWD",
+			expectedMoney: Money{Amount: -1005, Currency: "KWD"},
+		},
+		{
+			name:          "too precise",
+			amount:        "4.505",
+			currency:      "EUR",
+			expectedError: ErrInvalidAmount,
+		},
+		{
+			name:          "exponent notation",
+			amount:        "4e2",
+			currency:      "EUR",
+			expectedError: ErrInvalidAmount,
+		},
+		{
+			name:          "trailing point",
+			amount:        "4.",
+			currency:      "EUR",
+			expectedError: ErrInvalidAmount,
+		},
+		{
+			name:          "unknown currency",
+			amount:        "4.50",
+			currency:      "eur",
+			expectedError: ErrUnknownCurrency,
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			money, err := ParseMoney(tc.amount, tc.currency)
+
+			assert.Equal(t, tc.expectedMoney, money)
+			assert.Equal(t, true, errors.Is(err, tc.expectedError))
+		})
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	testTable := []struct {
+		money    Money
+		expected string
+	}{
+		{money: Money{Amount: 450, Currency: "EUR"}, expected: `{"amount":"4.50","currency":"EUR"}`},
+		{money: Money{Amount: 5, Currency: "USD"}, expected: `{"amount":"0.05","currency":"USD"}`},
+		{money: Money{Amount: -1005, Currency: "KWD"}, expected: `{"amount":"-1.005","currency":"KWD"}`},
+		{money: Money{Amount: 500, Currency: "JPY"}, expected: `{"amount":"500","currency":"JPY"}`},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.expected, func(t *testing.T) {
+			b, err := json.Marshal(tc.money)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, tc.expected, string(b))
+
+			var money Money
+			assert.Equal(t, nil, json.Unmarshal(b, &money))
+			assert.Equal(t, tc.money, money)
+		})
+	}
+
+	var money Money
+	assert.Equal(t, nil, json.Unmarshal([]byte(`{"amount":4.5,"currency":"EUR"}`), &money))
+	assert.Equal(t, Money{Amount: 450, Currency: "EUR"}, money)
+}
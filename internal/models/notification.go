@@ -0,0 +1,6 @@
+package models
+
+type Notification struct {
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
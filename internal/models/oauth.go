@@ -0,0 +1,140 @@
+package models
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	GrantAuthorizationCode = "authorization_code"
+	GrantClientCredentials = "client_credentials"
+	GrantRefreshToken      = "refresh_token"
+
+	TokenTypeAccess  = "access_token"
+	TokenTypeRefresh = "refresh_token"
+)
+
+// OAuthClient is a partner application registered by an admin. Public clients have
+// no secret and may only use the authorization code flow with PKCE.
+type OAuthClient struct {
+	ID           string    `db:"id"`
+	Name         string    `db:"name"`
+	SecretHash   string    `db:"secret_hash"`
+	RedirectURIs string    `db:"redirect_uris"`
+	GrantTypes   string    `db:"grant_types"`
+	Scopes       string    `db:"scopes"`
+	CreatedAt    time.Time `db:"created_at"`
+}
+
+type OAuthClientInfo struct {
+	ID           string       `json:"client_id"`
+	Name         string       `json:"name"`
+	Public       bool         `json:"public"`
+	RedirectURIs []string     `json:"redirect_uris"`
+	GrantTypes   []string     `json:"grant_types"`
+	Scopes       []Permission `json:"scopes"`
+	CreatedAt    time.Time    `json:"created_at"`
+}
+
+// RegisteredOAuthClient is returned once, when the client is registered: the secret
+// can't be recovered later.
+type RegisteredOAuthClient struct {
+	OAuthClientInfo
+	Secret string `json:"client_secret,omitempty"`
+}
+
+// AuthorizationRequest is what a client asks the user to consent to.
+type AuthorizationRequest struct {
+	ClientID            string
+	RedirectURI         string
+	Scopes              []Permission
+	State               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+}
+
+// Consent describes an authorization request for the consent screen.
+type Consent struct {
+	ClientID    string       `json:"client_id"`
+	ClientName  string       `json:"client_name"`
+	Scopes      []Permission `json:"scopes"`
+	RedirectURI string       `json:"redirect_uri"`
+	State       string       `json:"state,omitempty"`
+}
+
+// AuthorizationCode is stored until the client exchanges the code for tokens.
+// RedirectURISent tells whether the authorization request carried the redirect
+// URI, only then the token request has to repeat it.
+type AuthorizationCode struct {
+	ClientID        string       `json:"client_id"`
+	UserID          string       `json:"user_id"`
+	RedirectURI     string       `json:"redirect_uri"`
+	RedirectURISent bool         `json:"redirect_uri_sent,omitempty"`
+	Scopes          []Permission `json:"scopes"`
+	CodeChallenge   string       `json:"code_challenge"`
+}
+
+// TokenRequest is a request to the token endpoint, the fields used depend on GrantType.
+type TokenRequest struct {
+	GrantType    string
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+	RefreshToken string
+	Scopes       []Permission
+	ClientID     string
+	ClientSecret string
+}
+
+// OAuthToken is the token endpoint response as defined by RFC 6749.
+type OAuthToken struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+// Introspection is the token introspection response as defined by RFC 7662.
+type Introspection struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Subject   string `json:"sub,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+	TokenID   string `json:"jti,omitempty"`
+}
+
+func (c *OAuthClient) Public() bool {
+	return c.SecretHash == ""
+}
+
+func (c *OAuthClient) RedirectURIList() []string {
+	return strings.Fields(c.RedirectURIs)
+}
+
+func (c *OAuthClient) GrantTypeList() []string {
+	return strings.Fields(c.GrantTypes)
+}
+
+func (c *OAuthClient) ScopeList() []Permission {
+	return ParseScopes(c.Scopes)
+}
+
+func (c *OAuthClient) AllowsGrant(grantType string) bool {
+	return slices.Contains(c.GrantTypeList(), grantType)
+}
+
+func (c *OAuthClient) Info() OAuthClientInfo {
+	return OAuthClientInfo{
+		ID:           c.ID,
+		Name:         c.Name,
+		Public:       c.Public(),
+		RedirectURIs: c.RedirectURIList(),
+		GrantTypes:   c.GrantTypeList(),
+		Scopes:       c.ScopeList(),
+		CreatedAt:    c.CreatedAt,
+	}
+}
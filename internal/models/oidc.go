@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Identity links an account at an OpenID Connect provider, known by its subject,
+// to a user. A user can have one identity per provider.
+type Identity struct {
+	Provider  string    `db:"provider"`
+	Subject   string    `db:"subject"`
+	UserID    string    `db:"user_id"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+type IdentityInfo struct {
+	Provider string    `json:"provider"`
+	Subject  string    `json:"subject"`
+	LinkedAt time.Time `json:"linked_at"`
+}
+
+func (i *Identity) Info() IdentityInfo {
+	return IdentityInfo{
+		Provider: i.Provider,
+		Subject:  i.Subject,
+		LinkedAt: i.CreatedAt,
+	}
+}
+
+// OIDCLogin is a login started at a provider and waiting for its callback.
+// UserID is set when a signed in user links the provider's identity to their account.
+// BindingHash ties the login to the browser that started it, CookieMode is the auth
+// mode it asked for.
+type OIDCLogin struct {
+	Provider     string `json:"provider"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+	UserID       string `json:"user_id,omitempty"`
+	BindingHash  string `json:"binding_hash"`
+	CookieMode   bool   `json:"cookie_mode,omitempty"`
+}
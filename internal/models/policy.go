@@ -0,0 +1,144 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// AgeCategory groups drinks that share a legal minimum age.
+type AgeCategory string
+
+const (
+	AgeCategorySoft     AgeCategory = "soft"
+	AgeCategoryBeerWine AgeCategory = "beer_wine"
+	AgeCategorySpirits  AgeCategory = "spirits"
+)
+
+var AgeCategories = []AgeCategory{
+	AgeCategorySoft,
+	AgeCategoryBeerWine,
+	AgeCategorySpirits,
+}
+
+func (c AgeCategory) Valid() bool {
+	for _, category := range AgeCategories {
+		if c == category {
+			return true
+		}
+	}
+
+	return false
+}
+
+// JurisdictionPolicy holds minimum ages per drink category in one region.
+// Categories missing from MinAges have no age limit. NewAgePolicies fills them in
+// from the default region, so only soft drinks can be missing.
+type JurisdictionPolicy struct {
+	Region  string
+	MinAges map[AgeCategory]int
+}
+
+// AdultSince returns from when a person born on birthDate may be served each category.
+// Categories without an age limit are allowed from the start of Unix time, those with
+// one are left out when the birth date is unknown.
+func (p JurisdictionPolicy) AdultSince(birthDate time.Time) map[AgeCategory]time.Time {
+	adultSince := make(map[AgeCategory]time.Time, len(AgeCategories))
+
+	for _, category := range AgeCategories {
+		switch minAge := p.MinAges[category]; {
+		case minAge == 0:
+			adultSince[category] = time.Unix(0, 0).UTC()
+		case !birthDate.IsZero():
+			adultSince[category] = birthDate.AddDate(minAge, 0, 0)
+		}
+	}
+
+	return adultSince
+}
+
+// AgePolicies maps regions to their policies. Users without a region, or with a
+// region that has no policy of its own, fall under the default region.
+type AgePolicies struct {
+	DefaultRegion string
+	policies      map[string]JurisdictionPolicy
+}
+
+// NewAgePolicies builds policies from minimum ages keyed by region and category name.
+// The default region must be among them and set an age for every alcoholic category,
+// other regions take the ages they don't set from it.
+func NewAgePolicies(defaultRegion string, minAges map[string]map[string]int) (AgePolicies, error) {
+	policies := AgePolicies{
+		DefaultRegion: defaultRegion,
+		policies:      make(map[string]JurisdictionPolicy, len(minAges)),
+	}
+
+	for region, ages := range minAges {
+		policy := JurisdictionPolicy{
+			Region:  region,
+			MinAges: make(map[AgeCategory]int, len(ages)),
+		}
+
+		for name, age := range ages {
+			category := AgeCategory(name)
+			if !category.Valid() {
+				return AgePolicies{}, fmt.Errorf("unknown age category %q in region %s", name, region)
+			}
+
+			if age < 0 {
+				return AgePolicies{}, fmt.Errorf("invalid minimum age %d for %s in region %s", age, name, region)
+			}
+
+			policy.MinAges[category] = age
+		}
+
+		policies.policies[region] = policy
+	}
+
+	defaultPolicy, ok := policies.policies[defaultRegion]
+	if !ok {
+		return AgePolicies{}, fmt.Errorf("no policy for default region %s", defaultRegion)
+	}
+
+	for _, category := range AgeCategories {
+		if _, ok = defaultPolicy.MinAges[category]; !ok && category != AgeCategorySoft {
+			return AgePolicies{}, fmt.Errorf("no minimum age for %s in default region %s", category, defaultRegion)
+		}
+	}
+
+	for _, policy := range policies.policies {
+		for category, age := range defaultPolicy.MinAges {
+			if _, ok = policy.MinAges[category]; !ok {
+				policy.MinAges[category] = age
+			}
+		}
+	}
+
+	return policies, nil
+}
+
+func (p AgePolicies) For(region string) JurisdictionPolicy {
+	if policy, ok := p.policies[region]; ok {
+		return policy
+	}
+
+	return p.policies[p.DefaultRegion]
+}
+
+// Customer is the person drinks are listed for. AdultSince comes from the access
+// token, so the birth date itself never leaves the server.
+type Customer struct {
+	AdultSince map[AgeCategory]time.Time
+}
+
+// AllowedCategories returns the categories the customer may be served at t.
+func (c Customer) AllowedCategories(t time.Time) []AgeCategory {
+	categories := make([]AgeCategory, 0, len(AgeCategories))
+
+	for _, category := range AgeCategories {
+		if since, ok := c.AdultSince[category]; ok && !t.Before(since) {
+			categories = append(categories, category)
+		}
+	}
+
+	return categories
+}
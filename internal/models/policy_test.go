@@ -0,0 +1,98 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+)
+
+func TestAllowedCategories(t *testing.T) {
+	policies, err := NewAgePolicies("DEFAULT", map[string]map[string]int{
+		"DEFAULT": {"beer_wine": 18, "spirits": 18},
+		"DE":      {"beer_wine": 16, "spirits": 18},
+		"US":      {"beer_wine": 21, "spirits": 21},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name               string
+		birthDate          time.Time
+		region             string
+		expectedCategories []AgeCategory
+	}{
+		{
+			name:               "16 in DE",
+			birthDate:          time.Date(2010, time.October, 18, 0, 0, 0, 0, time.UTC),
+			region:             "DE",
+			expectedCategories: []AgeCategory{AgeCategorySoft, AgeCategoryBeerWine},
+		},
+		{
+			name:               "one day before 16 in DE",
+			birthDate:          time.Date(2010, time.October, 19, 0, 0, 0, 0, time.UTC),
+			region:             "DE",
+			expectedCategories: []AgeCategory{AgeCategorySoft},
+		},
+		{
+			name:               "20 in US",
+			birthDate:          time.Date(2006, time.January, 1, 0, 0, 0, 0, time.UTC),
+			region:             "US",
+			expectedCategories: []AgeCategory{AgeCategorySoft},
+		},
+		{
+			name:               "unknown region falls back to default",
+			birthDate:          time.Date(2006, time.January, 1, 0, 0, 0, 0, time.UTC),
+			region:             "FR",
+			expectedCategories: []AgeCategory{AgeCategorySoft, AgeCategoryBeerWine, AgeCategorySpirits},
+		},
+		{
+			name:               "unknown birth date",
+			region:             "DE",
+			expectedCategories: []AgeCategory{AgeCategorySoft},
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			customer := Customer{AdultSince: policies.For(tc.region).AdultSince(tc.birthDate)}
+			assert.Equal(t, tc.expectedCategories, customer.AllowedCategories(now))
+		})
+	}
+}
+
+func TestNewAgePolicies(t *testing.T) {
+	_, err := NewAgePolicies("DEFAULT", map[string]map[string]int{
+		"DEFAULT": {"cocktails": 18},
+	})
+	assert.NotEqual(t, nil, err)
+
+	_, err = NewAgePolicies("DEFAULT", map[string]map[string]int{
+		"DE": {"beer_wine": 16},
+	})
+	assert.NotEqual(t, nil, err)
+
+	_, err = NewAgePolicies("DEFAULT", map[string]map[string]int{
+		"DEFAULT": {"beer_wine": 18},
+	})
+	assert.NotEqual(t, nil, err)
+}
+
+func TestAgePoliciesInheritDefault(t *testing.T) {
+	policies, err := NewAgePolicies("DEFAULT", map[string]map[string]int{
+		"DEFAULT": {"beer_wine": 18, "spirits": 21},
+		"DE":      {"beer_wine": 16},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	birthDate := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	adultSince := policies.For("DE").AdultSince(birthDate)
+
+	assert.Equal(t, birthDate.AddDate(16, 0, 0), adultSince[AgeCategoryBeerWine])
+	assert.Equal(t, birthDate.AddDate(21, 0, 0), adultSince[AgeCategorySpirits])
+}
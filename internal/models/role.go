@@ -0,0 +1,63 @@
+package models
+
+import (
+	"slices"
+	"strings"
+)
+
+const (
+	RoleUser      = "user"
+	RoleBartender = "bartender"
+	RoleManager   = "manager"
+	RoleAuditor   = "auditor"
+	RoleAdmin     = "admin"
+)
+
+// Permission names an action as resource:action, e.g. drinks:create.
+type Permission string
+
+const (
+	PermDrinksCreate       Permission = "drinks:create"
+	PermDrinksUpdate       Permission = "drinks:update"
+	PermDrinksDelete       Permission = "drinks:delete"
+	PermCategoriesManage   Permission = "categories:manage"
+	PermUsersUnlock        Permission = "users:unlock"
+	PermUsersRead          Permission = "users:read"
+	PermUsersManage        Permission = "users:manage"
+	PermAPIKeysManage      Permission = "api_keys:manage"
+	PermOAuthClientsManage Permission = "oauth_clients:manage"
+)
+
+// Access is what a user may do: the roles assigned to them and the union
+// of the permissions those roles grant.
+type Access struct {
+	Roles       []string
+	Permissions []Permission
+}
+
+func (a Access) HasPermission(permission Permission) bool {
+	return slices.Contains(a.Permissions, permission)
+}
+
+// ParseScopes splits a space separated list of permissions, the form scopes are
+// stored and passed in.
+func ParseScopes(s string) []Permission {
+	fields := strings.Fields(s)
+
+	scopes := make([]Permission, len(fields))
+	for i := range fields {
+		scopes[i] = Permission(fields[i])
+	}
+
+	return scopes
+}
+
+// JoinScopes is the inverse of ParseScopes.
+func JoinScopes(scopes []Permission) string {
+	names := make([]string, len(scopes))
+	for i := range scopes {
+		names[i] = string(scopes[i])
+	}
+
+	return strings.Join(names, " ")
+}
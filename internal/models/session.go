@@ -5,11 +5,50 @@ import (
 	"time"
 )
 
+// Session is a refresh token family: its ID stays the same across rotations,
+// while TokenID and ParentTokenID track the chain of issued refresh tokens.
+// Sessions of OAuth clients carry the client and the scopes the user consented to.
 type Session struct {
-	ID           string    `json:"id"`
-	UserID       string    `json:"user_id"`
-	RefreshToken string    `json:"refresh_token"`
-	ExpiresAt    time.Time `json:"expires_at"`
+	ID            string       `json:"id"`
+	UserID        string       `json:"user_id"`
+	RefreshToken  string       `json:"refresh_token"`
+	TokenID       string       `json:"token_id"`
+	ParentTokenID string       `json:"parent_token_id"`
+	MFA           bool         `json:"mfa"`
+	ClientID      string       `json:"client_id,omitempty"`
+	Scopes        []Permission `json:"scopes,omitempty"`
+	UserAgent     string       `json:"user_agent"`
+	IP            string       `json:"ip"`
+	CreatedAt     time.Time    `json:"created_at"`
+	RefreshedAt   time.Time    `json:"refreshed_at"`
+	ExpiresAt     time.Time    `json:"expires_at"`
+}
+
+// UsedRefreshToken records a refresh token that was already rotated,
+// presenting it again means the token family is compromised.
+type UsedRefreshToken struct {
+	TokenID   string    `json:"token_id"`
+	SessionID string    `json:"session_id"`
+	UserID    string    `json:"user_id"`
+	UsedAt    time.Time `json:"used_at"`
+}
+
+// SessionInfo is the public view of a session, without the refresh token hash.
+type SessionInfo struct {
+	ID          string    `json:"id"`
+	UserAgent   string    `json:"user_agent"`
+	IP          string    `json:"ip"`
+	CreatedAt   time.Time `json:"created_at"`
+	RefreshedAt time.Time `json:"refreshed_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	ClientID    string    `json:"client_id,omitempty"`
+	Current     bool      `json:"current"`
+}
+
+// ClientInfo describes the device a session was created or refreshed from.
+type ClientInfo struct {
+	UserAgent string
+	IP        string
 }
 
 type Tokens struct {
@@ -17,6 +56,19 @@ type Tokens struct {
 	RefreshToken string `json:"refresh_token"`
 }
 
+func (s *Session) Info(currentSessionID string) SessionInfo {
+	return SessionInfo{
+		ID:          s.ID,
+		UserAgent:   s.UserAgent,
+		IP:          s.IP,
+		CreatedAt:   s.CreatedAt,
+		RefreshedAt: s.RefreshedAt,
+		ExpiresAt:   s.ExpiresAt,
+		ClientID:    s.ClientID,
+		Current:     s.ID == currentSessionID,
+	}
+}
+
 func MarshalSession(s Session) ([]byte, error) {
 	return json.Marshal(s)
 }
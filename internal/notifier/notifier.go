@@ -0,0 +1,72 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/HeadGardener/coursework/internal/models"
+)
+
+// LogNotifier writes notifications to the application log, it is meant for local use only.
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (n *LogNotifier) Send(_ context.Context, user *models.User, notification models.Notification) error {
+	log.Printf("[INFO] notification for %s: %s: %s", user.Username, notification.Subject, notification.Body)
+
+	return nil
+}
+
+// FileNotifier appends notifications as JSON lines to a file.
+type FileNotifier struct {
+	mu   sync.Mutex
+	path string
+}
+
+type fileRecord struct {
+	UserID   string    `json:"user_id"`
+	Username string    `json:"username"`
+	Subject  string    `json:"subject"`
+	Body     string    `json:"body"`
+	SentAt   time.Time `json:"sent_at"`
+}
+
+func NewFileNotifier(path string) *FileNotifier {
+	return &FileNotifier{path: path}
+}
+
+func (n *FileNotifier) Send(_ context.Context, user *models.User, notification models.Notification) error {
+	b, err := json.Marshal(fileRecord{
+		UserID:   user.ID,
+		Username: user.Username,
+		Subject:  notification.Subject,
+		Body:     notification.Body,
+		SentAt:   time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	f, err := os.OpenFile(n.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open notifications file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err = f.Write(append(b, '\n')); err != nil {
+		return fmt.Errorf("failed to write notification: %w", err)
+	}
+
+	return nil
+}
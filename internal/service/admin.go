@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/HeadGardener/coursework/internal/models"
+)
+
+var (
+	ErrUserNotFound  = errors.New("user not found")
+	ErrUserDisabled  = errors.New("user is disabled")
+	ErrUnknownRole   = errors.New("unknown role")
+	ErrDisablingSelf = errors.New("can't disable own account")
+)
+
+func (s *AuthService) ListUsers(ctx context.Context, filter models.UserFilter) (models.UserPage, error) {
+	users, total, err := s.userStorage.List(ctx, filter)
+	if err != nil {
+		return models.UserPage{}, err
+	}
+
+	userIDs := make([]string, len(users))
+	for i := range users {
+		userIDs[i] = users[i].ID
+	}
+
+	roles, err := s.userStorage.GetRoles(ctx, userIDs)
+	if err != nil {
+		return models.UserPage{}, err
+	}
+
+	page := models.UserPage{
+		Users:   make([]models.UserInfo, 0, len(users)),
+		Total:   total,
+		Page:    filter.Page,
+		PerPage: filter.PerPage,
+	}
+
+	for i := range users {
+		page.Users = append(page.Users, users[i].Info(roles[users[i].ID]))
+	}
+
+	return page, nil
+}
+
+func (s *AuthService) GetUser(ctx context.Context, userID string) (models.UserInfo, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return models.UserInfo{}, err
+	}
+
+	access, err := s.userStorage.GetAccess(ctx, userID)
+	if err != nil {
+		return models.UserInfo{}, err
+	}
+
+	return user.Info(access.Roles), nil
+}
+
+// SetUserRoles replaces the roles of the user and logs them out everywhere, so tokens
+// carrying the old permissions stop working at once.
+func (s *AuthService) SetUserRoles(ctx context.Context, userID string, roles []string) error {
+	known, err := s.userStorage.ListRoles(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, role := range roles {
+		if !slices.Contains(known, role) {
+			return fmt.Errorf("%w: %s", ErrUnknownRole, role)
+		}
+	}
+
+	if _, err = s.getUser(ctx, userID); err != nil {
+		return err
+	}
+
+	if err = s.userStorage.SetRoles(ctx, userID, roles); err != nil {
+		return err
+	}
+
+	return s.revokeSessions(ctx, userID, "")
+}
+
+// SetUserDisabled disables or enables the account of userID on behalf of actorID.
+// Disabling also logs the user out everywhere.
+func (s *AuthService) SetUserDisabled(ctx context.Context, actorID, userID string, disabled bool) error {
+	if disabled && actorID == userID {
+		return ErrDisablingSelf
+	}
+
+	if err := s.userStorage.SetDisabled(ctx, userID, disabled); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUserNotFound
+		}
+
+		return err
+	}
+
+	if !disabled {
+		return nil
+	}
+
+	return s.revokeSessions(ctx, userID, "")
+}
+
+// ForceLogout revokes every session of the user.
+func (s *AuthService) ForceLogout(ctx context.Context, userID string) error {
+	if _, err := s.getUser(ctx, userID); err != nil {
+		return err
+	}
+
+	return s.revokeSessions(ctx, userID, "")
+}
+
+// getUser returns the user with the given ID, ErrUserNotFound if there is none.
+func (s *AuthService) getUser(ctx context.Context, userID string) (*models.User, error) {
+	user, err := s.userStorage.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+
+		return nil, err
+	}
+
+	return user, nil
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/HeadGardener/coursework/internal/models"
+	mock_service "github.com/HeadGardener/coursework/internal/service/mocks"
+	"github.com/go-playground/assert/v2"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSetUserRoles(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	userStorage := mock_service.NewMockUserStorage(c)
+	sessionStorage := mock_service.NewMockSessionStorage(c)
+	denylist := mock_service.NewMockDenylist(c)
+
+	userStorage.EXPECT().ListRoles(gomock.Any()).Return([]string{models.RoleAdmin, models.RoleUser}, nil)
+	userStorage.EXPECT().GetByID(gomock.Any(), "user").Return(&models.User{ID: "user"}, nil)
+	userStorage.EXPECT().SetRoles(gomock.Any(), "user", []string{models.RoleUser}).Return(nil)
+	sessionStorage.EXPECT().GetAllByUser(gomock.Any(), "user").Return([]models.Session{{ID: "phone"}, {ID: "laptop"}}, nil)
+
+	for _, sessionID := range []string{"phone", "laptop"} {
+		denylist.EXPECT().Deny(gomock.Any(), sessionID, time.Minute).Return(nil)
+		sessionStorage.EXPECT().Delete(gomock.Any(), "user", sessionID).Return(nil)
+	}
+
+	service := NewAuthService(newTestTokenManager(t), sessionStorage, userStorage, denylist,
+		nil, nil, nil, nil, nil, nil, models.AgePolicies{})
+
+	err := service.SetUserRoles(context.Background(), "user", []string{models.RoleUser})
+	assert.Equal(t, nil, err)
+}
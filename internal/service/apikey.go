@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/HeadGardener/coursework/internal/lib/auth"
+	"github.com/HeadGardener/coursework/internal/lib/hash"
+	"github.com/HeadGardener/coursework/internal/models"
+	"github.com/google/uuid"
+)
+
+const (
+	apiKeyPrefixLen = 4
+	apiKeySecretLen = 32
+	apiKeySeparator = "."
+)
+
+var (
+	ErrAPIKeyNotFound  = errors.New("api key not found or already revoked")
+	ErrInvalidAPIKey   = errors.New("invalid api key")
+	ErrAPIKeyExpired   = errors.New("api key has expired")
+	ErrAPIKeyRevoked   = errors.New("api key has been revoked")
+	ErrScopeNotGranted = errors.New("scope is not granted to the user")
+)
+
+type APIKeyStorage interface {
+	Create(ctx context.Context, key *models.APIKey) error
+	GetByPrefix(ctx context.Context, prefix string) (models.APIKey, error)
+	List(ctx context.Context, userID string) ([]models.APIKey, error)
+	Revoke(ctx context.Context, keyID string) error
+	TouchLastUsed(ctx context.Context, keyID string) error
+}
+
+type APIKeyService struct {
+	userStorage   UserStorage
+	apiKeyStorage APIKeyStorage
+	agePolicies   models.AgePolicies
+}
+
+func NewAPIKeyService(userStorage UserStorage, apiKeyStorage APIKeyStorage,
+	agePolicies models.AgePolicies) *APIKeyService {
+	return &APIKeyService{
+		userStorage:   userStorage,
+		apiKeyStorage: apiKeyStorage,
+		agePolicies:   agePolicies,
+	}
+}
+
+// Create mints a key acting as userID and limited to scopes, each of which the user
+// must be granted. The returned key is the only copy of it.
+func (s *APIKeyService) Create(ctx context.Context, userID, name string, scopes []models.Permission,
+	expiresAt time.Time) (models.CreatedAPIKey, error) {
+	if _, err := s.userStorage.GetByID(ctx, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.CreatedAPIKey{}, ErrUserNotFound
+		}
+
+		return models.CreatedAPIKey{}, err
+	}
+
+	access, err := s.userStorage.GetAccess(ctx, userID)
+	if err != nil {
+		return models.CreatedAPIKey{}, err
+	}
+
+	for _, scope := range scopes {
+		if !access.HasPermission(scope) {
+			return models.CreatedAPIKey{}, fmt.Errorf("%w: %s", ErrScopeNotGranted, scope)
+		}
+	}
+
+	prefix, err := generatePrefix()
+	if err != nil {
+		return models.CreatedAPIKey{}, err
+	}
+
+	secret, err := auth.GenerateRandomToken(apiKeySecretLen)
+	if err != nil {
+		return models.CreatedAPIKey{}, err
+	}
+
+	plain := prefix + apiKeySeparator + secret
+
+	key := models.APIKey{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   hash.GetTokenHash(plain),
+		Scopes:    models.JoinScopes(scopes),
+		ExpiresAt: expiresAt,
+	}
+
+	if err = s.apiKeyStorage.Create(ctx, &key); err != nil {
+		return models.CreatedAPIKey{}, err
+	}
+
+	return models.CreatedAPIKey{
+		APIKeyInfo: key.Info(),
+		Key:        plain,
+	}, nil
+}
+
+// List returns keys of the user, or all keys if userID is empty.
+func (s *APIKeyService) List(ctx context.Context, userID string) ([]models.APIKeyInfo, error) {
+	keys, err := s.apiKeyStorage.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]models.APIKeyInfo, 0, len(keys))
+	for i := range keys {
+		infos = append(infos, keys[i].Info())
+	}
+
+	return infos, nil
+}
+
+func (s *APIKeyService) Revoke(ctx context.Context, keyID string) error {
+	err := s.apiKeyStorage.Revoke(ctx, keyID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrAPIKeyNotFound
+	}
+
+	return err
+}
+
+// Authenticate checks the key and returns the attributes of the user it acts as,
+// the same ones an access token would carry. Permissions are cut down to the key's
+// scopes, and to what the user is still granted.
+func (s *APIKeyService) Authenticate(ctx context.Context, plain string) (auth.UserAttributes, error) {
+	prefix, _, ok := strings.Cut(plain, apiKeySeparator)
+	if !ok || prefix == "" {
+		return auth.UserAttributes{}, ErrInvalidAPIKey
+	}
+
+	key, err := s.apiKeyStorage.GetByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return auth.UserAttributes{}, ErrInvalidAPIKey
+		}
+
+		return auth.UserAttributes{}, err
+	}
+
+	if !hash.CompareTokenHash(key.KeyHash, plain) {
+		return auth.UserAttributes{}, ErrInvalidAPIKey
+	}
+
+	if key.RevokedAt != nil {
+		return auth.UserAttributes{}, ErrAPIKeyRevoked
+	}
+
+	if time.Now().After(key.ExpiresAt) {
+		return auth.UserAttributes{}, ErrAPIKeyExpired
+	}
+
+	user, err := s.userStorage.GetByID(ctx, key.UserID)
+	if err != nil {
+		return auth.UserAttributes{}, err
+	}
+
+	if user.Disabled {
+		return auth.UserAttributes{}, ErrUserDisabled
+	}
+
+	access, err := s.userStorage.GetAccess(ctx, user.ID)
+	if err != nil {
+		return auth.UserAttributes{}, err
+	}
+
+	var permissions []models.Permission
+	for _, scope := range key.ScopeList() {
+		if slices.Contains(access.Permissions, scope) {
+			permissions = append(permissions, scope)
+		}
+	}
+
+	// a failure here must not reject an otherwise valid key
+	if err = s.apiKeyStorage.TouchLastUsed(ctx, key.ID); err != nil {
+		log.Printf("[WARN] failed to update last use of api key %s: %s", key.ID, err.Error())
+	}
+
+	return auth.UserAttributes{
+		ID:          user.ID,
+		TokenID:     key.ID,
+		Roles:       access.Roles,
+		Permissions: permissions,
+		AdultSince:  s.agePolicies.For(user.Region).AdultSince(user.BirthDate),
+		ExpiresAt:   key.ExpiresAt,
+		APIKey:      true,
+	}, nil
+}
+
+func generatePrefix() (string, error) {
+	b := make([]byte, apiKeyPrefixLen)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
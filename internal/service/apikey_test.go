@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/HeadGardener/coursework/internal/lib/auth"
+	"github.com/HeadGardener/coursework/internal/lib/hash"
+	"github.com/HeadGardener/coursework/internal/models"
+	mock_service "github.com/HeadGardener/coursework/internal/service/mocks"
+	"github.com/go-playground/assert/v2"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	type mockBehavior func(k *mock_service.MockAPIKeyStorage, u *mock_service.MockUserStorage, key models.APIKey)
+
+	const plain = "0a1b2c3d.secret"
+
+	agePolicies, err := models.NewAgePolicies("DEFAULT", map[string]map[string]int{
+		"DEFAULT": {"beer_wine": 18, "spirits": 18},
+		"DE":      {"beer_wine": 16},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := &models.User{
+		ID:        "user",
+		BirthDate: time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC),
+		Region:    "DE",
+	}
+
+	key := models.APIKey{
+		ID:        "key",
+		UserID:    user.ID,
+		Prefix:    "0a1b2c3d",
+		KeyHash:   hash.GetTokenHash(plain),
+		Scopes:    "drinks:create drinks:delete",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	testTable := []struct {
+		name             string
+		key              string
+		mockBehavior     mockBehavior
+		expectedUserAttr auth.UserAttributes
+		expectedError    error
+	}{
+		{
+			name: "ok",
+			key:  plain,
+			mockBehavior: func(k *mock_service.MockAPIKeyStorage, u *mock_service.MockUserStorage, key models.APIKey) {
+				k.EXPECT().GetByPrefix(gomock.Any(), key.Prefix).Return(key, nil)
+				u.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil)
+				u.EXPECT().GetAccess(gomock.Any(), user.ID).Return(models.Access{
+					Roles:       []string{models.RoleManager},
+					Permissions: []models.Permission{models.PermDrinksCreate, models.PermDrinksUpdate},
+				}, nil)
+				k.EXPECT().TouchLastUsed(gomock.Any(), key.ID).Return(nil)
+			},
+			expectedUserAttr: auth.UserAttributes{
+				ID:          user.ID,
+				TokenID:     key.ID,
+				Roles:       []string{models.RoleManager},
+				Permissions: []models.Permission{models.PermDrinksCreate},
+				AdultSince: map[models.AgeCategory]time.Time{
+					models.AgeCategorySoft:     time.Unix(0, 0).UTC(),
+					models.AgeCategoryBeerWine: time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC),
+					models.AgeCategorySpirits:  time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC),
+				},
+				ExpiresAt: key.ExpiresAt,
+				APIKey:    true,
+			},
+		},
+		{
+			name: "unknown prefix",
+			key:  "ffffffff.secret",
+			mockBehavior: func(k *mock_service.MockAPIKeyStorage, u *mock_service.MockUserStorage, key models.APIKey) {
+				k.EXPECT().GetByPrefix(gomock.Any(), "ffffffff").Return(models.APIKey{}, sql.ErrNoRows)
+			},
+			expectedError: ErrInvalidAPIKey,
+		},
+		{
+			name: "wrong secret",
+			key:  "0a1b2c3d.wrong",
+			mockBehavior: func(k *mock_service.MockAPIKeyStorage, u *mock_service.MockUserStorage, key models.APIKey) {
+				k.EXPECT().GetByPrefix(gomock.Any(), key.Prefix).Return(key, nil)
+			},
+			expectedError: ErrInvalidAPIKey,
+		},
+		{
+			name:          "malformed",
+			key:           "secret",
+			mockBehavior:  func(k *mock_service.MockAPIKeyStorage, u *mock_service.MockUserStorage, key models.APIKey) {},
+			expectedError: ErrInvalidAPIKey,
+		},
+		{
+			name: "revoked",
+			key:  plain,
+			mockBehavior: func(k *mock_service.MockAPIKeyStorage, u *mock_service.MockUserStorage, key models.APIKey) {
+				revokedAt := time.Now()
+				key.RevokedAt = &revokedAt
+				k.EXPECT().GetByPrefix(gomock.Any(), key.Prefix).Return(key, nil)
+			},
+			expectedError: ErrAPIKeyRevoked,
+		},
+		{
+			name: "expired",
+			key:  plain,
+			mockBehavior: func(k *mock_service.MockAPIKeyStorage, u *mock_service.MockUserStorage, key models.APIKey) {
+				key.ExpiresAt = time.Now().Add(-time.Minute)
+				k.EXPECT().GetByPrefix(gomock.Any(), key.Prefix).Return(key, nil)
+			},
+			expectedError: ErrAPIKeyExpired,
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			apiKeyStorage := mock_service.NewMockAPIKeyStorage(c)
+			userStorage := mock_service.NewMockUserStorage(c)
+			tc.mockBehavior(apiKeyStorage, userStorage, key)
+
+			service := NewAPIKeyService(userStorage, apiKeyStorage, agePolicies)
+
+			userAttr, err := service.Authenticate(context.Background(), tc.key)
+
+			assert.Equal(t, tc.expectedError, err)
+			assert.Equal(t, tc.expectedUserAttr, userAttr)
+		})
+	}
+}
@@ -2,11 +2,14 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"log"
+	"sort"
 	"time"
 
 	"github.com/HeadGardener/coursework/internal/lib/auth"
-
+	"github.com/HeadGardener/coursework/internal/lib/cache"
 	"github.com/HeadGardener/coursework/internal/lib/hash"
 	"github.com/HeadGardener/coursework/internal/models"
 	"github.com/google/uuid"
@@ -17,89 +20,246 @@ var (
 	ErrNotSameRefreshToken = errors.New("invalid refresh token")
 	ErrRefreshTokenExpired = errors.New("refresh token expired")
 	ErrInvalidSession      = errors.New("tokens are not connected: invalid access token session")
+	ErrSessionNotFound     = errors.New("session not found")
+	ErrRefreshTokenReused  = errors.New("refresh token has already been used: session revoked")
+	ErrTokenRevoked        = errors.New("access token has been revoked")
+)
+
+const (
+	// denylistCacheTTL bounds how long a revocation made by another instance
+	// can go unnoticed by this one.
+	denylistCacheTTL = 5 * time.Second
 )
 
 type TokenManager interface {
-	GenerateAccessToken(userID string, role models.UserRole, age uint8) (string, error)
+	GenerateAccessToken(userAttr auth.UserAttributes) (string, error)
 	ParseAccessToken(accessToken string) (auth.UserAttributes, error)
 	ParseAccessTokenWithoutExpirationTime(accessToken string) (auth.UserAttributes, error)
 	GenerateRefreshToken() (string, error)
 	GetRefreshTokenTTL() time.Duration
+	GetAccessTokenTTL() time.Duration
+	GenerateResetToken() (string, error)
+	GetResetTokenTTL() time.Duration
+	JWKS() auth.JWKS
 }
 
 type SessionStorage interface {
 	Add(ctx context.Context, session models.Session, ttl time.Duration) error
-	Get(ctx context.Context, userID string) (models.Session, error)
-	Delete(ctx context.Context, userID string) error
+	Get(ctx context.Context, sessionID string) (models.Session, error)
+	GetAllByUser(ctx context.Context, userID string) ([]models.Session, error)
+	Delete(ctx context.Context, userID, sessionID string) error
+	MarkRefreshTokenUsed(ctx context.Context, tokenHash string, token models.UsedRefreshToken, ttl time.Duration) (bool, error)
+	GetUsedRefreshToken(ctx context.Context, tokenHash string) (models.UsedRefreshToken, bool, error)
 }
 
 type UserStorage interface {
 	Create(ctx context.Context, user *models.User) (string, error)
 	GetByUsername(ctx context.Context, username string) (*models.User, error)
 	GetByID(ctx context.Context, userID string) (*models.User, error)
+	UpdatePassword(ctx context.Context, userID, passwordHash string) error
+	SetTOTP(ctx context.Context, userID, secret string, enabled bool) error
+	GetAccess(ctx context.Context, userID string) (models.Access, error)
+	List(ctx context.Context, filter models.UserFilter) ([]models.User, int, error)
+	GetRoles(ctx context.Context, userIDs []string) (map[string][]string, error)
+	ListRoles(ctx context.Context) ([]string, error)
+	SetRoles(ctx context.Context, userID string, roles []string) error
+	SetDisabled(ctx context.Context, userID string, disabled bool) error
+	UpdateProfile(ctx context.Context, userID, username, name string) error
+	Delete(ctx context.Context, userID string) error
+}
+
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Compare(passwordHash, password string) (ok, needsRehash bool, err error)
+}
+
+type Denylist interface {
+	Deny(ctx context.Context, id string, ttl time.Duration) error
+	CheckDenied(ctx context.Context, ids ...string) ([]bool, error)
+}
+
+type ResetTokenStorage interface {
+	Add(ctx context.Context, tokenHash, userID string, ttl time.Duration) error
+	Pop(ctx context.Context, tokenHash string) (string, error)
+}
+
+type Notifier interface {
+	Send(ctx context.Context, user *models.User, notification models.Notification) error
 }
 
 type AuthService struct {
-	tokenManager   TokenManager
-	sessionStorage SessionStorage
-	userStorage    UserStorage
+	tokenManager        TokenManager
+	sessionStorage      SessionStorage
+	userStorage         UserStorage
+	denylist            Denylist
+	denylistCache       *cache.Cache[string, bool]
+	resetTokenStorage   ResetTokenStorage
+	notifier            Notifier
+	recoveryCodeStorage RecoveryCodeStorage
+	mfaStorage          MFAStorage
+	attemptStorage      LoginAttemptStorage
+	passwordHasher      PasswordHasher
+	agePolicies         models.AgePolicies
 }
 
-func NewAuthService(tokenManager TokenManager, tokenStorage SessionStorage, userStorage UserStorage) *AuthService {
+func NewAuthService(tokenManager TokenManager, tokenStorage SessionStorage, userStorage UserStorage,
+	denylist Denylist, resetTokenStorage ResetTokenStorage, notifier Notifier,
+	recoveryCodeStorage RecoveryCodeStorage, mfaStorage MFAStorage, attemptStorage LoginAttemptStorage,
+	passwordHasher PasswordHasher, agePolicies models.AgePolicies) *AuthService {
 	return &AuthService{
-		tokenManager:   tokenManager,
-		sessionStorage: tokenStorage,
-		userStorage:    userStorage,
+		tokenManager:        tokenManager,
+		sessionStorage:      tokenStorage,
+		userStorage:         userStorage,
+		denylist:            denylist,
+		denylistCache:       cache.New[string, bool](),
+		resetTokenStorage:   resetTokenStorage,
+		notifier:            notifier,
+		recoveryCodeStorage: recoveryCodeStorage,
+		mfaStorage:          mfaStorage,
+		attemptStorage:      attemptStorage,
+		passwordHasher:      passwordHasher,
+		agePolicies:         agePolicies,
 	}
 }
 
-func (s *AuthService) SignUp(ctx context.Context, username, name string, age int, password string) (string, error) {
+func (s *AuthService) SignUp(ctx context.Context, username, name string, birthDate time.Time, region, password string) (string, error) {
+	passwordHash, err := s.passwordHasher.Hash(password)
+	if err != nil {
+		return "", err
+	}
+
 	user := &models.User{
 		ID:           uuid.NewString(),
 		Username:     username,
 		Name:         name,
-		Role:         models.RoleUser,
-		Age:          uint8(age),
-		PasswordHash: hash.GetStringHash(password),
+		BirthDate:    birthDate,
+		Region:       region,
+		PasswordHash: passwordHash,
 	}
 
 	return s.userStorage.Create(ctx, user)
 }
 
-func (s *AuthService) SignIn(ctx context.Context, username, password string) (models.Tokens, error) {
+// SignIn checks the password and starts a session. Users with 2FA enabled get
+// an MFA challenge instead, to be completed with CompleteSignIn. Repeated failures
+// lock the username and the client IP out for a while.
+func (s *AuthService) SignIn(ctx context.Context, username, password string,
+	client models.ClientInfo) (models.SignInResult, error) {
+	if err := s.checkLockout(ctx, username, client.IP); err != nil {
+		return models.SignInResult{}, err
+	}
+
 	user, err := s.userStorage.GetByUsername(ctx, username)
 	if err != nil {
-		return models.Tokens{}, err
+		if errors.Is(err, sql.ErrNoRows) {
+			if err := s.registerFailure(ctx, username, client.IP); err != nil {
+				return models.SignInResult{}, err
+			}
+		}
+
+		return models.SignInResult{}, err
+	}
+
+	ok, needsRehash, err := s.comparePassword(user, password)
+	if err != nil {
+		return models.SignInResult{}, err
+	}
+
+	if !ok {
+		if err = s.registerFailure(ctx, username, client.IP); err != nil {
+			return models.SignInResult{}, err
+		}
+
+		return models.SignInResult{}, ErrInvalidPassword
 	}
 
-	if !hash.CompareHashAndString([]byte(user.PasswordHash), password) {
-		return models.Tokens{}, ErrInvalidPassword
+	if err = s.attemptStorage.Reset(ctx, usernameLimitPrefix+username); err != nil {
+		return models.SignInResult{}, err
 	}
 
-	tokens, err := s.createSession(ctx, user.ID, user.Role, user.Age)
+	// checked only after the password, so the flag doesn't leak to whoever guesses usernames
+	if user.Disabled {
+		return models.SignInResult{}, ErrUserDisabled
+	}
+
+	if needsRehash {
+		s.rehashPassword(ctx, user.ID, password)
+	}
+
+	if user.TOTPEnabled {
+		mfaRequired, err := s.createMFAChallenge(ctx, user, client)
+		if err != nil {
+			return models.SignInResult{}, err
+		}
+
+		return models.SignInResult{MFARequired: mfaRequired}, nil
+	}
+
+	tokens, err := s.createSession(ctx, user, client, false)
 	if err != nil {
-		return models.Tokens{}, err
+		return models.SignInResult{}, err
 	}
 
-	return tokens, nil
+	return models.SignInResult{Tokens: tokens}, nil
 }
 
-func (s *AuthService) ParseAccessToken(token string) (auth.UserAttributes, error) {
-	return s.tokenManager.ParseAccessToken(token)
+// ParseAccessToken validates the token and rejects it if the token itself or its session
+// has been revoked. Denylist lookups are cached in-process: denials until the token
+// could have expired anyway, misses for denylistCacheTTL.
+func (s *AuthService) ParseAccessToken(ctx context.Context, token string) (auth.UserAttributes, error) {
+	userAttr, err := s.tokenManager.ParseAccessToken(token)
+	if err != nil {
+		return auth.UserAttributes{}, err
+	}
+
+	denied, err := s.isDenied(ctx, userAttr.TokenID, userAttr.SessionID)
+	if err != nil {
+		return auth.UserAttributes{}, err
+	}
+
+	if denied {
+		return auth.UserAttributes{}, ErrTokenRevoked
+	}
+
+	return userAttr, nil
 }
 
-func (s *AuthService) Refresh(ctx context.Context, accessToken, refreshToken string) (models.Tokens, error) {
+func (s *AuthService) GetJWKS() auth.JWKS {
+	return s.tokenManager.JWKS()
+}
+
+// Refresh rotates the refresh token of the session the access token belongs to.
+// Presenting a refresh token that was already rotated revokes its whole family,
+// since either the legitimate client or an attacker holds a stolen copy.
+func (s *AuthService) Refresh(ctx context.Context, accessToken, refreshToken string,
+	client models.ClientInfo) (models.Tokens, error) {
+	tokenHash := hash.GetTokenHash(refreshToken)
+
+	used, ok, err := s.sessionStorage.GetUsedRefreshToken(ctx, tokenHash)
+	if err != nil {
+		return models.Tokens{}, err
+	}
+
+	if ok {
+		return models.Tokens{}, s.revokeTokenFamily(ctx, used)
+	}
+
 	userAttr, err := s.tokenManager.ParseAccessTokenWithoutExpirationTime(accessToken)
 	if err != nil {
 		return models.Tokens{}, err
 	}
 
-	session, err := s.sessionStorage.Get(ctx, userAttr.ID)
+	session, err := s.sessionStorage.Get(ctx, userAttr.SessionID)
 	if err != nil {
 		return models.Tokens{}, err
 	}
 
-	if !hash.CompareHashAndString([]byte(session.RefreshToken), refreshToken) {
+	// sessions of OAuth clients are refreshed through the token endpoint only
+	if session.UserID != userAttr.ID || session.ClientID != "" {
+		return models.Tokens{}, ErrInvalidSession
+	}
+
+	if !hash.CompareTokenHash(session.RefreshToken, refreshToken) {
 		return models.Tokens{}, ErrNotSameRefreshToken
 	}
 
@@ -107,12 +267,42 @@ func (s *AuthService) Refresh(ctx context.Context, accessToken, refreshToken str
 		return models.Tokens{}, ErrRefreshTokenExpired
 	}
 
+	used = models.UsedRefreshToken{
+		TokenID:   session.TokenID,
+		SessionID: session.ID,
+		UserID:    session.UserID,
+		UsedAt:    time.Now(),
+	}
+
+	ok, err = s.sessionStorage.MarkRefreshTokenUsed(ctx, tokenHash, used, s.tokenManager.GetRefreshTokenTTL())
+	if err != nil {
+		return models.Tokens{}, err
+	}
+
+	// someone else rotated the same token in the meantime
+	if !ok {
+		return models.Tokens{}, s.revokeTokenFamily(ctx, used)
+	}
+
 	user, err := s.userStorage.GetByID(ctx, session.UserID)
 	if err != nil {
 		return models.Tokens{}, err
 	}
 
-	tokens, err := s.createSession(ctx, user.ID, user.Role, user.Age)
+	if user.Disabled {
+		if err = s.deleteSession(ctx, user.ID, session.ID); err != nil {
+			return models.Tokens{}, err
+		}
+
+		return models.Tokens{}, ErrUserDisabled
+	}
+
+	session.ParentTokenID = session.TokenID
+	session.UserAgent = client.UserAgent
+	session.IP = client.IP
+	session.RefreshedAt = time.Now()
+
+	tokens, err := s.issueTokens(ctx, &session, user)
 	if err != nil {
 		return models.Tokens{}, err
 	}
@@ -120,35 +310,201 @@ func (s *AuthService) Refresh(ctx context.Context, accessToken, refreshToken str
 	return tokens, nil
 }
 
-func (s *AuthService) LogOut(ctx context.Context, userID string) error {
-	return s.sessionStorage.Delete(ctx, userID)
+func (s *AuthService) LogOut(ctx context.Context, userAttr auth.UserAttributes) error {
+	if err := s.deny(ctx, userAttr.TokenID, time.Until(userAttr.ExpiresAt)); err != nil {
+		return err
+	}
+
+	return s.deleteSession(ctx, userAttr.ID, userAttr.SessionID)
 }
 
-func (s *AuthService) createSession(ctx context.Context, userID string, role models.UserRole, age uint8) (models.Tokens, error) {
+func (s *AuthService) GetSessions(ctx context.Context, userID, currentSessionID string) ([]models.SessionInfo, error) {
+	sessions, err := s.sessionStorage.GetAllByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]models.SessionInfo, 0, len(sessions))
+	for i := range sessions {
+		infos = append(infos, sessions[i].Info(currentSessionID))
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].CreatedAt.After(infos[j].CreatedAt)
+	})
+
+	return infos, nil
+}
+
+func (s *AuthService) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	sessions, err := s.sessionStorage.GetAllByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for i := range sessions {
+		if sessions[i].ID == sessionID {
+			return s.deleteSession(ctx, userID, sessionID)
+		}
+	}
+
+	return ErrSessionNotFound
+}
+
+// RevokeOtherSessions logs the user out everywhere except the current session.
+func (s *AuthService) RevokeOtherSessions(ctx context.Context, userID, currentSessionID string) error {
+	return s.revokeSessions(ctx, userID, currentSessionID)
+}
+
+// revokeSessions deletes every session of the user except keepSessionID,
+// pass an empty keepSessionID to revoke all of them.
+func (s *AuthService) revokeSessions(ctx context.Context, userID, keepSessionID string) error {
+	sessions, err := s.sessionStorage.GetAllByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for i := range sessions {
+		if sessions[i].ID == keepSessionID {
+			continue
+		}
+
+		if err = s.deleteSession(ctx, userID, sessions[i].ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *AuthService) revokeTokenFamily(ctx context.Context, used models.UsedRefreshToken) error {
+	log.Printf("[WARN] security event: reuse of refresh token %s detected, revoking session %s of user %s",
+		used.TokenID, used.SessionID, used.UserID)
+
+	if err := s.deleteSession(ctx, used.UserID, used.SessionID); err != nil {
+		return err
+	}
+
+	return ErrRefreshTokenReused
+}
+
+// deleteSession removes the session and denies it, so access tokens already
+// issued for it stop working at once.
+func (s *AuthService) deleteSession(ctx context.Context, userID, sessionID string) error {
+	if err := s.deny(ctx, sessionID, s.tokenManager.GetAccessTokenTTL()); err != nil {
+		return err
+	}
+
+	return s.sessionStorage.Delete(ctx, userID, sessionID)
+}
+
+func (s *AuthService) deny(ctx context.Context, id string, ttl time.Duration) error {
+	// tokens without a session, like client credentials ones, have an empty session ID
+	if ttl <= 0 || id == "" {
+		return nil
+	}
+
+	if err := s.denylist.Deny(ctx, id, ttl); err != nil {
+		return err
+	}
+
+	s.denylistCache.Set(id, true, ttl)
+
+	return nil
+}
+
+func (s *AuthService) isDenied(ctx context.Context, ids ...string) (bool, error) {
+	var unknown []string
+
+	for _, id := range ids {
+		denied, ok := s.denylistCache.Get(id)
+		if !ok {
+			unknown = append(unknown, id)
+			continue
+		}
+
+		if denied {
+			return true, nil
+		}
+	}
+
+	if len(unknown) == 0 {
+		return false, nil
+	}
+
+	denied, err := s.denylist.CheckDenied(ctx, unknown...)
+	if err != nil {
+		return false, err
+	}
+
+	var anyDenied bool
+	for i, id := range unknown {
+		if denied[i] {
+			anyDenied = true
+			s.denylistCache.Set(id, true, s.tokenManager.GetAccessTokenTTL())
+			continue
+		}
+
+		s.denylistCache.Set(id, false, denylistCacheTTL)
+	}
+
+	return anyDenied, nil
+}
+
+func (s *AuthService) createSession(ctx context.Context, user *models.User, client models.ClientInfo,
+	mfa bool) (models.Tokens, error) {
+	now := time.Now()
+
+	session := models.Session{
+		ID:          uuid.NewString(),
+		UserID:      user.ID,
+		UserAgent:   client.UserAgent,
+		IP:          client.IP,
+		MFA:         mfa,
+		CreatedAt:   now,
+		RefreshedAt: now,
+	}
+
+	return s.issueTokens(ctx, &session, user)
+}
+
+// issueTokens generates a new token pair bound to session and stores the session
+// with the hash of the new refresh token, replacing the previous one.
+func (s *AuthService) issueTokens(ctx context.Context, session *models.Session, user *models.User) (models.Tokens, error) {
 	var (
 		tokens models.Tokens
 		err    error
 	)
 
-	sessionID := uuid.NewString()
-	tokens.AccessToken, err = s.tokenManager.GenerateAccessToken(userID, role, age)
+	// permissions are resolved on every issue, so role changes apply from the next refresh
+	access, err := s.userStorage.GetAccess(ctx, user.ID)
 	if err != nil {
 		return models.Tokens{}, err
 	}
 
-	tokens.RefreshToken, err = s.tokenManager.GenerateRefreshToken()
+	tokens.AccessToken, err = s.tokenManager.GenerateAccessToken(auth.UserAttributes{
+		ID:          user.ID,
+		SessionID:   session.ID,
+		Roles:       access.Roles,
+		Permissions: access.Permissions,
+		AdultSince:  s.agePolicies.For(user.Region).AdultSince(user.BirthDate),
+		MFA:         session.MFA,
+		AuthTime:    session.CreatedAt,
+	})
 	if err != nil {
 		return models.Tokens{}, err
 	}
 
-	session := models.Session{
-		ID:           sessionID,
-		UserID:       userID,
-		RefreshToken: hash.GetStringHash(tokens.RefreshToken),
-		ExpiresAt:    time.Now().Add(s.tokenManager.GetRefreshTokenTTL()),
+	tokens.RefreshToken, err = s.tokenManager.GenerateRefreshToken()
+	if err != nil {
+		return models.Tokens{}, err
 	}
 
-	if err = s.sessionStorage.Add(ctx, session, s.tokenManager.GetRefreshTokenTTL()); err != nil {
+	session.TokenID = uuid.NewString()
+	session.RefreshToken = hash.GetTokenHash(tokens.RefreshToken)
+	session.ExpiresAt = time.Now().Add(s.tokenManager.GetRefreshTokenTTL())
+
+	if err = s.sessionStorage.Add(ctx, *session, s.tokenManager.GetRefreshTokenTTL()); err != nil {
 		return models.Tokens{}, err
 	}
 
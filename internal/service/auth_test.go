@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/HeadGardener/coursework/internal/config"
+	"github.com/HeadGardener/coursework/internal/lib/auth"
+	"github.com/HeadGardener/coursework/internal/lib/hash"
+	"github.com/HeadGardener/coursework/internal/models"
+	mock_service "github.com/HeadGardener/coursework/internal/service/mocks"
+	"github.com/go-playground/assert/v2"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRefresh(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockSessionStorage, u *mock_service.MockUserStorage, d *mock_service.MockDenylist,
+		session models.Session)
+
+	const refreshToken = "refresh"
+
+	tokenManager, err := auth.NewTokenManager(&config.TokensConfig{
+		SecretKey:       "secret",
+		AccessTokenTTL:  time.Minute,
+		InitialLen:      32,
+		RefreshTokenTTL: time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := &models.User{
+		ID:        "user",
+		Username:  "user",
+		BirthDate: time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	session := models.Session{
+		ID:           "session",
+		UserID:       user.ID,
+		RefreshToken: hash.GetTokenHash(refreshToken),
+		TokenID:      "token",
+		ExpiresAt:    time.Now().Add(time.Hour),
+	}
+
+	accessToken, err := tokenManager.GenerateAccessToken(auth.UserAttributes{
+		ID:        user.ID,
+		SessionID: session.ID,
+		Roles:     []string{models.RoleUser},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	used := models.UsedRefreshToken{
+		TokenID:   session.TokenID,
+		SessionID: session.ID,
+		UserID:    user.ID,
+	}
+
+	testTable := []struct {
+		name          string
+		refreshToken  string
+		mockBehavior  mockBehavior
+		expectedError error
+	}{
+		{
+			name:         "ok",
+			refreshToken: refreshToken,
+			mockBehavior: func(s *mock_service.MockSessionStorage, u *mock_service.MockUserStorage, d *mock_service.MockDenylist,
+				session models.Session) {
+				s.EXPECT().GetUsedRefreshToken(gomock.Any(), session.RefreshToken).Return(models.UsedRefreshToken{}, false, nil)
+				s.EXPECT().Get(gomock.Any(), session.ID).Return(session, nil)
+				s.EXPECT().MarkRefreshTokenUsed(gomock.Any(), session.RefreshToken, gomock.Any(), time.Hour).Return(true, nil)
+				u.EXPECT().GetByID(gomock.Any(), session.UserID).Return(user, nil)
+				u.EXPECT().GetAccess(gomock.Any(), user.ID).Return(models.Access{Roles: []string{models.RoleUser}}, nil)
+				s.EXPECT().Add(gomock.Any(), gomock.Any(), time.Hour).DoAndReturn(
+					func(_ context.Context, newSession models.Session, _ time.Duration) error {
+						assert.Equal(t, session.ID, newSession.ID)
+						assert.Equal(t, session.TokenID, newSession.ParentTokenID)
+						assert.NotEqual(t, session.TokenID, newSession.TokenID)
+						assert.NotEqual(t, session.RefreshToken, newSession.RefreshToken)
+
+						return nil
+					})
+			},
+		},
+		{
+			name:         "replayed refresh token",
+			refreshToken: refreshToken,
+			mockBehavior: func(s *mock_service.MockSessionStorage, u *mock_service.MockUserStorage, d *mock_service.MockDenylist,
+				session models.Session) {
+				s.EXPECT().GetUsedRefreshToken(gomock.Any(), session.RefreshToken).Return(used, true, nil)
+				d.EXPECT().Deny(gomock.Any(), used.SessionID, time.Minute).Return(nil)
+				s.EXPECT().Delete(gomock.Any(), used.UserID, used.SessionID).Return(nil)
+			},
+			expectedError: ErrRefreshTokenReused,
+		},
+		{
+			name:         "concurrent rotation",
+			refreshToken: refreshToken,
+			mockBehavior: func(s *mock_service.MockSessionStorage, u *mock_service.MockUserStorage, d *mock_service.MockDenylist,
+				session models.Session) {
+				s.EXPECT().GetUsedRefreshToken(gomock.Any(), session.RefreshToken).Return(models.UsedRefreshToken{}, false, nil)
+				s.EXPECT().Get(gomock.Any(), session.ID).Return(session, nil)
+				s.EXPECT().MarkRefreshTokenUsed(gomock.Any(), session.RefreshToken, gomock.Any(), time.Hour).Return(false, nil)
+				d.EXPECT().Deny(gomock.Any(), session.ID, time.Minute).Return(nil)
+				s.EXPECT().Delete(gomock.Any(), session.UserID, session.ID).Return(nil)
+			},
+			expectedError: ErrRefreshTokenReused,
+		},
+		{
+			name:         "disabled user",
+			refreshToken: refreshToken,
+			mockBehavior: func(s *mock_service.MockSessionStorage, u *mock_service.MockUserStorage, d *mock_service.MockDenylist,
+				session models.Session) {
+				disabled := *user
+				disabled.Disabled = true
+
+				s.EXPECT().GetUsedRefreshToken(gomock.Any(), session.RefreshToken).Return(models.UsedRefreshToken{}, false, nil)
+				s.EXPECT().Get(gomock.Any(), session.ID).Return(session, nil)
+				s.EXPECT().MarkRefreshTokenUsed(gomock.Any(), session.RefreshToken, gomock.Any(), time.Hour).Return(true, nil)
+				u.EXPECT().GetByID(gomock.Any(), session.UserID).Return(&disabled, nil)
+				d.EXPECT().Deny(gomock.Any(), session.ID, time.Minute).Return(nil)
+				s.EXPECT().Delete(gomock.Any(), session.UserID, session.ID).Return(nil)
+			},
+			expectedError: ErrUserDisabled,
+		},
+		{
+			name:         "oauth client session",
+			refreshToken: refreshToken,
+			mockBehavior: func(s *mock_service.MockSessionStorage, u *mock_service.MockUserStorage, d *mock_service.MockDenylist,
+				session models.Session) {
+				session.ClientID = "client"
+
+				s.EXPECT().GetUsedRefreshToken(gomock.Any(), session.RefreshToken).Return(models.UsedRefreshToken{}, false, nil)
+				s.EXPECT().Get(gomock.Any(), session.ID).Return(session, nil)
+			},
+			expectedError: ErrInvalidSession,
+		},
+		{
+			name:         "wrong refresh token",
+			refreshToken: "wrong",
+			mockBehavior: func(s *mock_service.MockSessionStorage, u *mock_service.MockUserStorage, d *mock_service.MockDenylist,
+				session models.Session) {
+				s.EXPECT().GetUsedRefreshToken(gomock.Any(), hash.GetTokenHash("wrong")).Return(models.UsedRefreshToken{}, false, nil)
+				s.EXPECT().Get(gomock.Any(), session.ID).Return(session, nil)
+			},
+			expectedError: ErrNotSameRefreshToken,
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			sessionStorage := mock_service.NewMockSessionStorage(c)
+			userStorage := mock_service.NewMockUserStorage(c)
+			denylist := mock_service.NewMockDenylist(c)
+			tc.mockBehavior(sessionStorage, userStorage, denylist, session)
+
+			service := NewAuthService(tokenManager, sessionStorage, userStorage, denylist, nil, nil, nil, nil, nil, nil, models.AgePolicies{})
+
+			_, err := service.Refresh(context.Background(), accessToken, tc.refreshToken, models.ClientInfo{})
+
+			assert.Equal(t, tc.expectedError, err)
+		})
+	}
+}
+
+func TestParseAccessToken(t *testing.T) {
+	type mockBehavior func(d *mock_service.MockDenylist, userAttr auth.UserAttributes)
+
+	tokenManager, err := auth.NewTokenManager(&config.TokensConfig{
+		SecretKey:      "secret",
+		AccessTokenTTL: time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	accessToken, err := tokenManager.GenerateAccessToken(auth.UserAttributes{
+		ID:        "user",
+		SessionID: "session",
+		Roles:     []string{models.RoleUser},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	userAttr, err := tokenManager.ParseAccessToken(accessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testTable := []struct {
+		name          string
+		mockBehavior  mockBehavior
+		expectedError error
+	}{
+		{
+			name: "ok",
+			mockBehavior: func(d *mock_service.MockDenylist, userAttr auth.UserAttributes) {
+				d.EXPECT().CheckDenied(gomock.Any(), userAttr.TokenID, userAttr.SessionID).Return([]bool{false, false}, nil)
+			},
+		},
+		{
+			name: "revoked token",
+			mockBehavior: func(d *mock_service.MockDenylist, userAttr auth.UserAttributes) {
+				d.EXPECT().CheckDenied(gomock.Any(), userAttr.TokenID, userAttr.SessionID).Return([]bool{true, false}, nil)
+			},
+			expectedError: ErrTokenRevoked,
+		},
+		{
+			name: "revoked session",
+			mockBehavior: func(d *mock_service.MockDenylist, userAttr auth.UserAttributes) {
+				d.EXPECT().CheckDenied(gomock.Any(), userAttr.TokenID, userAttr.SessionID).Return([]bool{false, true}, nil)
+			},
+			expectedError: ErrTokenRevoked,
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			denylist := mock_service.NewMockDenylist(c)
+			tc.mockBehavior(denylist, userAttr)
+
+			service := NewAuthService(tokenManager, nil, nil, denylist, nil, nil, nil, nil, nil, nil, models.AgePolicies{})
+
+			_, err := service.ParseAccessToken(context.Background(), accessToken)
+			assert.Equal(t, tc.expectedError, err)
+
+			// the second check must be served from the local cache
+			_, err = service.ParseAccessToken(context.Background(), accessToken)
+			assert.Equal(t, tc.expectedError, err)
+		})
+	}
+}
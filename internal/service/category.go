@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/HeadGardener/coursework/internal/models"
+	"github.com/HeadGardener/coursework/internal/storage"
+)
+
+var (
+	ErrCategoryNotFound = errors.New("category not found")
+	ErrParentNotFound   = errors.New("parent category not found")
+	ErrCategoryExists   = errors.New("category with this name already exists")
+	ErrCategoryCycle    = errors.New("category can't be moved under itself or its subcategory")
+	ErrCategoryInUse    = errors.New("category still has drinks or subcategories")
+)
+
+type CategoryStorage interface {
+	GetAll(ctx context.Context) ([]models.Category, error)
+	GetByID(ctx context.Context, id int) (models.Category, error)
+	GetByName(ctx context.Context, name string) (models.Category, error)
+	CountDrinks(ctx context.Context, filter models.DrinkFilter) (map[int]int, error)
+	Create(ctx context.Context, category *models.Category) (int, error)
+	Update(ctx context.Context, id int, category *models.Category) error
+	Delete(ctx context.Context, id int) error
+}
+
+type CategoryService struct {
+	categoryStorage CategoryStorage
+	softDrinkMaxABV float64
+}
+
+func NewCategoryService(categoryStorage CategoryStorage, softDrinkMaxABV float64) *CategoryService {
+	return &CategoryService{
+		categoryStorage: categoryStorage,
+		softDrinkMaxABV: softDrinkMaxABV,
+	}
+}
+
+// Tree returns all categories as a tree. Drink counts only include the drinks
+// customer may be served.
+func (s *CategoryService) Tree(ctx context.Context, customer models.Customer) ([]*models.CategoryNode, error) {
+	categories, err := s.categoryStorage.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.categoryStorage.CountDrinks(ctx, drinkFilter(s.softDrinkMaxABV, customer))
+	if err != nil {
+		return nil, err
+	}
+
+	return models.BuildCategoryTree(categories, counts), nil
+}
+
+func (s *CategoryService) Create(ctx context.Context, category *models.Category) (int, error) {
+	if err := s.checkName(ctx, 0, category.Name); err != nil {
+		return 0, err
+	}
+
+	if category.ParentID != nil {
+		if _, err := s.categoryStorage.GetByID(ctx, *category.ParentID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return 0, ErrParentNotFound
+			}
+
+			return 0, err
+		}
+	}
+
+	return s.categoryStorage.Create(ctx, category)
+}
+
+// Update replaces the category. It may be moved under another parent, but not
+// under itself or one of its subcategories.
+func (s *CategoryService) Update(ctx context.Context, id int, category *models.Category) error {
+	categories, err := s.categoryStorage.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !containsCategory(categories, id) {
+		return ErrCategoryNotFound
+	}
+
+	if err = s.checkName(ctx, id, category.Name); err != nil {
+		return err
+	}
+
+	if parentID := category.ParentID; parentID != nil {
+		if !containsCategory(categories, *parentID) {
+			return ErrParentNotFound
+		}
+
+		if models.IsDescendant(categories, *parentID, id) {
+			return ErrCategoryCycle
+		}
+	}
+
+	return s.categoryStorage.Update(ctx, id, category)
+}
+
+func (s *CategoryService) Delete(ctx context.Context, id int) error {
+	err := s.categoryStorage.Delete(ctx, id)
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrCategoryNotFound
+	case errors.Is(err, storage.ErrCategoryInUse):
+		return ErrCategoryInUse
+	default:
+		return err
+	}
+}
+
+// checkName fails if a category other than the one with the given ID has the name.
+func (s *CategoryService) checkName(ctx context.Context, id int, name string) error {
+	existing, err := s.categoryStorage.GetByName(ctx, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if existing.ID != id {
+		return ErrCategoryExists
+	}
+
+	return nil
+}
+
+func containsCategory(categories []models.Category, id int) bool {
+	for _, category := range categories {
+		if category.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/HeadGardener/coursework/internal/models"
+	mock_service "github.com/HeadGardener/coursework/internal/service/mocks"
+	"github.com/HeadGardener/coursework/internal/storage"
+	"github.com/go-playground/assert/v2"
+	"go.uber.org/mock/gomock"
+)
+
+func TestUpdateCategory(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockCategoryStorage, category *models.Category)
+
+	spirits, whiskey, bourbon := 1, 2, 3
+	categories := []models.Category{
+		{ID: spirits, Name: "Spirits"},
+		{ID: whiskey, Name: "Whiskey", ParentID: &spirits},
+		{ID: bourbon, Name: "Bourbon", ParentID: &whiskey},
+	}
+
+	missing := 42
+
+	testTable := []struct {
+		name          string
+		id            int
+		category      *models.Category
+		mockBehavior  mockBehavior
+		expectedError error
+	}{
+		{
+			name:     "ok",
+			id:       bourbon,
+			category: &models.Category{Name: "Bourbon whiskey", ParentID: &spirits},
+			mockBehavior: func(s *mock_service.MockCategoryStorage, category *models.Category) {
+				s.EXPECT().GetByName(gomock.Any(), category.Name).Return(models.Category{}, sql.ErrNoRows)
+				s.EXPECT().Update(gomock.Any(), bourbon, category).Return(nil)
+			},
+		},
+		{
+			name:          "unknown category",
+			id:            missing,
+			category:      &models.Category{Name: "Rye"},
+			mockBehavior:  func(s *mock_service.MockCategoryStorage, category *models.Category) {},
+			expectedError: ErrCategoryNotFound,
+		},
+		{
+			name:     "name taken",
+			id:       bourbon,
+			category: &models.Category{Name: "whiskey", ParentID: &whiskey},
+			mockBehavior: func(s *mock_service.MockCategoryStorage, category *models.Category) {
+				s.EXPECT().GetByName(gomock.Any(), category.Name).Return(categories[1], nil)
+			},
+			expectedError: ErrCategoryExists,
+		},
+		{
+			name:     "unknown parent",
+			id:       bourbon,
+			category: &models.Category{Name: "Bourbon", ParentID: &missing},
+			mockBehavior: func(s *mock_service.MockCategoryStorage, category *models.Category) {
+				s.EXPECT().GetByName(gomock.Any(), category.Name).Return(categories[2], nil)
+			},
+			expectedError: ErrParentNotFound,
+		},
+		{
+			name:     "moved under its subcategory",
+			id:       spirits,
+			category: &models.Category{Name: "Spirits", ParentID: &bourbon},
+			mockBehavior: func(s *mock_service.MockCategoryStorage, category *models.Category) {
+				s.EXPECT().GetByName(gomock.Any(), category.Name).Return(categories[0], nil)
+			},
+			expectedError: ErrCategoryCycle,
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			categoryStorage := mock_service.NewMockCategoryStorage(c)
+			categoryStorage.EXPECT().GetAll(gomock.Any()).Return(categories, nil)
+			tc.mockBehavior(categoryStorage, tc.category)
+
+			service := NewCategoryService(categoryStorage, 0.5)
+
+			err := service.Update(context.Background(), tc.id, tc.category)
+
+			assert.Equal(t, tc.expectedError, err)
+		})
+	}
+}
+
+func TestDeleteCategory(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockCategoryStorage)
+
+	testTable := []struct {
+		name          string
+		mockBehavior  mockBehavior
+		expectedError error
+	}{
+		{
+			name: "ok",
+			mockBehavior: func(s *mock_service.MockCategoryStorage) {
+				s.EXPECT().Delete(gomock.Any(), 1).Return(nil)
+			},
+		},
+		{
+			name: "not found",
+			mockBehavior: func(s *mock_service.MockCategoryStorage) {
+				s.EXPECT().Delete(gomock.Any(), 1).Return(sql.ErrNoRows)
+			},
+			expectedError: ErrCategoryNotFound,
+		},
+		{
+			name: "in use",
+			mockBehavior: func(s *mock_service.MockCategoryStorage) {
+				s.EXPECT().Delete(gomock.Any(), 1).Return(storage.ErrCategoryInUse)
+			},
+			expectedError: ErrCategoryInUse,
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			categoryStorage := mock_service.NewMockCategoryStorage(c)
+			tc.mockBehavior(categoryStorage)
+
+			service := NewCategoryService(categoryStorage, 0.5)
+
+			err := service.Delete(context.Background(), 1)
+
+			assert.Equal(t, tc.expectedError, err)
+		})
+	}
+}
@@ -2,63 +2,278 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/HeadGardener/coursework/internal/models"
 )
 
+var (
+	ErrDrinkNotFound      = errors.New("drink not found")
+	ErrAlcoholicSoftDrink = errors.New("drinks stronger than the soft drink ABV threshold can't be in the soft category")
+	ErrInvalidCost        = errors.New("invalid cost")
+)
+
 type DrinkStorage interface {
-	GetAll(ctx context.Context, adult bool) ([]models.Drink, error)
-	GetByID(ctx context.Context, id int, adult bool) (models.Drink, error)
+	GetAll(ctx context.Context, filter models.DrinkFilter, query models.DrinkQuery) ([]models.Drink, error)
+	Search(ctx context.Context, filter models.DrinkFilter, search models.DrinkSearch) ([]models.Drink, int, error)
+	Autocomplete(ctx context.Context, filter models.DrinkFilter, prefix string, limit int) ([]models.DrinkSuggestion, error)
+	GetByID(ctx context.Context, id int, filter models.DrinkFilter) (models.Drink, error)
 	Create(ctx context.Context, drink *models.Drink) (int, error)
 	Update(ctx context.Context, id int, drink *models.Drink) error
+	Patch(ctx context.Context, id int, apply func(drink *models.Drink) error) error
 	Delete(ctx context.Context, id int) error
 }
 
 type DrinkService struct {
-	drinkStorage DrinkStorage
+	drinkStorage    DrinkStorage
+	categoryStorage CategoryStorage
+	softDrinkMaxABV float64
 }
 
-func NewDrinkService(drinkStorage DrinkStorage) *DrinkService {
-	return &DrinkService{drinkStorage: drinkStorage}
+func NewDrinkService(drinkStorage DrinkStorage, categoryStorage CategoryStorage,
+	softDrinkMaxABV float64) *DrinkService {
+	return &DrinkService{
+		drinkStorage:    drinkStorage,
+		categoryStorage: categoryStorage,
+		softDrinkMaxABV: softDrinkMaxABV,
+	}
 }
 
-func (s *DrinkService) GetAll(ctx context.Context, adult bool) ([]models.Drink, error) {
-	return s.drinkStorage.GetAll(ctx, adult)
+// GetAll returns a page of the drinks customer may be served under the policy of their
+// region, as carried by their token. One drink more than the limit is fetched to learn
+// if there is a next page.
+func (s *DrinkService) GetAll(ctx context.Context, customer models.Customer,
+	query models.DrinkQuery) (models.DrinkPage, error) {
+	limit := query.Limit
+	query.Limit++
+
+	drinks, err := s.drinkStorage.GetAll(ctx, s.filter(customer), query)
+	if err != nil {
+		return models.DrinkPage{}, err
+	}
+
+	page := models.DrinkPage{
+		Drinks: drinks,
+	}
+
+	if len(drinks) > limit {
+		page.Drinks = drinks[:limit]
+		page.NextCursor = models.NewDrinkCursor(query, drinks[limit-1]).Encode()
+	}
+
+	return page, nil
 }
 
-func (s *DrinkService) GetByID(ctx context.Context, id int, adult bool) (models.Drink, error) {
-	return s.drinkStorage.GetByID(ctx, id, adult)
+// Search looks drinks customer may be served up by name or category, tolerating typos.
+func (s *DrinkService) Search(ctx context.Context, customer models.Customer,
+	search models.DrinkSearch) (models.DrinkSearchPage, error) {
+	drinks, total, err := s.drinkStorage.Search(ctx, s.filter(customer), search)
+	if err != nil {
+		return models.DrinkSearchPage{}, err
+	}
+
+	return models.DrinkSearchPage{
+		Drinks:  drinks,
+		Total:   total,
+		Page:    search.Page,
+		PerPage: search.PerPage,
+	}, nil
+}
+
+// Autocomplete suggests names of drinks customer may be served for a name being typed.
+func (s *DrinkService) Autocomplete(ctx context.Context, customer models.Customer, prefix string,
+	limit int) ([]models.DrinkSuggestion, error) {
+	return s.drinkStorage.Autocomplete(ctx, s.filter(customer), prefix, limit)
 }
 
+func (s *DrinkService) GetByID(ctx context.Context, id int, customer models.Customer) (models.Drink, error) {
+	return s.drinkStorage.GetByID(ctx, id, s.filter(customer))
+}
+
+// Add stores a new drink. Drinks without an age category get one from their ABV and
+// drink category, strong ones with no default in their category fall under the strictest.
 func (s *DrinkService) Add(ctx context.Context, drink *models.Drink) (int, error) {
+	if err := s.prepare(ctx, drink); err != nil {
+		return 0, err
+	}
+
 	return s.drinkStorage.Create(ctx, drink)
 }
 
-func (s *DrinkService) Update(ctx context.Context, id int, drinkInput *models.Drink) error {
-	drink, err := s.drinkStorage.GetByID(ctx, id, true)
+// Update replaces the drink with the given ID. An empty age category is derived
+// anew, as in Add.
+func (s *DrinkService) Update(ctx context.Context, id int, drink *models.Drink) error {
+	if _, err := s.getDrink(ctx, id); err != nil {
+		return err
+	}
+
+	if err := s.prepare(ctx, drink); err != nil {
+		return err
+	}
+
+	return s.drinkStorage.Update(ctx, id, drink)
+}
+
+// Patch changes only the fields set in patch. A new drink category or ABV derives
+// the age category anew unless the patch sets one. The drink stays locked while
+// the patch is applied, so concurrent patches don't lose each other's changes.
+func (s *DrinkService) Patch(ctx context.Context, id int, patch models.DrinkPatch) error {
+	err := s.drinkStorage.Patch(ctx, id, func(drink *models.Drink) error {
+		return s.applyPatch(ctx, drink, patch)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrDrinkNotFound
+	}
+
+	return err
+}
+
+func (s *DrinkService) Delete(ctx context.Context, id int) error {
+	return s.drinkStorage.Delete(ctx, id)
+}
+
+func (s *DrinkService) applyPatch(ctx context.Context, drink *models.Drink, patch models.DrinkPatch) error {
+	if patch.Name != nil {
+		drink.Name = *patch.Name
+	}
+
+	categoryChanged := patch.CategoryID != nil && *patch.CategoryID != drink.CategoryID
+	if categoryChanged {
+		drink.CategoryID = *patch.CategoryID
+	}
+
+	if patch.Bottle != nil {
+		drink.Bottle = *patch.Bottle
+	}
+
+	if patch.CostAmount != nil || patch.Currency != nil {
+		cost, err := patchCost(drink.Cost, patch.CostAmount, patch.Currency)
+		if err != nil {
+			return err
+		}
+
+		drink.Cost = cost
+	}
+
+	abvChanged := patch.ABV != nil && *patch.ABV != drink.ABV
+	if abvChanged {
+		drink.ABV = *patch.ABV
+	}
+
+	switch {
+	case patch.AgeCategory != nil:
+		drink.AgeCategory = *patch.AgeCategory
+	case patch.ResetAgeCategory || categoryChanged || abvChanged:
+		drink.AgeCategory = ""
+	}
+
+	return s.prepare(ctx, drink)
+}
+
+func (s *DrinkService) filter(customer models.Customer) models.DrinkFilter {
+	return drinkFilter(s.softDrinkMaxABV, customer)
+}
+
+// prepare checks the drink category exists and fills in a missing age category.
+func (s *DrinkService) prepare(ctx context.Context, drink *models.Drink) error {
+	categoryDefault, err := s.categoryDefault(ctx, drink.CategoryID)
 	if err != nil {
 		return err
 	}
 
-	if drink.Name != drinkInput.Name {
-		drink.Name = drinkInput.Name
+	if drink.AgeCategory == "" {
+		drink.AgeCategory = s.defaultAgeCategory(categoryDefault, drink.ABV)
+	}
+
+	return s.checkCategory(drink)
+}
+
+// getDrink returns the drink with the given ID whatever its age category,
+// ErrDrinkNotFound if there is none.
+func (s *DrinkService) getDrink(ctx context.Context, id int) (models.Drink, error) {
+	drink, err := s.drinkStorage.GetByID(ctx, id, s.anyDrink())
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Drink{}, ErrDrinkNotFound
 	}
 
-	if drink.Type != drinkInput.Type {
-		drink.Type = drinkInput.Type
+	return drink, err
+}
+
+// anyDrink is a filter that lets every drink through.
+func (s *DrinkService) anyDrink() models.DrinkFilter {
+	return models.DrinkFilter{
+		AgeCategories:   models.AgeCategories,
+		SoftDrinkMaxABV: s.softDrinkMaxABV,
 	}
+}
 
-	if drink.Bottle != drinkInput.Bottle && drinkInput.Bottle != 0 {
-		drink.Bottle = drinkInput.Bottle
+// categoryDefault returns the default age category of the drink category with the given ID,
+// empty if neither it nor its ancestors have one.
+func (s *DrinkService) categoryDefault(ctx context.Context, categoryID int) (models.AgeCategory, error) {
+	categories, err := s.categoryStorage.GetAll(ctx)
+	if err != nil {
+		return "", err
 	}
 
-	if drink.Cost != drinkInput.Cost && drinkInput.Cost != 0 {
-		drink.Cost = drinkInput.Cost
+	ageCategory, ok := models.DefaultAgeCategory(categories, categoryID)
+	if !ok {
+		return "", ErrCategoryNotFound
 	}
 
-	return s.drinkStorage.Update(ctx, id, &drink)
+	return ageCategory, nil
 }
 
-func (s *DrinkService) Delete(ctx context.Context, id int) error {
-	return s.drinkStorage.Delete(ctx, id)
+// defaultAgeCategory picks the age category of a drink that has none: soft for drinks
+// not stronger than the threshold, otherwise the default of its drink category.
+func (s *DrinkService) defaultAgeCategory(categoryDefault models.AgeCategory, abv float64) models.AgeCategory {
+	switch {
+	case abv <= s.softDrinkMaxABV:
+		return models.AgeCategorySoft
+	case categoryDefault != "":
+		return categoryDefault
+	default:
+		return models.AgeCategorySpirits
+	}
+}
+
+func (s *DrinkService) checkCategory(drink *models.Drink) error {
+	if drink.AgeCategory == models.AgeCategorySoft && drink.ABV > s.softDrinkMaxABV {
+		return ErrAlcoholicSoftDrink
+	}
+
+	return nil
+}
+
+// patchCost merges a new amount and currency into cost. A new currency alone keeps
+// the decimal amount, so it must have enough minor unit digits for it.
+func patchCost(cost models.Money, amount *string, currency *models.Currency) (models.Money, error) {
+	newAmount, newCurrency := cost.Decimal(), cost.Currency
+	if amount != nil {
+		newAmount = *amount
+	}
+
+	if currency != nil {
+		newCurrency = *currency
+	}
+
+	patched, err := models.ParseMoney(newAmount, newCurrency)
+	if err != nil {
+		return models.Money{}, fmt.Errorf("%w: %w", ErrInvalidCost, err)
+	}
+
+	if patched.Amount < 0 {
+		return models.Money{}, fmt.Errorf("%w: can't be less than 0", ErrInvalidCost)
+	}
+
+	return patched, nil
+}
+
+func drinkFilter(softDrinkMaxABV float64, customer models.Customer) models.DrinkFilter {
+	return models.DrinkFilter{
+		AgeCategories:   customer.AllowedCategories(time.Now()),
+		SoftDrinkMaxABV: softDrinkMaxABV,
+	}
 }
@@ -0,0 +1,369 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/HeadGardener/coursework/internal/models"
+	mock_service "github.com/HeadGardener/coursework/internal/service/mocks"
+	"github.com/go-playground/assert/v2"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAddDrink(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockDrinkStorage, expected *models.Drink)
+
+	beerWine := models.AgeCategoryBeerWine
+	parentID := 1
+	categories := []models.Category{
+		{ID: 1, Name: "Beer", AgeCategory: &beerWine},
+		{ID: 2, Name: "Craft beer", ParentID: &parentID},
+		{ID: 3, Name: "Other"},
+	}
+
+	testTable := []struct {
+		name             string
+		drink            models.Drink
+		expectedCategory models.AgeCategory
+		mockBehavior     mockBehavior
+		expectedError    error
+	}{
+		{
+			name:             "alcohol-free beer is soft",
+			drink:            models.Drink{Name: "beer", CategoryID: 1, ABV: 0.5},
+			expectedCategory: models.AgeCategorySoft,
+			mockBehavior: func(s *mock_service.MockDrinkStorage, expected *models.Drink) {
+				s.EXPECT().Create(gomock.Any(), expected).Return(1, nil)
+			},
+		},
+		{
+			name:             "strong drink without category",
+			drink:            models.Drink{Name: "whiskey", CategoryID: 3, ABV: 40},
+			expectedCategory: models.AgeCategorySpirits,
+			mockBehavior: func(s *mock_service.MockDrinkStorage, expected *models.Drink) {
+				s.EXPECT().Create(gomock.Any(), expected).Return(1, nil)
+			},
+		},
+		{
+			name:             "explicit category",
+			drink:            models.Drink{Name: "beer", CategoryID: 3, ABV: 5, AgeCategory: models.AgeCategoryBeerWine},
+			expectedCategory: models.AgeCategoryBeerWine,
+			mockBehavior: func(s *mock_service.MockDrinkStorage, expected *models.Drink) {
+				s.EXPECT().Create(gomock.Any(), expected).Return(1, nil)
+			},
+		},
+		{
+			name:             "alcoholic soft drink",
+			drink:            models.Drink{Name: "beer", CategoryID: 1, ABV: 5, AgeCategory: models.AgeCategorySoft},
+			expectedCategory: models.AgeCategorySoft,
+			mockBehavior:     func(s *mock_service.MockDrinkStorage, expected *models.Drink) {},
+			expectedError:    ErrAlcoholicSoftDrink,
+		},
+		{
+			name:             "default of the drink category",
+			drink:            models.Drink{Name: "ipa", CategoryID: 2, ABV: 6},
+			expectedCategory: models.AgeCategoryBeerWine,
+			mockBehavior: func(s *mock_service.MockDrinkStorage, expected *models.Drink) {
+				s.EXPECT().Create(gomock.Any(), expected).Return(1, nil)
+			},
+		},
+		{
+			name:          "unknown drink category",
+			drink:         models.Drink{Name: "beer", CategoryID: 42, ABV: 5},
+			mockBehavior:  func(s *mock_service.MockDrinkStorage, expected *models.Drink) {},
+			expectedError: ErrCategoryNotFound,
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			expected := tc.drink
+			expected.AgeCategory = tc.expectedCategory
+
+			drinkStorage := mock_service.NewMockDrinkStorage(c)
+			tc.mockBehavior(drinkStorage, &expected)
+
+			categoryStorage := mock_service.NewMockCategoryStorage(c)
+			categoryStorage.EXPECT().GetAll(gomock.Any()).Return(categories, nil)
+
+			service := NewDrinkService(drinkStorage, categoryStorage, 0.5)
+
+			drink := tc.drink
+			_, err := service.Add(context.Background(), &drink)
+
+			assert.Equal(t, tc.expectedError, err)
+		})
+	}
+}
+
+func TestGetAllDrinks(t *testing.T) {
+	drinks := []models.Drink{
+		{ID: 1, Name: "cola", Cost: models.Money{Amount: 100, Currency: "EUR"}},
+		{ID: 2, Name: "juice", Cost: models.Money{Amount: 150, Currency: "EUR"}},
+		{ID: 3, Name: "water", Cost: models.Money{Amount: 50, Currency: "EUR"}},
+	}
+
+	testTable := []struct {
+		name           string
+		query          models.DrinkQuery
+		stored         []models.Drink
+		expectedDrinks []models.Drink
+		expectedCursor string
+	}{
+		{
+			name:           "last page",
+			query:          models.DrinkQuery{Sort: models.DrinkSortID, Limit: 3},
+			stored:         drinks,
+			expectedDrinks: drinks,
+		},
+		{
+			name:           "more pages",
+			query:          models.DrinkQuery{Sort: models.DrinkSortCost, Desc: true, Limit: 2},
+			stored:         drinks,
+			expectedDrinks: drinks[:2],
+			expectedCursor: models.DrinkCursor{Sort: models.DrinkSortCost, Desc: true, ID: 2, Cost: 150}.Encode(),
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			expectedQuery := tc.query
+			expectedQuery.Limit++
+
+			drinkStorage := mock_service.NewMockDrinkStorage(c)
+			drinkStorage.EXPECT().GetAll(gomock.Any(), gomock.Any(), expectedQuery).Return(tc.stored, nil)
+
+			service := NewDrinkService(drinkStorage, nil, 0.5)
+
+			page, err := service.GetAll(context.Background(), models.Customer{}, tc.query)
+
+			assert.Equal(t, nil, err)
+			assert.Equal(t, tc.expectedDrinks, page.Drinks)
+			assert.Equal(t, tc.expectedCursor, page.NextCursor)
+		})
+	}
+}
+
+func TestPatchDrink(t *testing.T) {
+	beerWine := models.AgeCategoryBeerWine
+	categories := []models.Category{
+		{ID: 1, Name: "Beer", AgeCategory: &beerWine},
+		{ID: 2, Name: "Spirits"},
+	}
+
+	stored := models.Drink{
+		ID:          1,
+		Name:        "lager",
+		CategoryID:  1,
+		Bottle:      500,
+		Cost:        models.Money{Amount: 450, Currency: "EUR"},
+		ABV:         5,
+		AgeCategory: models.AgeCategoryBeerWine,
+	}
+
+	ptr := func(s string) *string { return &s }
+	zero, jpy, kwd := "0", models.Currency("JPY"), models.Currency("KWD")
+	spirits, categoryID, abv := models.AgeCategorySpirits, 2, 0.0
+
+	testTable := []struct {
+		name          string
+		patch         models.DrinkPatch
+		expected      func(d models.Drink) models.Drink
+		expectedError error
+	}{
+		{
+			name:  "cost set to zero",
+			patch: models.DrinkPatch{CostAmount: &zero},
+			expected: func(d models.Drink) models.Drink {
+				d.Cost.Amount = 0
+				return d
+			},
+		},
+		{
+			name:  "name only",
+			patch: models.DrinkPatch{Name: ptr("pils")},
+			expected: func(d models.Drink) models.Drink {
+				d.Name = "pils"
+				return d
+			},
+		},
+		{
+			name:  "currency only keeps the amount",
+			patch: models.DrinkPatch{Currency: &kwd},
+			expected: func(d models.Drink) models.Drink {
+				d.Cost = models.Money{Amount: 4500, Currency: "KWD"}
+				return d
+			},
+		},
+		{
+			name:          "currency without minor units for the amount",
+			patch:         models.DrinkPatch{Currency: &jpy},
+			expectedError: ErrInvalidCost,
+		},
+		{
+			name:  "abv set to zero makes it soft",
+			patch: models.DrinkPatch{ABV: &abv, ResetAgeCategory: true},
+			expected: func(d models.Drink) models.Drink {
+				d.ABV = 0
+				d.AgeCategory = models.AgeCategorySoft
+				return d
+			},
+		},
+		{
+			name:  "new category derives the age category",
+			patch: models.DrinkPatch{CategoryID: &categoryID},
+			expected: func(d models.Drink) models.Drink {
+				d.CategoryID = 2
+				d.AgeCategory = models.AgeCategorySpirits
+				return d
+			},
+		},
+		{
+			name:  "explicit age category wins",
+			patch: models.DrinkPatch{CategoryID: &categoryID, AgeCategory: &spirits},
+			expected: func(d models.Drink) models.Drink {
+				d.CategoryID = 2
+				d.AgeCategory = models.AgeCategorySpirits
+				return d
+			},
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var expected models.Drink
+			if tc.expected != nil {
+				expected = tc.expected(stored)
+			}
+
+			drinkStorage := mock_service.NewMockDrinkStorage(c)
+			expectPatch(t, drinkStorage, stored, expected)
+
+			categoryStorage := mock_service.NewMockCategoryStorage(c)
+			categoryStorage.EXPECT().GetAll(gomock.Any()).Return(categories, nil).AnyTimes()
+
+			service := NewDrinkService(drinkStorage, categoryStorage, 0.5)
+
+			err := service.Patch(context.Background(), 1, tc.patch)
+
+			assert.Equal(t, true, errors.Is(err, tc.expectedError))
+		})
+	}
+}
+
+func TestUpdateDrink(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	drinkStorage := mock_service.NewMockDrinkStorage(c)
+	drinkStorage.EXPECT().GetByID(gomock.Any(), 2, gomock.Any()).Return(models.Drink{}, sql.ErrNoRows)
+
+	service := NewDrinkService(drinkStorage, nil, 0.5)
+
+	err := service.Update(context.Background(), 2, &models.Drink{Name: "cola", CategoryID: 1})
+	assert.Equal(t, ErrDrinkNotFound, err)
+}
+
+func TestPatchDrinkKeepsABV(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	stored := models.Drink{
+		ID:          1,
+		Name:        "whiskey",
+		CategoryID:  2,
+		Bottle:      700,
+		Cost:        models.Money{Amount: 3000, Currency: "EUR"},
+		ABV:         40,
+		AgeCategory: models.AgeCategorySpirits,
+	}
+
+	expected := stored
+	expected.Bottle = 500
+
+	drinkStorage := mock_service.NewMockDrinkStorage(c)
+	expectPatch(t, drinkStorage, stored, expected)
+
+	categoryStorage := mock_service.NewMockCategoryStorage(c)
+	categoryStorage.EXPECT().GetAll(gomock.Any()).Return([]models.Category{{ID: 2, Name: "Spirits"}}, nil)
+
+	service := NewDrinkService(drinkStorage, categoryStorage, 0.5)
+
+	bottle := 500
+	err := service.Patch(context.Background(), 1, models.DrinkPatch{Bottle: &bottle})
+	assert.Equal(t, nil, err)
+}
+
+func TestPatchDrinkABVDerivesAgeCategory(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	beerWine := models.AgeCategoryBeerWine
+
+	stored := models.Drink{
+		ID:          1,
+		Name:        "Cider",
+		CategoryID:  2,
+		Bottle:      500,
+		Cost:        models.Money{Amount: 300, Currency: "EUR"},
+		ABV:         0,
+		AgeCategory: models.AgeCategorySoft,
+	}
+
+	expected := stored
+	expected.ABV = 5
+	expected.AgeCategory = models.AgeCategoryBeerWine
+
+	drinkStorage := mock_service.NewMockDrinkStorage(c)
+	expectPatch(t, drinkStorage, stored, expected)
+
+	categoryStorage := mock_service.NewMockCategoryStorage(c)
+	categoryStorage.EXPECT().GetAll(gomock.Any()).Return([]models.Category{{ID: 2, Name: "Cider", AgeCategory: &beerWine}}, nil)
+
+	service := NewDrinkService(drinkStorage, categoryStorage, 0.5)
+
+	abv := 5.0
+	err := service.Patch(context.Background(), 1, models.DrinkPatch{ABV: &abv})
+	assert.Equal(t, nil, err)
+}
+
+func TestPatchDrinkNotFound(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	drinkStorage := mock_service.NewMockDrinkStorage(c)
+	drinkStorage.EXPECT().Patch(gomock.Any(), 2, gomock.Any()).Return(sql.ErrNoRows)
+
+	service := NewDrinkService(drinkStorage, nil, 0.5)
+
+	err := service.Patch(context.Background(), 2, models.DrinkPatch{})
+	assert.Equal(t, ErrDrinkNotFound, err)
+}
+
+// expectPatch applies the patch to a copy of stored and checks it ends up as expected.
+func expectPatch(t *testing.T, drinkStorage *mock_service.MockDrinkStorage, stored, expected models.Drink) {
+	t.Helper()
+
+	drinkStorage.EXPECT().Patch(gomock.Any(), stored.ID, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ int, apply func(drink *models.Drink) error) error {
+			drink := stored
+			if err := apply(&drink); err != nil {
+				return err
+			}
+
+			assert.Equal(t, expected, drink)
+
+			return nil
+		})
+}
@@ -0,0 +1,283 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/HeadGardener/coursework/internal/lib/auth"
+	"github.com/HeadGardener/coursework/internal/lib/hash"
+	"github.com/HeadGardener/coursework/internal/models"
+	"github.com/google/uuid"
+)
+
+const (
+	exportTokenLen = 32
+	exportTimeout  = 5 * time.Minute
+	manifestFile   = "manifest.json"
+)
+
+var (
+	ErrExportNotFound = errors.New("export not found or expired")
+	ErrExportNotReady = errors.New("export is not ready yet")
+	ErrExportFailed   = errors.New("export failed")
+)
+
+type ExportStorage interface {
+	SaveJob(ctx context.Context, job models.ExportJob) error
+	GetJob(ctx context.Context, jobID string) (models.ExportJob, bool, error)
+	SaveArchive(ctx context.Context, jobID string, archive []byte, ttl time.Duration) error
+	GetArchive(ctx context.Context, jobID string) ([]byte, bool, error)
+}
+
+// exportSection is one JSON file of the archive. Data kept about users by other
+// features (orders, favorites, audit entries) is exported by adding a section here.
+type exportSection struct {
+	file    string
+	collect func(ctx context.Context, userID string) (any, error)
+}
+
+type ExportService struct {
+	userStorage     UserStorage
+	sessionStorage  SessionStorage
+	apiKeyStorage   APIKeyStorage
+	identityStorage IdentityStorage
+	exportStorage   ExportStorage
+	linkTTL         time.Duration
+	sections        []exportSection
+}
+
+func NewExportService(userStorage UserStorage, sessionStorage SessionStorage, apiKeyStorage APIKeyStorage,
+	identityStorage IdentityStorage, exportStorage ExportStorage, linkTTL time.Duration) *ExportService {
+	s := &ExportService{
+		userStorage:     userStorage,
+		sessionStorage:  sessionStorage,
+		apiKeyStorage:   apiKeyStorage,
+		identityStorage: identityStorage,
+		exportStorage:   exportStorage,
+		linkTTL:         linkTTL,
+	}
+
+	s.sections = []exportSection{
+		{file: "profile.json", collect: s.collectProfile},
+		{file: "sessions.json", collect: s.collectSessions},
+		{file: "api_keys.json", collect: s.collectAPIKeys},
+		{file: "identities.json", collect: s.collectIdentities},
+	}
+
+	return s
+}
+
+// RequestExport starts building the archive of the user in the background and returns
+// the job together with the token its archive can be downloaded with.
+func (s *ExportService) RequestExport(ctx context.Context, userID string) (models.ExportInfo, string, error) {
+	if _, err := s.userStorage.GetByID(ctx, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ExportInfo{}, "", ErrUserNotFound
+		}
+
+		return models.ExportInfo{}, "", err
+	}
+
+	token, err := auth.GenerateRandomToken(exportTokenLen)
+	if err != nil {
+		return models.ExportInfo{}, "", err
+	}
+
+	now := time.Now()
+	job := models.ExportJob{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		TokenHash: hash.GetTokenHash(token),
+		Status:    models.ExportPending,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.linkTTL),
+	}
+
+	if err = s.exportStorage.SaveJob(ctx, job); err != nil {
+		return models.ExportInfo{}, "", err
+	}
+
+	go s.run(job)
+
+	return job.Info(), token, nil
+}
+
+// GetExport returns the job if it belongs to userID, so users can't probe each other's exports.
+func (s *ExportService) GetExport(ctx context.Context, userID, jobID string) (models.ExportInfo, error) {
+	job, ok, err := s.exportStorage.GetJob(ctx, jobID)
+	if err != nil {
+		return models.ExportInfo{}, err
+	}
+
+	if !ok || job.UserID != userID {
+		return models.ExportInfo{}, ErrExportNotFound
+	}
+
+	return job.Info(), nil
+}
+
+// Download returns the archive of the job. The token is all that is checked,
+// which lets the link be opened without signing in.
+func (s *ExportService) Download(ctx context.Context, jobID, token string) ([]byte, error) {
+	job, ok, err := s.exportStorage.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok || !hash.CompareTokenHash(job.TokenHash, token) {
+		return nil, ErrExportNotFound
+	}
+
+	switch job.Status {
+	case models.ExportPending:
+		return nil, ErrExportNotReady
+	case models.ExportFailed:
+		return nil, ErrExportFailed
+	}
+
+	archive, ok, err := s.exportStorage.GetArchive(ctx, job.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, ErrExportNotFound
+	}
+
+	return archive, nil
+}
+
+// Build collects everything stored about the user into a zip archive of JSON files.
+func (s *ExportService) Build(ctx context.Context, userID string) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	manifest := models.ExportManifest{
+		UserID:      userID,
+		GeneratedAt: time.Now().UTC(),
+		Files:       make([]string, 0, len(s.sections)),
+	}
+
+	for _, section := range s.sections {
+		data, err := section.collect(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = writeJSON(zw, section.file, data); err != nil {
+			return nil, err
+		}
+
+		manifest.Files = append(manifest.Files, section.file)
+	}
+
+	if err := writeJSON(zw, manifestFile, manifest); err != nil {
+		return nil, err
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// run builds the archive of job, it is detached from the request that started it.
+// Jobs interrupted by a restart stay pending until they expire.
+func (s *ExportService) run(job models.ExportJob) {
+	ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+	defer cancel()
+
+	archive, err := s.Build(ctx, job.UserID)
+	if err == nil {
+		err = s.exportStorage.SaveArchive(ctx, job.ID, archive, time.Until(job.ExpiresAt))
+	}
+
+	job.Status = models.ExportReady
+	job.CompletedAt = time.Now()
+
+	if err != nil {
+		log.Printf("[ERROR] failed to export data of user %s: %s", job.UserID, err.Error())
+
+		job.Status = models.ExportFailed
+		job.Error = err.Error()
+	}
+
+	if err = s.exportStorage.SaveJob(ctx, job); err != nil {
+		log.Printf("[ERROR] failed to save export job %s: %s", job.ID, err.Error())
+	}
+}
+
+func (s *ExportService) collectProfile(ctx context.Context, userID string) (any, error) {
+	user, err := s.userStorage.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	access, err := s.userStorage.GetAccess(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return user.Info(access.Roles), nil
+}
+
+func (s *ExportService) collectSessions(ctx context.Context, userID string) (any, error) {
+	sessions, err := s.sessionStorage.GetAllByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]models.SessionInfo, 0, len(sessions))
+	for i := range sessions {
+		infos = append(infos, sessions[i].Info(""))
+	}
+
+	return infos, nil
+}
+
+func (s *ExportService) collectAPIKeys(ctx context.Context, userID string) (any, error) {
+	keys, err := s.apiKeyStorage.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]models.APIKeyInfo, 0, len(keys))
+	for i := range keys {
+		infos = append(infos, keys[i].Info())
+	}
+
+	return infos, nil
+}
+
+func (s *ExportService) collectIdentities(ctx context.Context, userID string) (any, error) {
+	identities, err := s.identityStorage.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]models.IdentityInfo, 0, len(identities))
+	for i := range identities {
+		infos = append(infos, identities[i].Info())
+	}
+
+	return infos, nil
+}
+
+func writeJSON(zw *zip.Writer, name string, data any) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(data)
+}
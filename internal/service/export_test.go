@@ -0,0 +1,194 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/HeadGardener/coursework/internal/lib/hash"
+	"github.com/HeadGardener/coursework/internal/models"
+	mock_service "github.com/HeadGardener/coursework/internal/service/mocks"
+	"github.com/go-playground/assert/v2"
+	"go.uber.org/mock/gomock"
+)
+
+func TestBuildExport(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	user := &models.User{
+		ID:           "user",
+		Username:     "bob",
+		Name:         "Bob",
+		PasswordHash: "secret hash",
+		TOTPSecret:   "secret",
+	}
+
+	userStorage := mock_service.NewMockUserStorage(c)
+	userStorage.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil)
+	userStorage.EXPECT().GetAccess(gomock.Any(), user.ID).Return(models.Access{Roles: []string{models.RoleUser}}, nil)
+
+	sessionStorage := mock_service.NewMockSessionStorage(c)
+	sessionStorage.EXPECT().GetAllByUser(gomock.Any(), user.ID).Return([]models.Session{
+		{ID: "session", UserID: user.ID, RefreshToken: "refresh hash", IP: "127.0.0.1"},
+	}, nil)
+
+	apiKeyStorage := mock_service.NewMockAPIKeyStorage(c)
+	apiKeyStorage.EXPECT().List(gomock.Any(), user.ID).Return([]models.APIKey{
+		{ID: "key", UserID: user.ID, Name: "ci", Prefix: "0a1b2c3d", KeyHash: "key hash"},
+	}, nil)
+
+	identityStorage := mock_service.NewMockIdentityStorage(c)
+	identityStorage.EXPECT().ListByUser(gomock.Any(), user.ID).Return([]models.Identity{
+		{Provider: "corp", Subject: "bob-sub", UserID: user.ID},
+	}, nil)
+
+	service := NewExportService(userStorage, sessionStorage, apiKeyStorage, identityStorage, nil, time.Hour)
+
+	archive, err := service.Build(context.Background(), user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := make(map[string][]byte)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		files[f.Name], err = io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var manifest models.ExportManifest
+	if err = json.Unmarshal(files[manifestFile], &manifest); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []string{"profile.json", "sessions.json", "api_keys.json", "identities.json"},
+		manifest.Files)
+
+	var profile models.UserInfo
+	if err = json.Unmarshal(files["profile.json"], &profile); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "bob", profile.Username)
+	assert.Equal(t, []string{models.RoleUser}, profile.Roles)
+
+	var sessions []models.SessionInfo
+	if err = json.Unmarshal(files["sessions.json"], &sessions); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 1, len(sessions))
+	assert.Equal(t, "127.0.0.1", sessions[0].IP)
+
+	var keys []models.APIKeyInfo
+	if err = json.Unmarshal(files["api_keys.json"], &keys); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 1, len(keys))
+	assert.Equal(t, "ci", keys[0].Name)
+
+	var identities []models.IdentityInfo
+	if err = json.Unmarshal(files["identities.json"], &identities); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []models.IdentityInfo{{Provider: "corp", Subject: "bob-sub"}}, identities)
+
+	for _, content := range files {
+		for _, secret := range []string{user.PasswordHash, user.TOTPSecret, "refresh hash", "key hash"} {
+			assert.Equal(t, false, bytes.Contains(content, []byte(secret)))
+		}
+	}
+}
+
+func TestDownloadExport(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockExportStorage, job models.ExportJob)
+
+	const token = "token"
+
+	job := models.ExportJob{
+		ID:        "job",
+		UserID:    "user",
+		TokenHash: hash.GetTokenHash(token),
+		Status:    models.ExportReady,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	testTable := []struct {
+		name            string
+		token           string
+		mockBehavior    mockBehavior
+		expectedArchive []byte
+		expectedError   error
+	}{
+		{
+			name:  "ok",
+			token: token,
+			mockBehavior: func(s *mock_service.MockExportStorage, job models.ExportJob) {
+				s.EXPECT().GetJob(gomock.Any(), job.ID).Return(job, true, nil)
+				s.EXPECT().GetArchive(gomock.Any(), job.ID).Return([]byte("zip"), true, nil)
+			},
+			expectedArchive: []byte("zip"),
+		},
+		{
+			name:  "wrong token",
+			token: "wrong",
+			mockBehavior: func(s *mock_service.MockExportStorage, job models.ExportJob) {
+				s.EXPECT().GetJob(gomock.Any(), job.ID).Return(job, true, nil)
+			},
+			expectedError: ErrExportNotFound,
+		},
+		{
+			name:  "pending",
+			token: token,
+			mockBehavior: func(s *mock_service.MockExportStorage, job models.ExportJob) {
+				job.Status = models.ExportPending
+				s.EXPECT().GetJob(gomock.Any(), job.ID).Return(job, true, nil)
+			},
+			expectedError: ErrExportNotReady,
+		},
+		{
+			name:  "expired",
+			token: token,
+			mockBehavior: func(s *mock_service.MockExportStorage, job models.ExportJob) {
+				s.EXPECT().GetJob(gomock.Any(), job.ID).Return(models.ExportJob{}, false, nil)
+			},
+			expectedError: ErrExportNotFound,
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			exportStorage := mock_service.NewMockExportStorage(c)
+			tc.mockBehavior(exportStorage, job)
+
+			service := NewExportService(nil, nil, nil, nil, exportStorage, time.Hour)
+
+			archive, err := service.Download(context.Background(), job.ID, tc.token)
+
+			assert.Equal(t, tc.expectedError, err)
+			assert.Equal(t, tc.expectedArchive, archive)
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+const (
+	userFailuresThreshold = 5
+	ipFailuresThreshold   = 20
+	failuresWindow        = 15 * time.Minute
+	baseLockout           = 30 * time.Second
+	maxLockout            = time.Hour
+
+	usernameLimitPrefix = "user:"
+	ipLimitPrefix       = "ip:"
+)
+
+var (
+	ErrTooManyAttempts = errors.New("too many failed sign in attempts")
+)
+
+type LoginAttemptStorage interface {
+	GetLock(ctx context.Context, key string) (time.Duration, error)
+	RegisterFailure(ctx context.Context, key string, window time.Duration) (int64, error)
+	Lock(ctx context.Context, key string, d time.Duration) error
+	Reset(ctx context.Context, key string) error
+}
+
+// RetryAfterError tells the caller when the request may be retried.
+type RetryAfterError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *RetryAfterError) Error() string {
+	return fmt.Sprintf("%s: retry after %s", e.Err.Error(), e.RetryAfter.Round(time.Second))
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// UnlockUser lets an admin clear the lockout of an account before it expires.
+func (s *AuthService) UnlockUser(ctx context.Context, userID string) error {
+	user, err := s.userStorage.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return s.attemptStorage.Reset(ctx, usernameLimitPrefix+user.Username)
+}
+
+// checkLockout runs before the password is compared, so a locked account
+// doesn't cost a password hash comparison.
+func (s *AuthService) checkLockout(ctx context.Context, username, ip string) error {
+	for _, key := range []string{usernameLimitPrefix + username, ipLimitPrefix + ip} {
+		lock, err := s.attemptStorage.GetLock(ctx, key)
+		if err != nil {
+			return err
+		}
+
+		if lock > 0 {
+			return &RetryAfterError{
+				Err:        ErrTooManyAttempts,
+				RetryAfter: lock,
+			}
+		}
+	}
+
+	return nil
+}
+
+// registerFailure counts the failed attempt for both the username and the client IP
+// and locks whichever crossed its threshold, doubling the lock with each further failure.
+func (s *AuthService) registerFailure(ctx context.Context, username, ip string) error {
+	limits := []struct {
+		key       string
+		threshold int64
+	}{
+		{key: usernameLimitPrefix + username, threshold: userFailuresThreshold},
+		{key: ipLimitPrefix + ip, threshold: ipFailuresThreshold},
+	}
+
+	for _, limit := range limits {
+		failures, err := s.attemptStorage.RegisterFailure(ctx, limit.key, failuresWindow)
+		if err != nil {
+			return err
+		}
+
+		if failures < limit.threshold {
+			continue
+		}
+
+		lockout := lockoutDuration(failures - limit.threshold)
+		log.Printf("[WARN] security event: %d failed sign in attempts for %s, locked for %s", failures, limit.key, lockout)
+
+		if err = s.attemptStorage.Lock(ctx, limit.key, lockout); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func lockoutDuration(excess int64) time.Duration {
+	lockout := baseLockout
+	for i := int64(0); i < excess && lockout < maxLockout; i++ {
+		lockout *= 2
+	}
+
+	return min(lockout, maxLockout)
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/HeadGardener/coursework/internal/lib/hash"
+	"github.com/HeadGardener/coursework/internal/models"
+	mock_service "github.com/HeadGardener/coursework/internal/service/mocks"
+	"github.com/go-playground/assert/v2"
+	"go.uber.org/mock/gomock"
+)
+
+func TestLockoutDuration(t *testing.T) {
+	testTable := []struct {
+		excess   int64
+		expected time.Duration
+	}{
+		{excess: 0, expected: 30 * time.Second},
+		{excess: 1, expected: time.Minute},
+		{excess: 3, expected: 4 * time.Minute},
+		{excess: 100, expected: time.Hour},
+	}
+
+	for _, tc := range testTable {
+		assert.Equal(t, tc.expected, lockoutDuration(tc.excess))
+	}
+}
+
+func TestSignInLockout(t *testing.T) {
+	type mockBehavior func(a *mock_service.MockLoginAttemptStorage, u *mock_service.MockUserStorage)
+
+	client := models.ClientInfo{IP: "127.0.0.1"}
+
+	testTable := []struct {
+		name               string
+		mockBehavior       mockBehavior
+		expectedError      error
+		expectedRetryAfter time.Duration
+	}{
+		{
+			name: "username locked",
+			mockBehavior: func(a *mock_service.MockLoginAttemptStorage, u *mock_service.MockUserStorage) {
+				a.EXPECT().GetLock(gomock.Any(), "user:user").Return(time.Minute, nil)
+			},
+			expectedError:      ErrTooManyAttempts,
+			expectedRetryAfter: time.Minute,
+		},
+		{
+			name: "ip locked",
+			mockBehavior: func(a *mock_service.MockLoginAttemptStorage, u *mock_service.MockUserStorage) {
+				a.EXPECT().GetLock(gomock.Any(), "user:user").Return(time.Duration(0), nil)
+				a.EXPECT().GetLock(gomock.Any(), "ip:127.0.0.1").Return(time.Second, nil)
+			},
+			expectedError:      ErrTooManyAttempts,
+			expectedRetryAfter: time.Second,
+		},
+		{
+			name: "wrong password crosses threshold",
+			mockBehavior: func(a *mock_service.MockLoginAttemptStorage, u *mock_service.MockUserStorage) {
+				a.EXPECT().GetLock(gomock.Any(), gomock.Any()).Return(time.Duration(0), nil).Times(2)
+				u.EXPECT().GetByUsername(gomock.Any(), "user").Return(&models.User{
+					ID:           "1",
+					Username:     "user",
+					PasswordHash: "$2a$04$9Q2TgRbq5zqQXNTrjG1DVeAaXKpFzQmm5rNvUhE/eDdNoVnnMVKIG",
+				}, nil)
+				a.EXPECT().RegisterFailure(gomock.Any(), "user:user", failuresWindow).Return(int64(userFailuresThreshold), nil)
+				a.EXPECT().Lock(gomock.Any(), "user:user", baseLockout).Return(nil)
+				a.EXPECT().RegisterFailure(gomock.Any(), "ip:127.0.0.1", failuresWindow).Return(int64(1), nil)
+			},
+			expectedError: ErrInvalidPassword,
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			attemptStorage := mock_service.NewMockLoginAttemptStorage(c)
+			userStorage := mock_service.NewMockUserStorage(c)
+			tc.mockBehavior(attemptStorage, userStorage)
+
+			service := NewAuthService(nil, nil, userStorage, nil, nil, nil, nil, nil, attemptStorage,
+				hash.NewPasswordHasher(hash.DefaultArgon2Params), models.AgePolicies{})
+
+			_, err := service.SignIn(context.Background(), "user", "password", client)
+			assert.Equal(t, true, errors.Is(err, tc.expectedError))
+
+			var retryErr *RetryAfterError
+			if errors.As(err, &retryErr) {
+				assert.Equal(t, tc.expectedRetryAfter, retryErr.RetryAfter)
+			}
+		})
+	}
+}
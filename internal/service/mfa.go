@@ -0,0 +1,252 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/HeadGardener/coursework/internal/lib/auth"
+	"github.com/HeadGardener/coursework/internal/lib/hash"
+	"github.com/HeadGardener/coursework/internal/lib/totp"
+	"github.com/HeadGardener/coursework/internal/models"
+)
+
+const (
+	totpIssuer         = "coursework"
+	mfaChallengeTTL    = 5 * time.Minute
+	maxMFAAttempts     = 5
+	recoveryCodesCount = 10
+	recoveryCodeLen    = 5
+	mfaTokenLen        = 32
+)
+
+var (
+	ErrMFAAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnrolled    = errors.New("two-factor authentication is not enrolled")
+	ErrMFANotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrInvalidMFACode    = errors.New("invalid two-factor authentication code")
+)
+
+type RecoveryCodeStorage interface {
+	Replace(ctx context.Context, userID string, codeHashes []string) error
+	Use(ctx context.Context, userID, codeHash string) (bool, error)
+}
+
+type MFAStorage interface {
+	AddChallenge(ctx context.Context, tokenHash string, challenge models.MFAChallenge, ttl time.Duration) error
+	GetChallenge(ctx context.Context, tokenHash string) (models.MFAChallenge, error)
+	IncrChallengeAttempts(ctx context.Context, tokenHash string, ttl time.Duration) (int64, error)
+	DeleteChallenge(ctx context.Context, tokenHash string) error
+	MarkTOTPUsed(ctx context.Context, userID string, counter int64, ttl time.Duration) (bool, error)
+}
+
+// EnrollTOTP generates a new secret for the user. 2FA stays disabled until
+// the user proves the authenticator works with ConfirmTOTP.
+func (s *AuthService) EnrollTOTP(ctx context.Context, userID string) (models.TOTPEnrollment, error) {
+	user, err := s.userStorage.GetByID(ctx, userID)
+	if err != nil {
+		return models.TOTPEnrollment{}, err
+	}
+
+	if user.TOTPEnabled {
+		return models.TOTPEnrollment{}, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return models.TOTPEnrollment{}, err
+	}
+
+	if err = s.userStorage.SetTOTP(ctx, user.ID, secret, false); err != nil {
+		return models.TOTPEnrollment{}, err
+	}
+
+	return models.TOTPEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(totpIssuer, user.Username, secret),
+	}, nil
+}
+
+// ConfirmTOTP enables 2FA once the user enters a valid code and returns
+// recovery codes, which are shown only this once.
+func (s *AuthService) ConfirmTOTP(ctx context.Context, userID, code string) ([]string, error) {
+	user, err := s.userStorage.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TOTPEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	if user.TOTPSecret == "" {
+		return nil, ErrMFANotEnrolled
+	}
+
+	ok, err := s.verifyTOTP(ctx, user, code)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	codes, err := s.regenerateRecoveryCodes(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.userStorage.SetTOTP(ctx, user.ID, user.TOTPSecret, true); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func (s *AuthService) DisableTOTP(ctx context.Context, userID, code string) error {
+	user, err := s.userStorage.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !user.TOTPEnabled {
+		return ErrMFANotEnabled
+	}
+
+	ok, err := s.verifySecondFactor(ctx, user, code)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return ErrInvalidMFACode
+	}
+
+	if err = s.userStorage.SetTOTP(ctx, user.ID, "", false); err != nil {
+		return err
+	}
+
+	return s.recoveryCodeStorage.Replace(ctx, user.ID, nil)
+}
+
+// CompleteSignIn exchanges the token from the password step and a TOTP or recovery code
+// for a new session. The challenge is dropped after maxMFAAttempts wrong codes.
+func (s *AuthService) CompleteSignIn(ctx context.Context, mfaToken, code string, client models.ClientInfo) (models.Tokens, error) {
+	tokenHash := hash.GetTokenHash(mfaToken)
+
+	challenge, err := s.mfaStorage.GetChallenge(ctx, tokenHash)
+	if err != nil {
+		return models.Tokens{}, err
+	}
+
+	user, err := s.userStorage.GetByID(ctx, challenge.UserID)
+	if err != nil {
+		return models.Tokens{}, err
+	}
+
+	ok, err := s.verifySecondFactor(ctx, user, code)
+	if err != nil {
+		return models.Tokens{}, err
+	}
+
+	if !ok {
+		attempts, err := s.mfaStorage.IncrChallengeAttempts(ctx, tokenHash, mfaChallengeTTL)
+		if err != nil {
+			return models.Tokens{}, err
+		}
+
+		if attempts >= maxMFAAttempts {
+			if err = s.mfaStorage.DeleteChallenge(ctx, tokenHash); err != nil {
+				return models.Tokens{}, err
+			}
+		}
+
+		return models.Tokens{}, ErrInvalidMFACode
+	}
+
+	if err = s.mfaStorage.DeleteChallenge(ctx, tokenHash); err != nil {
+		return models.Tokens{}, err
+	}
+
+	// the account may have been disabled while the challenge was pending
+	if user.Disabled {
+		return models.Tokens{}, ErrUserDisabled
+	}
+
+	return s.createSession(ctx, user, client, true)
+}
+
+func (s *AuthService) createMFAChallenge(ctx context.Context, user *models.User,
+	client models.ClientInfo) (*models.MFARequired, error) {
+	token, err := auth.GenerateRandomToken(mfaTokenLen)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge := models.MFAChallenge{
+		UserID:    user.ID,
+		UserAgent: client.UserAgent,
+		IP:        client.IP,
+	}
+
+	if err = s.mfaStorage.AddChallenge(ctx, hash.GetTokenHash(token), challenge, mfaChallengeTTL); err != nil {
+		return nil, err
+	}
+
+	return &models.MFARequired{
+		MFAToken:  token,
+		ExpiresAt: time.Now().Add(mfaChallengeTTL),
+	}, nil
+}
+
+func (s *AuthService) verifySecondFactor(ctx context.Context, user *models.User, code string) (bool, error) {
+	ok, err := s.verifyTOTP(ctx, user, code)
+	if err != nil || ok {
+		return ok, err
+	}
+
+	return s.recoveryCodeStorage.Use(ctx, user.ID, hash.GetTokenHash(normalizeRecoveryCode(code)))
+}
+
+// verifyTOTP accepts each code only once, so a code seen over the shoulder can't be replayed.
+func (s *AuthService) verifyTOTP(ctx context.Context, user *models.User, code string) (bool, error) {
+	counter, ok := totp.Validate(user.TOTPSecret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+
+	return s.mfaStorage.MarkTOTPUsed(ctx, user.ID, counter, 3*totp.Period)
+}
+
+func (s *AuthService) regenerateRecoveryCodes(ctx context.Context, userID string) ([]string, error) {
+	var (
+		codes      = make([]string, recoveryCodesCount)
+		codeHashes = make([]string, recoveryCodesCount)
+		encoding   = base32.StdEncoding.WithPadding(base32.NoPadding)
+	)
+
+	for i := range codes {
+		b := make([]byte, recoveryCodeLen)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+
+		code := strings.ToLower(encoding.EncodeToString(b))
+		codes[i] = code[:4] + "-" + code[4:]
+		codeHashes[i] = hash.GetTokenHash(code)
+	}
+
+	if err := s.recoveryCodeStorage.Replace(ctx, userID, codeHashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
@@ -0,0 +1,144 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: category.go
+//
+// Generated by this command:
+//
+//	mockgen -source=category.go -destination=mocks/category.go -package=mock_service
+//
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	reflect "reflect"
+
+	models "github.com/HeadGardener/coursework/internal/models"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockCategoryStorage is a mock of CategoryStorage interface.
+type MockCategoryStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockCategoryStorageMockRecorder
+}
+
+// MockCategoryStorageMockRecorder is the mock recorder for MockCategoryStorage.
+type MockCategoryStorageMockRecorder struct {
+	mock *MockCategoryStorage
+}
+
+// NewMockCategoryStorage creates a new mock instance.
+func NewMockCategoryStorage(ctrl *gomock.Controller) *MockCategoryStorage {
+	mock := &MockCategoryStorage{ctrl: ctrl}
+	mock.recorder = &MockCategoryStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCategoryStorage) EXPECT() *MockCategoryStorageMockRecorder {
+	return m.recorder
+}
+
+// CountDrinks mocks base method.
+func (m *MockCategoryStorage) CountDrinks(ctx context.Context, filter models.DrinkFilter) (map[int]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountDrinks", ctx, filter)
+	ret0, _ := ret[0].(map[int]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountDrinks indicates an expected call of CountDrinks.
+func (mr *MockCategoryStorageMockRecorder) CountDrinks(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountDrinks", reflect.TypeOf((*MockCategoryStorage)(nil).CountDrinks), ctx, filter)
+}
+
+// Create mocks base method.
+func (m *MockCategoryStorage) Create(ctx context.Context, category *models.Category) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, category)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockCategoryStorageMockRecorder) Create(ctx, category any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCategoryStorage)(nil).Create), ctx, category)
+}
+
+// Delete mocks base method.
+func (m *MockCategoryStorage) Delete(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockCategoryStorageMockRecorder) Delete(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCategoryStorage)(nil).Delete), ctx, id)
+}
+
+// GetAll mocks base method.
+func (m *MockCategoryStorage) GetAll(ctx context.Context) ([]models.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx)
+	ret0, _ := ret[0].([]models.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockCategoryStorageMockRecorder) GetAll(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockCategoryStorage)(nil).GetAll), ctx)
+}
+
+// GetByID mocks base method.
+func (m *MockCategoryStorage) GetByID(ctx context.Context, id int) (models.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(models.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockCategoryStorageMockRecorder) GetByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockCategoryStorage)(nil).GetByID), ctx, id)
+}
+
+// GetByName mocks base method.
+func (m *MockCategoryStorage) GetByName(ctx context.Context, name string) (models.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByName", ctx, name)
+	ret0, _ := ret[0].(models.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByName indicates an expected call of GetByName.
+func (mr *MockCategoryStorageMockRecorder) GetByName(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByName", reflect.TypeOf((*MockCategoryStorage)(nil).GetByName), ctx, name)
+}
+
+// Update mocks base method.
+func (m *MockCategoryStorage) Update(ctx context.Context, id int, category *models.Category) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, id, category)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockCategoryStorageMockRecorder) Update(ctx, id, category any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockCategoryStorage)(nil).Update), ctx, id, category)
+}
@@ -0,0 +1,159 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: drink.go
+//
+// Generated by this command:
+//
+//	mockgen -source=drink.go -destination=mocks/drink.go -package=mock_service
+//
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	reflect "reflect"
+
+	models "github.com/HeadGardener/coursework/internal/models"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDrinkStorage is a mock of DrinkStorage interface.
+type MockDrinkStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockDrinkStorageMockRecorder
+}
+
+// MockDrinkStorageMockRecorder is the mock recorder for MockDrinkStorage.
+type MockDrinkStorageMockRecorder struct {
+	mock *MockDrinkStorage
+}
+
+// NewMockDrinkStorage creates a new mock instance.
+func NewMockDrinkStorage(ctrl *gomock.Controller) *MockDrinkStorage {
+	mock := &MockDrinkStorage{ctrl: ctrl}
+	mock.recorder = &MockDrinkStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDrinkStorage) EXPECT() *MockDrinkStorageMockRecorder {
+	return m.recorder
+}
+
+// Autocomplete mocks base method.
+func (m *MockDrinkStorage) Autocomplete(ctx context.Context, filter models.DrinkFilter, prefix string, limit int) ([]models.DrinkSuggestion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Autocomplete", ctx, filter, prefix, limit)
+	ret0, _ := ret[0].([]models.DrinkSuggestion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Autocomplete indicates an expected call of Autocomplete.
+func (mr *MockDrinkStorageMockRecorder) Autocomplete(ctx, filter, prefix, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Autocomplete", reflect.TypeOf((*MockDrinkStorage)(nil).Autocomplete), ctx, filter, prefix, limit)
+}
+
+// Create mocks base method.
+func (m *MockDrinkStorage) Create(ctx context.Context, drink *models.Drink) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, drink)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockDrinkStorageMockRecorder) Create(ctx, drink any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockDrinkStorage)(nil).Create), ctx, drink)
+}
+
+// Delete mocks base method.
+func (m *MockDrinkStorage) Delete(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockDrinkStorageMockRecorder) Delete(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockDrinkStorage)(nil).Delete), ctx, id)
+}
+
+// GetAll mocks base method.
+func (m *MockDrinkStorage) GetAll(ctx context.Context, filter models.DrinkFilter, query models.DrinkQuery) ([]models.Drink, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx, filter, query)
+	ret0, _ := ret[0].([]models.Drink)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockDrinkStorageMockRecorder) GetAll(ctx, filter, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockDrinkStorage)(nil).GetAll), ctx, filter, query)
+}
+
+// GetByID mocks base method.
+func (m *MockDrinkStorage) GetByID(ctx context.Context, id int, filter models.DrinkFilter) (models.Drink, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id, filter)
+	ret0, _ := ret[0].(models.Drink)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockDrinkStorageMockRecorder) GetByID(ctx, id, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockDrinkStorage)(nil).GetByID), ctx, id, filter)
+}
+
+// Patch mocks base method.
+func (m *MockDrinkStorage) Patch(ctx context.Context, id int, apply func(*models.Drink) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Patch", ctx, id, apply)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Patch indicates an expected call of Patch.
+func (mr *MockDrinkStorageMockRecorder) Patch(ctx, id, apply any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Patch", reflect.TypeOf((*MockDrinkStorage)(nil).Patch), ctx, id, apply)
+}
+
+// Search mocks base method.
+func (m *MockDrinkStorage) Search(ctx context.Context, filter models.DrinkFilter, search models.DrinkSearch) ([]models.Drink, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, filter, search)
+	ret0, _ := ret[0].([]models.Drink)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockDrinkStorageMockRecorder) Search(ctx, filter, search any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockDrinkStorage)(nil).Search), ctx, filter, search)
+}
+
+// Update mocks base method.
+func (m *MockDrinkStorage) Update(ctx context.Context, id int, drink *models.Drink) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, id, drink)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockDrinkStorageMockRecorder) Update(ctx, id, drink any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockDrinkStorage)(nil).Update), ctx, id, drink)
+}
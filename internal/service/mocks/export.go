@@ -0,0 +1,102 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: export.go
+//
+// Generated by this command:
+//
+//	mockgen -source=export.go -destination=mocks/export.go -package=mock_service
+//
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	models "github.com/HeadGardener/coursework/internal/models"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockExportStorage is a mock of ExportStorage interface.
+type MockExportStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockExportStorageMockRecorder
+}
+
+// MockExportStorageMockRecorder is the mock recorder for MockExportStorage.
+type MockExportStorageMockRecorder struct {
+	mock *MockExportStorage
+}
+
+// NewMockExportStorage creates a new mock instance.
+func NewMockExportStorage(ctrl *gomock.Controller) *MockExportStorage {
+	mock := &MockExportStorage{ctrl: ctrl}
+	mock.recorder = &MockExportStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExportStorage) EXPECT() *MockExportStorageMockRecorder {
+	return m.recorder
+}
+
+// GetArchive mocks base method.
+func (m *MockExportStorage) GetArchive(ctx context.Context, jobID string) ([]byte, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetArchive", ctx, jobID)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetArchive indicates an expected call of GetArchive.
+func (mr *MockExportStorageMockRecorder) GetArchive(ctx, jobID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetArchive", reflect.TypeOf((*MockExportStorage)(nil).GetArchive), ctx, jobID)
+}
+
+// GetJob mocks base method.
+func (m *MockExportStorage) GetJob(ctx context.Context, jobID string) (models.ExportJob, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJob", ctx, jobID)
+	ret0, _ := ret[0].(models.ExportJob)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetJob indicates an expected call of GetJob.
+func (mr *MockExportStorageMockRecorder) GetJob(ctx, jobID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJob", reflect.TypeOf((*MockExportStorage)(nil).GetJob), ctx, jobID)
+}
+
+// SaveArchive mocks base method.
+func (m *MockExportStorage) SaveArchive(ctx context.Context, jobID string, archive []byte, ttl time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveArchive", ctx, jobID, archive, ttl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveArchive indicates an expected call of SaveArchive.
+func (mr *MockExportStorageMockRecorder) SaveArchive(ctx, jobID, archive, ttl any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveArchive", reflect.TypeOf((*MockExportStorage)(nil).SaveArchive), ctx, jobID, archive, ttl)
+}
+
+// SaveJob mocks base method.
+func (m *MockExportStorage) SaveJob(ctx context.Context, job models.ExportJob) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveJob", ctx, job)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveJob indicates an expected call of SaveJob.
+func (mr *MockExportStorageMockRecorder) SaveJob(ctx, job any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveJob", reflect.TypeOf((*MockExportStorage)(nil).SaveJob), ctx, job)
+}
@@ -0,0 +1,99 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: lockout.go
+//
+// Generated by this command:
+//
+//	mockgen -source=lockout.go -destination=mocks/lockout.go -package=mock_service
+//
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockLoginAttemptStorage is a mock of LoginAttemptStorage interface.
+type MockLoginAttemptStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginAttemptStorageMockRecorder
+}
+
+// MockLoginAttemptStorageMockRecorder is the mock recorder for MockLoginAttemptStorage.
+type MockLoginAttemptStorageMockRecorder struct {
+	mock *MockLoginAttemptStorage
+}
+
+// NewMockLoginAttemptStorage creates a new mock instance.
+func NewMockLoginAttemptStorage(ctrl *gomock.Controller) *MockLoginAttemptStorage {
+	mock := &MockLoginAttemptStorage{ctrl: ctrl}
+	mock.recorder = &MockLoginAttemptStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoginAttemptStorage) EXPECT() *MockLoginAttemptStorageMockRecorder {
+	return m.recorder
+}
+
+// GetLock mocks base method.
+func (m *MockLoginAttemptStorage) GetLock(ctx context.Context, key string) (time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLock", ctx, key)
+	ret0, _ := ret[0].(time.Duration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLock indicates an expected call of GetLock.
+func (mr *MockLoginAttemptStorageMockRecorder) GetLock(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLock", reflect.TypeOf((*MockLoginAttemptStorage)(nil).GetLock), ctx, key)
+}
+
+// Lock mocks base method.
+func (m *MockLoginAttemptStorage) Lock(ctx context.Context, key string, d time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lock", ctx, key, d)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Lock indicates an expected call of Lock.
+func (mr *MockLoginAttemptStorageMockRecorder) Lock(ctx, key, d any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lock", reflect.TypeOf((*MockLoginAttemptStorage)(nil).Lock), ctx, key, d)
+}
+
+// RegisterFailure mocks base method.
+func (m *MockLoginAttemptStorage) RegisterFailure(ctx context.Context, key string, window time.Duration) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterFailure", ctx, key, window)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RegisterFailure indicates an expected call of RegisterFailure.
+func (mr *MockLoginAttemptStorageMockRecorder) RegisterFailure(ctx, key, window any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterFailure", reflect.TypeOf((*MockLoginAttemptStorage)(nil).RegisterFailure), ctx, key, window)
+}
+
+// Reset mocks base method.
+func (m *MockLoginAttemptStorage) Reset(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reset", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reset indicates an expected call of Reset.
+func (mr *MockLoginAttemptStorageMockRecorder) Reset(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockLoginAttemptStorage)(nil).Reset), ctx, key)
+}
@@ -0,0 +1,153 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: oauth.go
+//
+// Generated by this command:
+//
+//	mockgen -source=oauth.go -destination=mocks/oauth.go -package=mock_service
+//
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	models "github.com/HeadGardener/coursework/internal/models"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockOAuthClientStorage is a mock of OAuthClientStorage interface.
+type MockOAuthClientStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockOAuthClientStorageMockRecorder
+}
+
+// MockOAuthClientStorageMockRecorder is the mock recorder for MockOAuthClientStorage.
+type MockOAuthClientStorageMockRecorder struct {
+	mock *MockOAuthClientStorage
+}
+
+// NewMockOAuthClientStorage creates a new mock instance.
+func NewMockOAuthClientStorage(ctrl *gomock.Controller) *MockOAuthClientStorage {
+	mock := &MockOAuthClientStorage{ctrl: ctrl}
+	mock.recorder = &MockOAuthClientStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOAuthClientStorage) EXPECT() *MockOAuthClientStorageMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockOAuthClientStorage) Create(ctx context.Context, client *models.OAuthClient) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, client)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockOAuthClientStorageMockRecorder) Create(ctx, client any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockOAuthClientStorage)(nil).Create), ctx, client)
+}
+
+// Delete mocks base method.
+func (m *MockOAuthClientStorage) Delete(ctx context.Context, clientID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, clientID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockOAuthClientStorageMockRecorder) Delete(ctx, clientID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockOAuthClientStorage)(nil).Delete), ctx, clientID)
+}
+
+// GetByID mocks base method.
+func (m *MockOAuthClientStorage) GetByID(ctx context.Context, clientID string) (models.OAuthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, clientID)
+	ret0, _ := ret[0].(models.OAuthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockOAuthClientStorageMockRecorder) GetByID(ctx, clientID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockOAuthClientStorage)(nil).GetByID), ctx, clientID)
+}
+
+// List mocks base method.
+func (m *MockOAuthClientStorage) List(ctx context.Context) ([]models.OAuthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx)
+	ret0, _ := ret[0].([]models.OAuthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockOAuthClientStorageMockRecorder) List(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockOAuthClientStorage)(nil).List), ctx)
+}
+
+// MockAuthorizationCodeStorage is a mock of AuthorizationCodeStorage interface.
+type MockAuthorizationCodeStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuthorizationCodeStorageMockRecorder
+}
+
+// MockAuthorizationCodeStorageMockRecorder is the mock recorder for MockAuthorizationCodeStorage.
+type MockAuthorizationCodeStorageMockRecorder struct {
+	mock *MockAuthorizationCodeStorage
+}
+
+// NewMockAuthorizationCodeStorage creates a new mock instance.
+func NewMockAuthorizationCodeStorage(ctrl *gomock.Controller) *MockAuthorizationCodeStorage {
+	mock := &MockAuthorizationCodeStorage{ctrl: ctrl}
+	mock.recorder = &MockAuthorizationCodeStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuthorizationCodeStorage) EXPECT() *MockAuthorizationCodeStorageMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockAuthorizationCodeStorage) Add(ctx context.Context, codeHash string, code models.AuthorizationCode, ttl time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", ctx, codeHash, code, ttl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockAuthorizationCodeStorageMockRecorder) Add(ctx, codeHash, code, ttl any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockAuthorizationCodeStorage)(nil).Add), ctx, codeHash, code, ttl)
+}
+
+// Pop mocks base method.
+func (m *MockAuthorizationCodeStorage) Pop(ctx context.Context, codeHash string) (models.AuthorizationCode, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Pop", ctx, codeHash)
+	ret0, _ := ret[0].(models.AuthorizationCode)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Pop indicates an expected call of Pop.
+func (mr *MockAuthorizationCodeStorageMockRecorder) Pop(ctx, codeHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pop", reflect.TypeOf((*MockAuthorizationCodeStorage)(nil).Pop), ctx, codeHash)
+}
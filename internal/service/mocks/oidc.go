@@ -0,0 +1,235 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: oidc.go
+//
+// Generated by this command:
+//
+//	mockgen -source=oidc.go -destination=mocks/oidc.go -package=mock_service
+//
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	oidc "github.com/HeadGardener/coursework/internal/lib/oidc"
+	models "github.com/HeadGardener/coursework/internal/models"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockOIDCProvider is a mock of OIDCProvider interface.
+type MockOIDCProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockOIDCProviderMockRecorder
+}
+
+// MockOIDCProviderMockRecorder is the mock recorder for MockOIDCProvider.
+type MockOIDCProviderMockRecorder struct {
+	mock *MockOIDCProvider
+}
+
+// NewMockOIDCProvider creates a new mock instance.
+func NewMockOIDCProvider(ctrl *gomock.Controller) *MockOIDCProvider {
+	mock := &MockOIDCProvider{ctrl: ctrl}
+	mock.recorder = &MockOIDCProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOIDCProvider) EXPECT() *MockOIDCProviderMockRecorder {
+	return m.recorder
+}
+
+// AuthCodeURL mocks base method.
+func (m *MockOIDCProvider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthCodeURL", ctx, state, nonce, codeChallenge)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthCodeURL indicates an expected call of AuthCodeURL.
+func (mr *MockOIDCProviderMockRecorder) AuthCodeURL(ctx, state, nonce, codeChallenge any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthCodeURL", reflect.TypeOf((*MockOIDCProvider)(nil).AuthCodeURL), ctx, state, nonce, codeChallenge)
+}
+
+// AutoProvision mocks base method.
+func (m *MockOIDCProvider) AutoProvision() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AutoProvision")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// AutoProvision indicates an expected call of AutoProvision.
+func (mr *MockOIDCProviderMockRecorder) AutoProvision() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AutoProvision", reflect.TypeOf((*MockOIDCProvider)(nil).AutoProvision))
+}
+
+// Exchange mocks base method.
+func (m *MockOIDCProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*oidc.Claims, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Exchange", ctx, code, codeVerifier, nonce)
+	ret0, _ := ret[0].(*oidc.Claims)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Exchange indicates an expected call of Exchange.
+func (mr *MockOIDCProviderMockRecorder) Exchange(ctx, code, codeVerifier, nonce any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exchange", reflect.TypeOf((*MockOIDCProvider)(nil).Exchange), ctx, code, codeVerifier, nonce)
+}
+
+// TrustMFA mocks base method.
+func (m *MockOIDCProvider) TrustMFA() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TrustMFA")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// TrustMFA indicates an expected call of TrustMFA.
+func (mr *MockOIDCProviderMockRecorder) TrustMFA() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TrustMFA", reflect.TypeOf((*MockOIDCProvider)(nil).TrustMFA))
+}
+
+// MockIdentityStorage is a mock of IdentityStorage interface.
+type MockIdentityStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdentityStorageMockRecorder
+}
+
+// MockIdentityStorageMockRecorder is the mock recorder for MockIdentityStorage.
+type MockIdentityStorageMockRecorder struct {
+	mock *MockIdentityStorage
+}
+
+// NewMockIdentityStorage creates a new mock instance.
+func NewMockIdentityStorage(ctrl *gomock.Controller) *MockIdentityStorage {
+	mock := &MockIdentityStorage{ctrl: ctrl}
+	mock.recorder = &MockIdentityStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdentityStorage) EXPECT() *MockIdentityStorageMockRecorder {
+	return m.recorder
+}
+
+// CreateUser mocks base method.
+func (m *MockIdentityStorage) CreateUser(ctx context.Context, user *models.User, identity models.Identity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", ctx, user, identity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockIdentityStorageMockRecorder) CreateUser(ctx, user, identity any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockIdentityStorage)(nil).CreateUser), ctx, user, identity)
+}
+
+// Get mocks base method.
+func (m *MockIdentityStorage) Get(ctx context.Context, provider, subject string) (models.Identity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, provider, subject)
+	ret0, _ := ret[0].(models.Identity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockIdentityStorageMockRecorder) Get(ctx, provider, subject any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockIdentityStorage)(nil).Get), ctx, provider, subject)
+}
+
+// Link mocks base method.
+func (m *MockIdentityStorage) Link(ctx context.Context, identity models.Identity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Link", ctx, identity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Link indicates an expected call of Link.
+func (mr *MockIdentityStorageMockRecorder) Link(ctx, identity any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Link", reflect.TypeOf((*MockIdentityStorage)(nil).Link), ctx, identity)
+}
+
+// ListByUser mocks base method.
+func (m *MockIdentityStorage) ListByUser(ctx context.Context, userID string) ([]models.Identity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByUser", ctx, userID)
+	ret0, _ := ret[0].([]models.Identity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByUser indicates an expected call of ListByUser.
+func (mr *MockIdentityStorageMockRecorder) ListByUser(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByUser", reflect.TypeOf((*MockIdentityStorage)(nil).ListByUser), ctx, userID)
+}
+
+// MockOIDCLoginStorage is a mock of OIDCLoginStorage interface.
+type MockOIDCLoginStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockOIDCLoginStorageMockRecorder
+}
+
+// MockOIDCLoginStorageMockRecorder is the mock recorder for MockOIDCLoginStorage.
+type MockOIDCLoginStorageMockRecorder struct {
+	mock *MockOIDCLoginStorage
+}
+
+// NewMockOIDCLoginStorage creates a new mock instance.
+func NewMockOIDCLoginStorage(ctrl *gomock.Controller) *MockOIDCLoginStorage {
+	mock := &MockOIDCLoginStorage{ctrl: ctrl}
+	mock.recorder = &MockOIDCLoginStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOIDCLoginStorage) EXPECT() *MockOIDCLoginStorageMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockOIDCLoginStorage) Add(ctx context.Context, stateHash string, login models.OIDCLogin, ttl time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", ctx, stateHash, login, ttl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockOIDCLoginStorageMockRecorder) Add(ctx, stateHash, login, ttl any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockOIDCLoginStorage)(nil).Add), ctx, stateHash, login, ttl)
+}
+
+// Pop mocks base method.
+func (m *MockOIDCLoginStorage) Pop(ctx context.Context, stateHash string) (models.OIDCLogin, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Pop", ctx, stateHash)
+	ret0, _ := ret[0].(models.OIDCLogin)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Pop indicates an expected call of Pop.
+func (mr *MockOIDCLoginStorageMockRecorder) Pop(ctx, stateHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pop", reflect.TypeOf((*MockOIDCLoginStorage)(nil).Pop), ctx, stateHash)
+}
@@ -0,0 +1,603 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/HeadGardener/coursework/internal/lib/auth"
+	"github.com/HeadGardener/coursework/internal/lib/hash"
+	"github.com/HeadGardener/coursework/internal/models"
+	"github.com/google/uuid"
+)
+
+const (
+	authorizationCodeLen = 32
+	authorizationCodeTTL = 5 * time.Minute
+	clientSecretLen      = 32
+	pkceMethodS256       = "S256"
+	tokenTypeBearer      = "Bearer"
+	refreshTokenSep      = "."
+)
+
+// Errors of the OAuth endpoints, each of them maps to an error code of RFC 6749.
+var (
+	ErrClientNotFound       = errors.New("oauth client not found")
+	ErrInvalidOAuthRequest  = errors.New("invalid request")
+	ErrInvalidClient        = errors.New("client authentication failed")
+	ErrInvalidGrant         = errors.New("invalid authorization grant")
+	ErrUnauthorizedClient   = errors.New("client is not allowed to use this grant type")
+	ErrUnsupportedGrantType = errors.New("unsupported grant type")
+	ErrInvalidScope         = errors.New("invalid scope")
+)
+
+type OAuthClientStorage interface {
+	Create(ctx context.Context, client *models.OAuthClient) error
+	GetByID(ctx context.Context, clientID string) (models.OAuthClient, error)
+	List(ctx context.Context) ([]models.OAuthClient, error)
+	Delete(ctx context.Context, clientID string) error
+}
+
+type AuthorizationCodeStorage interface {
+	Add(ctx context.Context, codeHash string, code models.AuthorizationCode, ttl time.Duration) error
+	Pop(ctx context.Context, codeHash string) (models.AuthorizationCode, bool, error)
+}
+
+// OAuthService lets partner applications act on behalf of users. Its tokens are
+// the same access tokens and sessions AuthService issues, with the client and the
+// consented scopes attached.
+type OAuthService struct {
+	authService   *AuthService
+	clientStorage OAuthClientStorage
+	codeStorage   AuthorizationCodeStorage
+}
+
+func NewOAuthService(authService *AuthService, clientStorage OAuthClientStorage,
+	codeStorage AuthorizationCodeStorage) *OAuthService {
+	return &OAuthService{
+		authService:   authService,
+		clientStorage: clientStorage,
+		codeStorage:   codeStorage,
+	}
+}
+
+// RegisterClient stores a new client. Confidential clients get a secret, which
+// is returned only here.
+func (s *OAuthService) RegisterClient(ctx context.Context, name string, redirectURIs, grantTypes []string,
+	scopes []models.Permission, public bool) (models.RegisteredOAuthClient, error) {
+	client := models.OAuthClient{
+		ID:           uuid.NewString(),
+		Name:         name,
+		RedirectURIs: strings.Join(redirectURIs, " "),
+		GrantTypes:   strings.Join(grantTypes, " "),
+		Scopes:       models.JoinScopes(scopes),
+	}
+
+	var secret string
+	if !public {
+		var err error
+
+		secret, err = auth.GenerateRandomToken(clientSecretLen)
+		if err != nil {
+			return models.RegisteredOAuthClient{}, err
+		}
+
+		client.SecretHash = hash.GetTokenHash(secret)
+	}
+
+	if err := s.clientStorage.Create(ctx, &client); err != nil {
+		return models.RegisteredOAuthClient{}, err
+	}
+
+	return models.RegisteredOAuthClient{
+		OAuthClientInfo: client.Info(),
+		Secret:          secret,
+	}, nil
+}
+
+func (s *OAuthService) ListClients(ctx context.Context) ([]models.OAuthClientInfo, error) {
+	clients, err := s.clientStorage.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]models.OAuthClientInfo, 0, len(clients))
+	for i := range clients {
+		infos = append(infos, clients[i].Info())
+	}
+
+	return infos, nil
+}
+
+// DeleteClient removes the client. Its refresh tokens stop working at once, access
+// tokens already issued live until they expire.
+func (s *OAuthService) DeleteClient(ctx context.Context, clientID string) error {
+	err := s.clientStorage.Delete(ctx, clientID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrClientNotFound
+	}
+
+	return err
+}
+
+// PrepareAuthorization checks the request and describes it for the consent screen.
+func (s *OAuthService) PrepareAuthorization(ctx context.Context,
+	req models.AuthorizationRequest) (models.Consent, error) {
+	client, req, err := s.checkAuthorization(ctx, req)
+	if err != nil {
+		return models.Consent{}, err
+	}
+
+	return models.Consent{
+		ClientID:    client.ID,
+		ClientName:  client.Name,
+		Scopes:      req.Scopes,
+		RedirectURI: req.RedirectURI,
+		State:       req.State,
+	}, nil
+}
+
+// Authorize records the decision of the user and returns where to send them back:
+// the redirect URI of the client with either a code or an access_denied error.
+func (s *OAuthService) Authorize(ctx context.Context, userID string, req models.AuthorizationRequest,
+	approved bool) (string, error) {
+	redirectURISent := req.RedirectURI != ""
+
+	_, req, err := s.checkAuthorization(ctx, req)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	if req.State != "" {
+		params.Set("state", req.State)
+	}
+
+	if !approved {
+		params.Set("error", "access_denied")
+		return withQuery(req.RedirectURI, params)
+	}
+
+	code, err := auth.GenerateRandomToken(authorizationCodeLen)
+	if err != nil {
+		return "", err
+	}
+
+	if err = s.codeStorage.Add(ctx, hash.GetTokenHash(code), models.AuthorizationCode{
+		ClientID:        req.ClientID,
+		UserID:          userID,
+		RedirectURI:     req.RedirectURI,
+		RedirectURISent: redirectURISent,
+		Scopes:          req.Scopes,
+		CodeChallenge:   req.CodeChallenge,
+	}, authorizationCodeTTL); err != nil {
+		return "", err
+	}
+
+	params.Set("code", code)
+
+	return withQuery(req.RedirectURI, params)
+}
+
+// Exchange handles the token endpoint.
+func (s *OAuthService) Exchange(ctx context.Context, req models.TokenRequest) (models.OAuthToken, error) {
+	client, err := s.authenticateClient(ctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return models.OAuthToken{}, err
+	}
+
+	switch req.GrantType {
+	case models.GrantAuthorizationCode, models.GrantClientCredentials, models.GrantRefreshToken:
+	default:
+		return models.OAuthToken{}, fmt.Errorf("%w: %s", ErrUnsupportedGrantType, req.GrantType)
+	}
+
+	if !client.AllowsGrant(req.GrantType) {
+		return models.OAuthToken{}, ErrUnauthorizedClient
+	}
+
+	switch req.GrantType {
+	case models.GrantAuthorizationCode:
+		return s.exchangeCode(ctx, client, req)
+	case models.GrantClientCredentials:
+		return s.exchangeClientCredentials(client, req)
+	default:
+		return s.exchangeRefreshToken(ctx, client, req)
+	}
+}
+
+// Introspect reports whether token is active, as defined by RFC 7662. Only
+// confidential clients may introspect tokens. Refresh tokens can be told apart from
+// access tokens by their form, so the token type hint isn't needed.
+func (s *OAuthService) Introspect(ctx context.Context, clientID, clientSecret, token string) (models.Introspection, error) {
+	client, err := s.authenticateClient(ctx, clientID, clientSecret)
+	if err != nil {
+		return models.Introspection{}, err
+	}
+
+	if client.Public() {
+		return models.Introspection{}, ErrInvalidClient
+	}
+
+	if session := s.findSession(ctx, token); session != nil && session.ExpiresAt.After(time.Now()) {
+		return models.Introspection{
+			Active:    true,
+			Scope:     models.JoinScopes(session.Scopes),
+			ClientID:  session.ClientID,
+			Subject:   session.UserID,
+			TokenType: models.TokenTypeRefresh,
+			ExpiresAt: session.ExpiresAt.Unix(),
+		}, nil
+	}
+
+	userAttr, err := s.authService.ParseAccessToken(ctx, token)
+	if err != nil {
+		return models.Introspection{Active: false}, nil //nolint:nilerr
+	}
+
+	return models.Introspection{
+		Active:    true,
+		Scope:     models.JoinScopes(userAttr.Permissions),
+		ClientID:  userAttr.ClientID,
+		Subject:   userAttr.ID,
+		TokenType: models.TokenTypeAccess,
+		ExpiresAt: userAttr.ExpiresAt.Unix(),
+		TokenID:   userAttr.TokenID,
+	}, nil
+}
+
+// Revoke revokes a token issued to the client, as defined by RFC 7009. Revoking
+// a refresh token ends its session with all of its access tokens. Unknown tokens
+// and tokens of other clients are ignored.
+func (s *OAuthService) Revoke(ctx context.Context, clientID, clientSecret, token string) error {
+	client, err := s.authenticateClient(ctx, clientID, clientSecret)
+	if err != nil {
+		return err
+	}
+
+	if session := s.findSession(ctx, token); session != nil {
+		if session.ClientID != client.ID {
+			return nil
+		}
+
+		return s.authService.deleteSession(ctx, session.UserID, session.ID)
+	}
+
+	userAttr, err := s.authService.tokenManager.ParseAccessToken(token)
+	if err != nil || userAttr.ClientID != client.ID {
+		return nil //nolint:nilerr
+	}
+
+	return s.authService.deny(ctx, userAttr.TokenID, time.Until(userAttr.ExpiresAt))
+}
+
+// checkAuthorization validates the request against the registered client. The redirect
+// URI may be omitted only when the client has exactly one.
+func (s *OAuthService) checkAuthorization(ctx context.Context,
+	req models.AuthorizationRequest) (models.OAuthClient, models.AuthorizationRequest, error) {
+	client, err := s.clientStorage.GetByID(ctx, req.ClientID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.OAuthClient{}, req, ErrInvalidClient
+		}
+
+		return models.OAuthClient{}, req, err
+	}
+
+	if !client.AllowsGrant(models.GrantAuthorizationCode) {
+		return models.OAuthClient{}, req, ErrUnauthorizedClient
+	}
+
+	redirectURIs := client.RedirectURIList()
+	if req.RedirectURI == "" && len(redirectURIs) == 1 {
+		req.RedirectURI = redirectURIs[0]
+	}
+
+	if !slices.Contains(redirectURIs, req.RedirectURI) {
+		return models.OAuthClient{}, req, fmt.Errorf("%w: redirect_uri is not registered", ErrInvalidOAuthRequest)
+	}
+
+	if req.CodeChallenge == "" || req.CodeChallengeMethod != pkceMethodS256 {
+		return models.OAuthClient{}, req, fmt.Errorf("%w: code_challenge with S256 method is required",
+			ErrInvalidOAuthRequest)
+	}
+
+	if err = checkScopes(req.Scopes, client.ScopeList()); err != nil {
+		return models.OAuthClient{}, req, err
+	}
+
+	return client, req, nil
+}
+
+func (s *OAuthService) exchangeCode(ctx context.Context, client models.OAuthClient,
+	req models.TokenRequest) (models.OAuthToken, error) {
+	code, ok, err := s.codeStorage.Pop(ctx, hash.GetTokenHash(req.Code))
+	if err != nil {
+		return models.OAuthToken{}, err
+	}
+
+	if !ok || code.ClientID != client.ID {
+		return models.OAuthToken{}, ErrInvalidGrant
+	}
+
+	// RFC 6749 section 4.1.3: redirect_uri must match if it was sent with the
+	// authorization request, otherwise it may be left out.
+	if (code.RedirectURISent || req.RedirectURI != "") && code.RedirectURI != req.RedirectURI {
+		return models.OAuthToken{}, fmt.Errorf("%w: redirect_uri doesn't match", ErrInvalidGrant)
+	}
+
+	if !verifyCodeChallenge(code.CodeChallenge, req.CodeVerifier) {
+		return models.OAuthToken{}, fmt.Errorf("%w: code_verifier doesn't match", ErrInvalidGrant)
+	}
+
+	user, err := s.authService.userStorage.GetByID(ctx, code.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.OAuthToken{}, ErrInvalidGrant
+		}
+
+		return models.OAuthToken{}, err
+	}
+
+	if user.Disabled {
+		return models.OAuthToken{}, ErrInvalidGrant
+	}
+
+	now := time.Now()
+	session := models.Session{
+		ID:          uuid.NewString(),
+		UserID:      user.ID,
+		ClientID:    client.ID,
+		Scopes:      code.Scopes,
+		CreatedAt:   now,
+		RefreshedAt: now,
+	}
+
+	return s.issueTokens(ctx, &session, user)
+}
+
+// exchangeClientCredentials issues a token for the client itself, it isn't bound
+// to any user or session and can't be refreshed.
+func (s *OAuthService) exchangeClientCredentials(client models.OAuthClient,
+	req models.TokenRequest) (models.OAuthToken, error) {
+	scopes := req.Scopes
+	if len(scopes) == 0 {
+		scopes = client.ScopeList()
+	}
+
+	if err := checkScopes(scopes, client.ScopeList()); err != nil {
+		return models.OAuthToken{}, err
+	}
+
+	accessToken, err := s.authService.tokenManager.GenerateAccessToken(auth.UserAttributes{
+		ClientID:    client.ID,
+		Permissions: scopes,
+	})
+	if err != nil {
+		return models.OAuthToken{}, err
+	}
+
+	return models.OAuthToken{
+		AccessToken: accessToken,
+		TokenType:   tokenTypeBearer,
+		ExpiresIn:   int(s.authService.tokenManager.GetAccessTokenTTL().Seconds()),
+		Scope:       models.JoinScopes(scopes),
+	}, nil
+}
+
+// exchangeRefreshToken rotates the refresh token the same way AuthService.Refresh
+// does, a reused token revokes the session. The scope may only be narrowed.
+func (s *OAuthService) exchangeRefreshToken(ctx context.Context, client models.OAuthClient,
+	req models.TokenRequest) (models.OAuthToken, error) {
+	tokenHash := hash.GetTokenHash(req.RefreshToken)
+
+	used, ok, err := s.authService.sessionStorage.GetUsedRefreshToken(ctx, tokenHash)
+	if err != nil {
+		return models.OAuthToken{}, err
+	}
+
+	if ok {
+		return models.OAuthToken{}, s.authService.revokeTokenFamily(ctx, used)
+	}
+
+	session := s.findSession(ctx, req.RefreshToken)
+	if session == nil || session.ClientID != client.ID || session.ExpiresAt.Before(time.Now()) {
+		return models.OAuthToken{}, ErrInvalidGrant
+	}
+
+	if len(req.Scopes) != 0 {
+		if err = checkScopes(req.Scopes, session.Scopes); err != nil {
+			return models.OAuthToken{}, err
+		}
+
+		session.Scopes = req.Scopes
+	}
+
+	used = models.UsedRefreshToken{
+		TokenID:   session.TokenID,
+		SessionID: session.ID,
+		UserID:    session.UserID,
+		UsedAt:    time.Now(),
+	}
+
+	ok, err = s.authService.sessionStorage.MarkRefreshTokenUsed(ctx, tokenHash, used,
+		s.authService.tokenManager.GetRefreshTokenTTL())
+	if err != nil {
+		return models.OAuthToken{}, err
+	}
+
+	if !ok {
+		return models.OAuthToken{}, s.authService.revokeTokenFamily(ctx, used)
+	}
+
+	user, err := s.authService.userStorage.GetByID(ctx, session.UserID)
+	if err != nil {
+		return models.OAuthToken{}, err
+	}
+
+	if user.Disabled {
+		if err = s.authService.deleteSession(ctx, user.ID, session.ID); err != nil {
+			return models.OAuthToken{}, err
+		}
+
+		return models.OAuthToken{}, ErrInvalidGrant
+	}
+
+	session.ParentTokenID = session.TokenID
+	session.RefreshedAt = time.Now()
+
+	return s.issueTokens(ctx, session, user)
+}
+
+// issueTokens is AuthService.issueTokens for client sessions: the access token only
+// gets the consented scopes the user still has, and the refresh token starts with
+// the session ID, since clients present it without an access token.
+func (s *OAuthService) issueTokens(ctx context.Context, session *models.Session,
+	user *models.User) (models.OAuthToken, error) {
+	access, err := s.authService.userStorage.GetAccess(ctx, user.ID)
+	if err != nil {
+		return models.OAuthToken{}, err
+	}
+
+	var permissions []models.Permission
+	for _, scope := range session.Scopes {
+		if access.HasPermission(scope) {
+			permissions = append(permissions, scope)
+		}
+	}
+
+	tm := s.authService.tokenManager
+
+	accessToken, err := tm.GenerateAccessToken(auth.UserAttributes{
+		ID:          user.ID,
+		SessionID:   session.ID,
+		ClientID:    session.ClientID,
+		Permissions: permissions,
+		AdultSince:  s.authService.agePolicies.For(user.Region).AdultSince(user.BirthDate),
+	})
+	if err != nil {
+		return models.OAuthToken{}, err
+	}
+
+	secret, err := tm.GenerateRefreshToken()
+	if err != nil {
+		return models.OAuthToken{}, err
+	}
+
+	refreshToken := session.ID + refreshTokenSep + secret
+
+	session.TokenID = uuid.NewString()
+	session.RefreshToken = hash.GetTokenHash(refreshToken)
+	session.ExpiresAt = time.Now().Add(tm.GetRefreshTokenTTL())
+
+	if err = s.authService.sessionStorage.Add(ctx, *session, tm.GetRefreshTokenTTL()); err != nil {
+		return models.OAuthToken{}, err
+	}
+
+	return models.OAuthToken{
+		AccessToken:  accessToken,
+		TokenType:    tokenTypeBearer,
+		ExpiresIn:    int(tm.GetAccessTokenTTL().Seconds()),
+		RefreshToken: refreshToken,
+		Scope:        models.JoinScopes(permissions),
+	}, nil
+}
+
+// authenticateClient checks the client secret, public clients must not send one.
+func (s *OAuthService) authenticateClient(ctx context.Context, clientID, clientSecret string) (models.OAuthClient, error) {
+	if clientID == "" {
+		return models.OAuthClient{}, ErrInvalidClient
+	}
+
+	client, err := s.clientStorage.GetByID(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.OAuthClient{}, ErrInvalidClient
+		}
+
+		return models.OAuthClient{}, err
+	}
+
+	if client.Public() {
+		if clientSecret != "" {
+			return models.OAuthClient{}, ErrInvalidClient
+		}
+
+		return client, nil
+	}
+
+	if !hash.CompareTokenHash(client.SecretHash, clientSecret) {
+		return models.OAuthClient{}, ErrInvalidClient
+	}
+
+	return client, nil
+}
+
+// findSession returns the session the refresh token belongs to, or nil if there is none.
+func (s *OAuthService) findSession(ctx context.Context, refreshToken string) *models.Session {
+	sessionID, _, ok := strings.Cut(refreshToken, refreshTokenSep)
+	if !ok {
+		return nil
+	}
+
+	if _, err := uuid.Parse(sessionID); err != nil {
+		return nil
+	}
+
+	// a missing session and a failed lookup both leave the token unusable
+	session, err := s.authService.sessionStorage.Get(ctx, sessionID)
+	if err != nil || !hash.CompareTokenHash(session.RefreshToken, refreshToken) {
+		return nil
+	}
+
+	return &session
+}
+
+func checkScopes(requested, allowed []models.Permission) error {
+	for _, scope := range requested {
+		if !slices.Contains(allowed, scope) {
+			return fmt.Errorf("%w: %s", ErrInvalidScope, scope)
+		}
+	}
+
+	return nil
+}
+
+// verifyCodeChallenge checks the PKCE verifier against the S256 challenge of RFC 7636.
+func verifyCodeChallenge(challenge, verifier string) bool {
+	if verifier == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(challenge), []byte(codeChallengeS256(verifier))) == 1
+}
+
+func codeChallengeS256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func withQuery(rawURL string, params url.Values) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	query := u.Query()
+	for key := range params {
+		query.Set(key, params.Get(key))
+	}
+
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
@@ -0,0 +1,409 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HeadGardener/coursework/internal/config"
+	"github.com/HeadGardener/coursework/internal/lib/auth"
+	"github.com/HeadGardener/coursework/internal/lib/hash"
+	"github.com/HeadGardener/coursework/internal/models"
+	mock_service "github.com/HeadGardener/coursework/internal/service/mocks"
+	"github.com/go-playground/assert/v2"
+	"go.uber.org/mock/gomock"
+)
+
+const (
+	testRedirectURI  = "https://partner.example/callback"
+	testCodeVerifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+)
+
+var testClient = models.OAuthClient{
+	ID:           "8c0d7f4e-0d1a-4c36-a4b5-0e6f0c6f1a11",
+	Name:         "partner",
+	RedirectURIs: testRedirectURI,
+	GrantTypes:   "authorization_code refresh_token",
+	Scopes:       "drinks:create drinks:update",
+}
+
+func newTestTokenManager(t *testing.T) *auth.TokenManager {
+	tokenManager, err := auth.NewTokenManager(&config.TokensConfig{
+		SecretKey:       "secret",
+		AccessTokenTTL:  time.Minute,
+		InitialLen:      32,
+		RefreshTokenTTL: time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return tokenManager
+}
+
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func TestAuthorize(t *testing.T) {
+	type mockBehavior func(c *mock_service.MockOAuthClientStorage, a *mock_service.MockAuthorizationCodeStorage)
+
+	request := models.AuthorizationRequest{
+		ClientID:            testClient.ID,
+		Scopes:              []models.Permission{models.PermDrinksCreate},
+		State:               "xyz",
+		CodeChallenge:       codeChallenge(testCodeVerifier),
+		CodeChallengeMethod: "S256",
+	}
+
+	testTable := []struct {
+		name          string
+		request       func(r models.AuthorizationRequest) models.AuthorizationRequest
+		approved      bool
+		mockBehavior  mockBehavior
+		expectedQuery url.Values
+		expectedError error
+	}{
+		{
+			name:     "approved",
+			request:  func(r models.AuthorizationRequest) models.AuthorizationRequest { return r },
+			approved: true,
+			mockBehavior: func(c *mock_service.MockOAuthClientStorage, a *mock_service.MockAuthorizationCodeStorage) {
+				c.EXPECT().GetByID(gomock.Any(), testClient.ID).Return(testClient, nil)
+				a.EXPECT().Add(gomock.Any(), gomock.Any(), models.AuthorizationCode{
+					ClientID:      testClient.ID,
+					UserID:        "user",
+					RedirectURI:   testRedirectURI,
+					Scopes:        []models.Permission{models.PermDrinksCreate},
+					CodeChallenge: codeChallenge(testCodeVerifier),
+				}, authorizationCodeTTL).Return(nil)
+			},
+			expectedQuery: url.Values{"state": {"xyz"}},
+		},
+		{
+			name: "approved with redirect uri",
+			request: func(r models.AuthorizationRequest) models.AuthorizationRequest {
+				r.RedirectURI = testRedirectURI
+				return r
+			},
+			approved: true,
+			mockBehavior: func(c *mock_service.MockOAuthClientStorage, a *mock_service.MockAuthorizationCodeStorage) {
+				c.EXPECT().GetByID(gomock.Any(), testClient.ID).Return(testClient, nil)
+				a.EXPECT().Add(gomock.Any(), gomock.Any(), models.AuthorizationCode{
+					ClientID:        testClient.ID,
+					UserID:          "user",
+					RedirectURI:     testRedirectURI,
+					RedirectURISent: true,
+					Scopes:          []models.Permission{models.PermDrinksCreate},
+					CodeChallenge:   codeChallenge(testCodeVerifier),
+				}, authorizationCodeTTL).Return(nil)
+			},
+			expectedQuery: url.Values{"state": {"xyz"}},
+		},
+		{
+			name:    "denied",
+			request: func(r models.AuthorizationRequest) models.AuthorizationRequest { return r },
+			mockBehavior: func(c *mock_service.MockOAuthClientStorage, a *mock_service.MockAuthorizationCodeStorage) {
+				c.EXPECT().GetByID(gomock.Any(), testClient.ID).Return(testClient, nil)
+			},
+			expectedQuery: url.Values{"state": {"xyz"}, "error": {"access_denied"}},
+		},
+		{
+			name: "unregistered redirect uri",
+			request: func(r models.AuthorizationRequest) models.AuthorizationRequest {
+				r.RedirectURI = "https://attacker.example/callback"
+				return r
+			},
+			approved: true,
+			mockBehavior: func(c *mock_service.MockOAuthClientStorage, a *mock_service.MockAuthorizationCodeStorage) {
+				c.EXPECT().GetByID(gomock.Any(), testClient.ID).Return(testClient, nil)
+			},
+			expectedError: ErrInvalidOAuthRequest,
+		},
+		{
+			name: "no pkce",
+			request: func(r models.AuthorizationRequest) models.AuthorizationRequest {
+				r.CodeChallenge = ""
+				return r
+			},
+			approved: true,
+			mockBehavior: func(c *mock_service.MockOAuthClientStorage, a *mock_service.MockAuthorizationCodeStorage) {
+				c.EXPECT().GetByID(gomock.Any(), testClient.ID).Return(testClient, nil)
+			},
+			expectedError: ErrInvalidOAuthRequest,
+		},
+		{
+			name: "scope not allowed",
+			request: func(r models.AuthorizationRequest) models.AuthorizationRequest {
+				r.Scopes = []models.Permission{models.PermUsersManage}
+				return r
+			},
+			approved: true,
+			mockBehavior: func(c *mock_service.MockOAuthClientStorage, a *mock_service.MockAuthorizationCodeStorage) {
+				c.EXPECT().GetByID(gomock.Any(), testClient.ID).Return(testClient, nil)
+			},
+			expectedError: ErrInvalidScope,
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			clientStorage := mock_service.NewMockOAuthClientStorage(c)
+			codeStorage := mock_service.NewMockAuthorizationCodeStorage(c)
+			tc.mockBehavior(clientStorage, codeStorage)
+
+			service := NewOAuthService(nil, clientStorage, codeStorage)
+
+			redirectTo, err := service.Authorize(context.Background(), "user", tc.request(request), tc.approved)
+			if tc.expectedError != nil {
+				assert.Equal(t, true, errors.Is(err, tc.expectedError))
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			u, err := url.Parse(redirectTo)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			query := u.Query()
+			if tc.approved {
+				assert.NotEqual(t, "", query.Get("code"))
+				query.Del("code")
+			}
+
+			assert.Equal(t, testRedirectURI, strings.Split(redirectTo, "?")[0])
+			assert.Equal(t, tc.expectedQuery, query)
+		})
+	}
+}
+
+func TestExchangeAuthorizationCode(t *testing.T) {
+	type mockBehavior func(a *mock_service.MockAuthorizationCodeStorage, u *mock_service.MockUserStorage,
+		s *mock_service.MockSessionStorage)
+
+	const code = "code"
+
+	user := &models.User{ID: "user"}
+
+	storedCode := models.AuthorizationCode{
+		ClientID:      testClient.ID,
+		UserID:        user.ID,
+		RedirectURI:   testRedirectURI,
+		Scopes:        []models.Permission{models.PermDrinksCreate, models.PermDrinksUpdate},
+		CodeChallenge: codeChallenge(testCodeVerifier),
+	}
+
+	codeWithRedirectURI := storedCode
+	codeWithRedirectURI.RedirectURISent = true
+
+	testTable := []struct {
+		name          string
+		codeVerifier  string
+		redirectURI   string
+		mockBehavior  mockBehavior
+		expectedScope string
+		expectedError error
+	}{
+		{
+			name:         "ok",
+			codeVerifier: testCodeVerifier,
+			redirectURI:  testRedirectURI,
+			mockBehavior: func(a *mock_service.MockAuthorizationCodeStorage, u *mock_service.MockUserStorage,
+				s *mock_service.MockSessionStorage) {
+				a.EXPECT().Pop(gomock.Any(), hash.GetTokenHash(code)).Return(storedCode, true, nil)
+				u.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil)
+				u.EXPECT().GetAccess(gomock.Any(), user.ID).Return(models.Access{
+					Permissions: []models.Permission{models.PermDrinksCreate},
+				}, nil)
+				s.EXPECT().Add(gomock.Any(), gomock.Any(), time.Hour).DoAndReturn(
+					func(_ context.Context, session models.Session, _ time.Duration) error {
+						assert.Equal(t, testClient.ID, session.ClientID)
+						assert.Equal(t, storedCode.Scopes, session.Scopes)
+
+						return nil
+					})
+			},
+			expectedScope: "drinks:create",
+		},
+		{
+			name:         "redirect uri left out in both requests",
+			codeVerifier: testCodeVerifier,
+			mockBehavior: func(a *mock_service.MockAuthorizationCodeStorage, u *mock_service.MockUserStorage,
+				s *mock_service.MockSessionStorage) {
+				a.EXPECT().Pop(gomock.Any(), hash.GetTokenHash(code)).Return(storedCode, true, nil)
+				u.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil)
+				u.EXPECT().GetAccess(gomock.Any(), user.ID).Return(models.Access{
+					Permissions: []models.Permission{models.PermDrinksCreate},
+				}, nil)
+				s.EXPECT().Add(gomock.Any(), gomock.Any(), time.Hour).Return(nil)
+			},
+			expectedScope: "drinks:create",
+		},
+		{
+			name:         "sent redirect uri left out",
+			codeVerifier: testCodeVerifier,
+			mockBehavior: func(a *mock_service.MockAuthorizationCodeStorage, u *mock_service.MockUserStorage,
+				s *mock_service.MockSessionStorage) {
+				a.EXPECT().Pop(gomock.Any(), hash.GetTokenHash(code)).Return(codeWithRedirectURI, true, nil)
+			},
+			expectedError: ErrInvalidGrant,
+		},
+		{
+			name:         "wrong code verifier",
+			codeVerifier: "wrong",
+			redirectURI:  testRedirectURI,
+			mockBehavior: func(a *mock_service.MockAuthorizationCodeStorage, u *mock_service.MockUserStorage,
+				s *mock_service.MockSessionStorage) {
+				a.EXPECT().Pop(gomock.Any(), hash.GetTokenHash(code)).Return(storedCode, true, nil)
+			},
+			expectedError: ErrInvalidGrant,
+		},
+		{
+			name:         "other redirect uri",
+			codeVerifier: testCodeVerifier,
+			redirectURI:  "https://partner.example/other",
+			mockBehavior: func(a *mock_service.MockAuthorizationCodeStorage, u *mock_service.MockUserStorage,
+				s *mock_service.MockSessionStorage) {
+				a.EXPECT().Pop(gomock.Any(), hash.GetTokenHash(code)).Return(storedCode, true, nil)
+			},
+			expectedError: ErrInvalidGrant,
+		},
+		{
+			name:         "used code",
+			codeVerifier: testCodeVerifier,
+			redirectURI:  testRedirectURI,
+			mockBehavior: func(a *mock_service.MockAuthorizationCodeStorage, u *mock_service.MockUserStorage,
+				s *mock_service.MockSessionStorage) {
+				a.EXPECT().Pop(gomock.Any(), hash.GetTokenHash(code)).Return(models.AuthorizationCode{}, false, nil)
+			},
+			expectedError: ErrInvalidGrant,
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			clientStorage := mock_service.NewMockOAuthClientStorage(c)
+			clientStorage.EXPECT().GetByID(gomock.Any(), testClient.ID).Return(testClient, nil)
+
+			codeStorage := mock_service.NewMockAuthorizationCodeStorage(c)
+			userStorage := mock_service.NewMockUserStorage(c)
+			sessionStorage := mock_service.NewMockSessionStorage(c)
+			tc.mockBehavior(codeStorage, userStorage, sessionStorage)
+
+			authService := NewAuthService(newTestTokenManager(t), sessionStorage, userStorage, nil,
+				nil, nil, nil, nil, nil, nil, models.AgePolicies{})
+			service := NewOAuthService(authService, clientStorage, codeStorage)
+
+			token, err := service.Exchange(context.Background(), models.TokenRequest{
+				GrantType:    models.GrantAuthorizationCode,
+				Code:         code,
+				RedirectURI:  tc.redirectURI,
+				CodeVerifier: tc.codeVerifier,
+				ClientID:     testClient.ID,
+			})
+			if tc.expectedError != nil {
+				assert.Equal(t, true, errors.Is(err, tc.expectedError))
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tc.expectedScope, token.Scope)
+			assert.Equal(t, "Bearer", token.TokenType)
+			assert.NotEqual(t, "", token.RefreshToken)
+		})
+	}
+}
+
+func TestExchangeClientCredentials(t *testing.T) {
+	const secret = "secret"
+
+	client := models.OAuthClient{
+		ID:         "5f1e8b8a-1f0e-4d3c-9a57-3b1c0f3b2a22",
+		SecretHash: hash.GetTokenHash(secret),
+		GrantTypes: "client_credentials",
+		Scopes:     "drinks:update",
+	}
+
+	testTable := []struct {
+		name          string
+		clientSecret  string
+		scopes        []models.Permission
+		expectedScope string
+		expectedError error
+	}{
+		{
+			name:          "ok",
+			clientSecret:  secret,
+			expectedScope: "drinks:update",
+		},
+		{
+			name:          "wrong secret",
+			clientSecret:  "wrong",
+			expectedError: ErrInvalidClient,
+		},
+		{
+			name:          "scope not allowed",
+			clientSecret:  secret,
+			scopes:        []models.Permission{models.PermDrinksDelete},
+			expectedError: ErrInvalidScope,
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			clientStorage := mock_service.NewMockOAuthClientStorage(c)
+			clientStorage.EXPECT().GetByID(gomock.Any(), client.ID).Return(client, nil)
+
+			tokenManager := newTestTokenManager(t)
+			authService := NewAuthService(tokenManager, nil, nil, nil, nil, nil, nil, nil, nil, nil, models.AgePolicies{})
+			service := NewOAuthService(authService, clientStorage, nil)
+
+			token, err := service.Exchange(context.Background(), models.TokenRequest{
+				GrantType:    models.GrantClientCredentials,
+				Scopes:       tc.scopes,
+				ClientID:     client.ID,
+				ClientSecret: tc.clientSecret,
+			})
+			if tc.expectedError != nil {
+				assert.Equal(t, true, errors.Is(err, tc.expectedError))
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			userAttr, err := tokenManager.ParseAccessToken(token.AccessToken)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tc.expectedScope, token.Scope)
+			assert.Equal(t, "", token.RefreshToken)
+			assert.Equal(t, client.ID, userAttr.ClientID)
+			assert.Equal(t, "", userAttr.ID)
+		})
+	}
+}
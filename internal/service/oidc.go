@@ -0,0 +1,329 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/HeadGardener/coursework/internal/lib/auth"
+	"github.com/HeadGardener/coursework/internal/lib/hash"
+	"github.com/HeadGardener/coursework/internal/lib/oidc"
+	"github.com/HeadGardener/coursework/internal/models"
+	"github.com/google/uuid"
+)
+
+const (
+	oidcStateLen        = 32
+	oidcNonceLen        = 32
+	oidcVerifierLen     = 32
+	oidcBindingLen      = 32
+	oidcLoginTTL        = 10 * time.Minute
+	birthdateLayout     = "2006-01-02"
+	amrMFA              = "mfa"
+	maxUsernameLen      = 32
+	usernameAttempts    = 5
+	usernameSuffixRange = 10000
+	defaultUsername     = "user"
+	defaultName         = "User"
+)
+
+var (
+	ErrUnknownProvider   = errors.New("unknown identity provider")
+	ErrInvalidOIDCState  = errors.New("invalid or expired login state")
+	ErrSSOFailed         = errors.New("single sign-on failed")
+	ErrIdentityNotLinked = errors.New("identity isn't linked to any account")
+	ErrIdentityConflict  = errors.New("identity or account is already linked at this provider")
+	ErrNoFreeUsername    = errors.New("unable to pick a free username")
+)
+
+type OIDCProvider interface {
+	AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error)
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*oidc.Claims, error)
+	AutoProvision() bool
+	TrustMFA() bool
+}
+
+type IdentityStorage interface {
+	Get(ctx context.Context, provider, subject string) (models.Identity, error)
+	ListByUser(ctx context.Context, userID string) ([]models.Identity, error)
+	Link(ctx context.Context, identity models.Identity) error
+	CreateUser(ctx context.Context, user *models.User, identity models.Identity) error
+}
+
+type OIDCLoginStorage interface {
+	Add(ctx context.Context, stateHash string, login models.OIDCLogin, ttl time.Duration) error
+	Pop(ctx context.Context, stateHash string) (models.OIDCLogin, bool, error)
+}
+
+// OIDCService signs users in through external OpenID Connect providers. Identities
+// are matched by the provider's subject, and the login ends in a regular session.
+type OIDCService struct {
+	authService     *AuthService
+	providers       map[string]OIDCProvider
+	identityStorage IdentityStorage
+	loginStorage    OIDCLoginStorage
+}
+
+func NewOIDCService(authService *AuthService, providers map[string]OIDCProvider, identityStorage IdentityStorage,
+	loginStorage OIDCLoginStorage) *OIDCService {
+	return &OIDCService{
+		authService:     authService,
+		providers:       providers,
+		identityStorage: identityStorage,
+		loginStorage:    loginStorage,
+	}
+}
+
+func (s *OIDCService) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// StartLogin returns the provider's login page to send the user to, and the binding
+// the browser must present with the callback, so a callback crafted by someone else
+// can't sign the user in. With userID set the identity is linked to that user instead
+// of signing in by it. cookieMode is remembered for the callback.
+func (s *OIDCService) StartLogin(ctx context.Context, providerName, userID string,
+	cookieMode bool) (authURL, binding string, err error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return "", "", ErrUnknownProvider
+	}
+
+	state, err := auth.GenerateRandomToken(oidcStateLen)
+	if err != nil {
+		return "", "", err
+	}
+
+	if binding, err = auth.GenerateRandomToken(oidcBindingLen); err != nil {
+		return "", "", err
+	}
+
+	login := models.OIDCLogin{
+		Provider:    providerName,
+		UserID:      userID,
+		BindingHash: hash.GetTokenHash(binding),
+		CookieMode:  cookieMode,
+	}
+
+	if login.Nonce, err = auth.GenerateRandomToken(oidcNonceLen); err != nil {
+		return "", "", err
+	}
+
+	if login.CodeVerifier, err = auth.GenerateRandomToken(oidcVerifierLen); err != nil {
+		return "", "", err
+	}
+
+	authURL, err = provider.AuthCodeURL(ctx, state, login.Nonce, codeChallengeS256(login.CodeVerifier))
+	if err != nil {
+		return "", "", err
+	}
+
+	if err = s.loginStorage.Add(ctx, hash.GetTokenHash(state), login, oidcLoginTTL); err != nil {
+		return "", "", err
+	}
+
+	return authURL, binding, nil
+}
+
+// CompleteLogin handles the provider's redirect back: it redeems the code, finds,
+// links or provisions the user and starts a session. A second factor done at
+// the provider counts only if the provider is trusted for it, users with 2FA get
+// an MFA challenge otherwise. A callback
+// without the binding of the browser that started the login is rejected.
+func (s *OIDCService) CompleteLogin(ctx context.Context, providerName, code, state, binding string,
+	client models.ClientInfo) (models.SignInResult, error) {
+	login, ok, err := s.loginStorage.Pop(ctx, hash.GetTokenHash(state))
+	if err != nil {
+		return models.SignInResult{}, err
+	}
+
+	if !ok || login.Provider != providerName || !hash.CompareTokenHash(login.BindingHash, binding) {
+		return models.SignInResult{}, ErrInvalidOIDCState
+	}
+
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return models.SignInResult{}, ErrUnknownProvider
+	}
+
+	claims, err := provider.Exchange(ctx, code, login.CodeVerifier, login.Nonce)
+	if err != nil {
+		return models.SignInResult{}, fmt.Errorf("%w: %w", ErrSSOFailed, err)
+	}
+
+	user, err := s.resolveUser(ctx, providerName, provider, login.UserID, claims)
+	if err != nil {
+		return models.SignInResult{}, err
+	}
+
+	if user.Disabled {
+		return models.SignInResult{}, ErrUserDisabled
+	}
+
+	mfa := provider.TrustMFA() && slices.Contains(claims.AMR, amrMFA)
+
+	if user.TOTPEnabled && !mfa {
+		mfaRequired, err := s.authService.createMFAChallenge(ctx, user, client)
+		if err != nil {
+			return models.SignInResult{}, err
+		}
+
+		return models.SignInResult{MFARequired: mfaRequired, CookieMode: login.CookieMode}, nil
+	}
+
+	tokens, err := s.authService.createSession(ctx, user, client, mfa)
+	if err != nil {
+		return models.SignInResult{}, err
+	}
+
+	return models.SignInResult{Tokens: tokens, CookieMode: login.CookieMode}, nil
+}
+
+func (s *OIDCService) resolveUser(ctx context.Context, providerName string, provider OIDCProvider,
+	linkUserID string, claims *oidc.Claims) (*models.User, error) {
+	identity, err := s.identityStorage.Get(ctx, providerName, claims.Subject)
+
+	switch {
+	case err == nil:
+		if linkUserID != "" && identity.UserID != linkUserID {
+			return nil, ErrIdentityConflict
+		}
+
+		return s.authService.userStorage.GetByID(ctx, identity.UserID)
+
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, err
+
+	case linkUserID != "":
+		err = s.identityStorage.Link(ctx, models.Identity{
+			Provider: providerName,
+			Subject:  claims.Subject,
+			UserID:   linkUserID,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrIdentityConflict
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		return s.authService.userStorage.GetByID(ctx, linkUserID)
+
+	case !provider.AutoProvision():
+		return nil, ErrIdentityNotLinked
+
+	default:
+		return s.provisionUser(ctx, providerName, claims)
+	}
+}
+
+// provisionUser creates a password-less user from the ID token claims. Without
+// a birthdate claim the age stays unknown, so only soft drinks are served.
+func (s *OIDCService) provisionUser(ctx context.Context, providerName string, claims *oidc.Claims) (*models.User, error) {
+	username, err := s.freeUsername(ctx, usernameFromClaims(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		ID:       uuid.NewString(),
+		Username: username,
+		Name:     nameFromClaims(claims),
+	}
+
+	if birthDate, err := time.Parse(birthdateLayout, claims.Birthdate); err == nil {
+		user.BirthDate = birthDate
+	}
+
+	if err = s.identityStorage.CreateUser(ctx, user, models.Identity{
+		Provider: providerName,
+		Subject:  claims.Subject,
+		UserID:   user.ID,
+	}); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// freeUsername returns base if nobody has it yet, or base with a random number appended.
+func (s *OIDCService) freeUsername(ctx context.Context, base string) (string, error) {
+	username := base
+
+	for range usernameAttempts {
+		_, err := s.authService.userStorage.GetByUsername(ctx, username)
+		if errors.Is(err, sql.ErrNoRows) {
+			return username, nil
+		}
+
+		if err != nil {
+			return "", err
+		}
+
+		username = fmt.Sprintf("%s%04d", base, rand.IntN(usernameSuffixRange)) //nolint:gosec
+	}
+
+	return "", ErrNoFreeUsername
+}
+
+// usernameFromClaims keeps the letters and digits of preferred_username,
+// the domain of an email-like username is dropped.
+func usernameFromClaims(claims *oidc.Claims) string {
+	local, _, _ := strings.Cut(claims.PreferredUsername, "@")
+
+	username := keepRunes(local, func(r rune) bool {
+		return r <= unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))
+	})
+	if username == "" {
+		return defaultUsername
+	}
+
+	if len(username) > maxUsernameLen {
+		username = username[:maxUsernameLen]
+	}
+
+	return username
+}
+
+func nameFromClaims(claims *oidc.Claims) string {
+	candidates := []string{claims.GivenName}
+	if fields := strings.Fields(claims.Name); len(fields) > 0 {
+		candidates = append(candidates, fields[0])
+	}
+
+	for _, candidate := range candidates {
+		name := keepRunes(candidate, func(r rune) bool {
+			return r <= unicode.MaxASCII && unicode.IsLetter(r)
+		})
+		if name != "" {
+			return name
+		}
+	}
+
+	return defaultName
+}
+
+func keepRunes(s string, keep func(rune) bool) string {
+	return strings.Map(func(r rune) rune {
+		if keep(r) {
+			return r
+		}
+
+		return -1
+	}, s)
+}
@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/HeadGardener/coursework/internal/config"
+	"github.com/HeadGardener/coursework/internal/lib/hash"
+	"github.com/HeadGardener/coursework/internal/lib/oidc"
+	"github.com/HeadGardener/coursework/internal/lib/oidc/oidctest"
+	"github.com/HeadGardener/coursework/internal/models"
+	mock_service "github.com/HeadGardener/coursework/internal/service/mocks"
+	"github.com/go-playground/assert/v2"
+	"go.uber.org/mock/gomock"
+)
+
+const testOIDCProvider = "corp"
+
+func newTestIdP(t *testing.T) *oidctest.Server {
+	t.Helper()
+
+	idp, err := oidctest.NewServer("coursework", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(idp.Close)
+
+	idp.AddUser(oidctest.User{
+		Subject:           "alice-sub",
+		PreferredUsername: "alice@corp.example",
+		Name:              "Alice Smith",
+		GivenName:         "Alice",
+		Birthdate:         "1990-05-01",
+	})
+	idp.AddUser(oidctest.User{
+		Subject: "bob-sub",
+		AMR:     []string{"pwd", "mfa"},
+	})
+
+	return idp
+}
+
+func TestCompleteLogin(t *testing.T) {
+	type mockBehavior func(i *mock_service.MockIdentityStorage, u *mock_service.MockUserStorage,
+		s *mock_service.MockSessionStorage)
+
+	user := &models.User{ID: "user", Username: "alice"}
+
+	testTable := []struct {
+		name          string
+		subject       string
+		linkUserID    string
+		autoProvision bool
+		trustMFA      bool
+		mockBehavior  mockBehavior
+		expectedError error
+	}{
+		{
+			name:    "linked identity",
+			subject: "alice-sub",
+			mockBehavior: func(i *mock_service.MockIdentityStorage, u *mock_service.MockUserStorage,
+				s *mock_service.MockSessionStorage) {
+				i.EXPECT().Get(gomock.Any(), testOIDCProvider, "alice-sub").Return(models.Identity{UserID: user.ID}, nil)
+				u.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil)
+				u.EXPECT().GetAccess(gomock.Any(), user.ID).Return(models.Access{}, nil)
+				s.EXPECT().Add(gomock.Any(), gomock.Any(), time.Hour).Return(nil)
+			},
+		},
+		{
+			name:          "provisioned user",
+			subject:       "alice-sub",
+			autoProvision: true,
+			mockBehavior: func(i *mock_service.MockIdentityStorage, u *mock_service.MockUserStorage,
+				s *mock_service.MockSessionStorage) {
+				i.EXPECT().Get(gomock.Any(), testOIDCProvider, "alice-sub").Return(models.Identity{}, sql.ErrNoRows)
+				u.EXPECT().GetByUsername(gomock.Any(), "alice").Return(user, nil)
+				u.EXPECT().GetByUsername(gomock.Any(), gomock.Any()).Return(nil, sql.ErrNoRows)
+				i.EXPECT().CreateUser(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, created *models.User, identity models.Identity) error {
+						assert.NotEqual(t, "alice", created.Username)
+						assert.Equal(t, "Alice", created.Name)
+						assert.Equal(t, time.Date(1990, time.May, 1, 0, 0, 0, 0, time.UTC), created.BirthDate)
+						assert.Equal(t, "", created.PasswordHash)
+						assert.Equal(t, models.Identity{Provider: testOIDCProvider, Subject: "alice-sub", UserID: created.ID}, identity)
+
+						return nil
+					})
+				u.EXPECT().GetAccess(gomock.Any(), gomock.Any()).Return(models.Access{}, nil)
+				s.EXPECT().Add(gomock.Any(), gomock.Any(), time.Hour).Return(nil)
+			},
+		},
+		{
+			name:    "unknown identity without provisioning",
+			subject: "alice-sub",
+			mockBehavior: func(i *mock_service.MockIdentityStorage, u *mock_service.MockUserStorage,
+				s *mock_service.MockSessionStorage) {
+				i.EXPECT().Get(gomock.Any(), testOIDCProvider, "alice-sub").Return(models.Identity{}, sql.ErrNoRows)
+			},
+			expectedError: ErrIdentityNotLinked,
+		},
+		{
+			name:       "link to signed in user",
+			subject:    "alice-sub",
+			linkUserID: user.ID,
+			mockBehavior: func(i *mock_service.MockIdentityStorage, u *mock_service.MockUserStorage,
+				s *mock_service.MockSessionStorage) {
+				i.EXPECT().Get(gomock.Any(), testOIDCProvider, "alice-sub").Return(models.Identity{}, sql.ErrNoRows)
+				i.EXPECT().Link(gomock.Any(), models.Identity{
+					Provider: testOIDCProvider,
+					Subject:  "alice-sub",
+					UserID:   user.ID,
+				}).Return(nil)
+				u.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil)
+				u.EXPECT().GetAccess(gomock.Any(), user.ID).Return(models.Access{}, nil)
+				s.EXPECT().Add(gomock.Any(), gomock.Any(), time.Hour).Return(nil)
+			},
+		},
+		{
+			name:       "identity linked to another user",
+			subject:    "alice-sub",
+			linkUserID: user.ID,
+			mockBehavior: func(i *mock_service.MockIdentityStorage, u *mock_service.MockUserStorage,
+				s *mock_service.MockSessionStorage) {
+				i.EXPECT().Get(gomock.Any(), testOIDCProvider, "alice-sub").Return(models.Identity{UserID: "other"}, nil)
+			},
+			expectedError: ErrIdentityConflict,
+		},
+		{
+			name:    "disabled user",
+			subject: "alice-sub",
+			mockBehavior: func(i *mock_service.MockIdentityStorage, u *mock_service.MockUserStorage,
+				s *mock_service.MockSessionStorage) {
+				i.EXPECT().Get(gomock.Any(), testOIDCProvider, "alice-sub").Return(models.Identity{UserID: user.ID}, nil)
+				u.EXPECT().GetByID(gomock.Any(), user.ID).Return(&models.User{ID: user.ID, Disabled: true}, nil)
+			},
+			expectedError: ErrUserDisabled,
+		},
+		{
+			name:     "second factor done at the provider",
+			subject:  "bob-sub",
+			trustMFA: true,
+			mockBehavior: func(i *mock_service.MockIdentityStorage, u *mock_service.MockUserStorage,
+				s *mock_service.MockSessionStorage) {
+				i.EXPECT().Get(gomock.Any(), testOIDCProvider, "bob-sub").Return(models.Identity{UserID: user.ID}, nil)
+				u.EXPECT().GetByID(gomock.Any(), user.ID).Return(&models.User{ID: user.ID, TOTPEnabled: true}, nil)
+				u.EXPECT().GetAccess(gomock.Any(), user.ID).Return(models.Access{}, nil)
+				s.EXPECT().Add(gomock.Any(), gomock.Any(), time.Hour).DoAndReturn(
+					func(_ context.Context, session models.Session, _ time.Duration) error {
+						assert.Equal(t, true, session.MFA)
+						return nil
+					})
+			},
+		},
+		{
+			name:    "second factor at an untrusted provider",
+			subject: "bob-sub",
+			mockBehavior: func(i *mock_service.MockIdentityStorage, u *mock_service.MockUserStorage,
+				s *mock_service.MockSessionStorage) {
+				i.EXPECT().Get(gomock.Any(), testOIDCProvider, "bob-sub").Return(models.Identity{UserID: user.ID}, nil)
+				u.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil)
+				u.EXPECT().GetAccess(gomock.Any(), user.ID).Return(models.Access{}, nil)
+				s.EXPECT().Add(gomock.Any(), gomock.Any(), time.Hour).DoAndReturn(
+					func(_ context.Context, session models.Session, _ time.Duration) error {
+						assert.Equal(t, false, session.MFA)
+						return nil
+					})
+			},
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			idp := newTestIdP(t)
+
+			identityStorage := mock_service.NewMockIdentityStorage(c)
+			loginStorage := mock_service.NewMockOIDCLoginStorage(c)
+			userStorage := mock_service.NewMockUserStorage(c)
+			sessionStorage := mock_service.NewMockSessionStorage(c)
+			tc.mockBehavior(identityStorage, userStorage, sessionStorage)
+
+			var (
+				login     models.OIDCLogin
+				stateHash string
+			)
+
+			loginStorage.EXPECT().Add(gomock.Any(), gomock.Any(), gomock.Any(), oidcLoginTTL).DoAndReturn(
+				func(_ context.Context, hash string, l models.OIDCLogin, _ time.Duration) error {
+					stateHash, login = hash, l
+					return nil
+				})
+			loginStorage.EXPECT().Pop(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, hash string) (models.OIDCLogin, bool, error) {
+					return login, hash == stateHash, nil
+				})
+
+			provider := oidc.NewProvider(config.OIDCProviderConfig{
+				Name:          testOIDCProvider,
+				Issuer:        idp.Issuer(),
+				ClientID:      idp.ClientID,
+				ClientSecret:  idp.ClientSecret,
+				RedirectURL:   "https://app.example.com/sso/callback",
+				Scopes:        []string{"openid", "profile"},
+				AutoProvision: tc.autoProvision,
+				TrustMFA:      tc.trustMFA,
+			})
+
+			authService := NewAuthService(newTestTokenManager(t), sessionStorage, userStorage, nil,
+				nil, nil, nil, nil, nil, nil, models.AgePolicies{})
+			service := NewOIDCService(authService, map[string]OIDCProvider{testOIDCProvider: provider},
+				identityStorage, loginStorage)
+
+			authURL, binding, err := service.StartLogin(context.Background(), testOIDCProvider, tc.linkUserID, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			callback, err := idp.Authorize(authURL, tc.subject)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := service.CompleteLogin(context.Background(), testOIDCProvider,
+				callback.Query().Get("code"), callback.Query().Get("state"), binding, models.ClientInfo{})
+			if tc.expectedError != nil {
+				assert.Equal(t, true, errors.Is(err, tc.expectedError))
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.NotEqual(t, "", result.Tokens.AccessToken)
+			assert.NotEqual(t, "", result.Tokens.RefreshToken)
+		})
+	}
+}
+
+func TestCompleteLoginInvalidState(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	loginStorage := mock_service.NewMockOIDCLoginStorage(c)
+	loginStorage.EXPECT().Pop(gomock.Any(), hash.GetTokenHash("state")).Return(models.OIDCLogin{
+		Provider: "other",
+	}, true, nil)
+
+	service := NewOIDCService(nil, map[string]OIDCProvider{testOIDCProvider: nil}, nil, loginStorage)
+
+	_, err := service.CompleteLogin(context.Background(), testOIDCProvider, "code", "state", "binding",
+		models.ClientInfo{})
+	assert.Equal(t, true, errors.Is(err, ErrInvalidOIDCState))
+}
+
+func TestCompleteLoginOtherBrowser(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	loginStorage := mock_service.NewMockOIDCLoginStorage(c)
+	loginStorage.EXPECT().Pop(gomock.Any(), hash.GetTokenHash("state")).Return(models.OIDCLogin{
+		Provider:    testOIDCProvider,
+		BindingHash: hash.GetTokenHash("binding"),
+	}, true, nil)
+
+	service := NewOIDCService(nil, map[string]OIDCProvider{testOIDCProvider: nil}, nil, loginStorage)
+
+	_, err := service.CompleteLogin(context.Background(), testOIDCProvider, "code", "state", "attacker's binding",
+		models.ClientInfo{})
+	assert.Equal(t, true, errors.Is(err, ErrInvalidOIDCState))
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/HeadGardener/coursework/internal/lib/auth"
+	"github.com/HeadGardener/coursework/internal/lib/hash"
+	"github.com/HeadGardener/coursework/internal/models"
+)
+
+// ChangePassword sets a new password after checking the old one and logs the user out
+// of every session except the one the request came from.
+func (s *AuthService) ChangePassword(ctx context.Context, userAttr auth.UserAttributes, oldPassword, newPassword string) error {
+	user, err := s.userStorage.GetByID(ctx, userAttr.ID)
+	if err != nil {
+		return err
+	}
+
+	ok, _, err := s.comparePassword(user, oldPassword)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return ErrInvalidPassword
+	}
+
+	if err = s.setPassword(ctx, user.ID, newPassword); err != nil {
+		return err
+	}
+
+	return s.revokeSessions(ctx, user.ID, userAttr.SessionID)
+}
+
+// RequestPasswordReset sends a single-use reset token to the user. Unknown usernames
+// are not reported to the caller, so the endpoint can't be used to enumerate accounts.
+func (s *AuthService) RequestPasswordReset(ctx context.Context, username string) error {
+	user, err := s.userStorage.GetByUsername(ctx, username)
+	if err != nil {
+		log.Printf("[INFO] password reset requested for unknown user %s: %s", username, err.Error())
+		return nil
+	}
+
+	token, err := s.tokenManager.GenerateResetToken()
+	if err != nil {
+		return err
+	}
+
+	ttl := s.tokenManager.GetResetTokenTTL()
+	if err = s.resetTokenStorage.Add(ctx, hash.GetTokenHash(token), user.ID, ttl); err != nil {
+		return err
+	}
+
+	return s.notifier.Send(ctx, user, models.Notification{
+		Subject: "Password reset",
+		Body:    fmt.Sprintf("Use this token to reset your password: %s. It expires in %s.", token, ttl),
+	})
+}
+
+// ResetPassword redeems a reset token and logs the user out everywhere.
+func (s *AuthService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	userID, err := s.resetTokenStorage.Pop(ctx, hash.GetTokenHash(token))
+	if err != nil {
+		return err
+	}
+
+	if err = s.setPassword(ctx, userID, newPassword); err != nil {
+		return err
+	}
+
+	return s.revokeSessions(ctx, userID, "")
+}
+
+func (s *AuthService) setPassword(ctx context.Context, userID, password string) error {
+	passwordHash, err := s.passwordHasher.Hash(password)
+	if err != nil {
+		return err
+	}
+
+	return s.userStorage.UpdatePassword(ctx, userID, passwordHash)
+}
+
+// rehashPassword upgrades a hash made with an old algorithm or parameters. It runs after
+// a successful sign in, the only time the plain password is known, and a failure
+// here must not fail the sign in itself.
+func (s *AuthService) rehashPassword(ctx context.Context, userID, password string) {
+	if err := s.setPassword(ctx, userID, password); err != nil {
+		log.Printf("[ERROR] failed to rehash password of user %s: %s", userID, err.Error())
+	}
+}
+
+// comparePassword treats the missing password of a user provisioned through single
+// sign-on as a wrong one. Such users can set a password with a reset.
+func (s *AuthService) comparePassword(user *models.User, password string) (ok, needsRehash bool, err error) {
+	if user.PasswordHash == "" {
+		return false, false, nil
+	}
+
+	return s.passwordHasher.Compare(user.PasswordHash, password)
+}
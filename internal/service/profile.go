@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/HeadGardener/coursework/internal/lib/auth"
+	"github.com/HeadGardener/coursework/internal/models"
+	"github.com/HeadGardener/coursework/internal/storage"
+)
+
+var ErrUsernameTaken = errors.New("username is already taken")
+
+// UpdateProfile changes the username and name of the user, empty values keep the current ones.
+func (s *AuthService) UpdateProfile(ctx context.Context, userID, username, name string) (models.UserInfo, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return models.UserInfo{}, err
+	}
+
+	if username != "" {
+		user.Username = username
+	}
+
+	if name != "" {
+		user.Name = name
+	}
+
+	if err = s.userStorage.UpdateProfile(ctx, user.ID, user.Username, user.Name); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return models.UserInfo{}, ErrUserNotFound
+		case errors.Is(err, storage.ErrUsernameTaken):
+			return models.UserInfo{}, ErrUsernameTaken
+		default:
+			return models.UserInfo{}, err
+		}
+	}
+
+	access, err := s.userStorage.GetAccess(ctx, user.ID)
+	if err != nil {
+		return models.UserInfo{}, err
+	}
+
+	return user.Info(access.Roles), nil
+}
+
+// DeleteAccount removes the user after checking the password. Every session is revoked
+// first, so the tokens the user still holds stop working right away. Wrong passwords
+// count towards the same lockout as failed sign ins, so a stolen token can't be used
+// to guess the password.
+func (s *AuthService) DeleteAccount(ctx context.Context, userAttr auth.UserAttributes, password string,
+	client models.ClientInfo) error {
+	user, err := s.getUser(ctx, userAttr.ID)
+	if err != nil {
+		return err
+	}
+
+	if err = s.checkLockout(ctx, user.Username, client.IP); err != nil {
+		return err
+	}
+
+	ok, _, err := s.comparePassword(user, password)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		if err = s.registerFailure(ctx, user.Username, client.IP); err != nil {
+			return err
+		}
+
+		return ErrInvalidPassword
+	}
+
+	if err = s.attemptStorage.Reset(ctx, usernameLimitPrefix+user.Username); err != nil {
+		return err
+	}
+
+	if err = s.revokeSessions(ctx, user.ID, ""); err != nil {
+		return err
+	}
+
+	return s.userStorage.Delete(ctx, user.ID)
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/HeadGardener/coursework/internal/lib/auth"
+	"github.com/HeadGardener/coursework/internal/lib/hash"
+	"github.com/HeadGardener/coursework/internal/models"
+	mock_service "github.com/HeadGardener/coursework/internal/service/mocks"
+	"github.com/HeadGardener/coursework/internal/storage"
+	"github.com/go-playground/assert/v2"
+	"go.uber.org/mock/gomock"
+)
+
+func TestUpdateProfileUsernameTaken(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	userStorage := mock_service.NewMockUserStorage(c)
+	userStorage.EXPECT().GetByID(gomock.Any(), "user").Return(&models.User{ID: "user", Username: "alice", Name: "Alice"}, nil)
+	userStorage.EXPECT().UpdateProfile(gomock.Any(), "user", "bob", "Alice").Return(storage.ErrUsernameTaken)
+
+	service := NewAuthService(newTestTokenManager(t), nil, userStorage, nil,
+		nil, nil, nil, nil, nil, nil, models.AgePolicies{})
+
+	_, err := service.UpdateProfile(context.Background(), "user", "bob", "")
+	assert.Equal(t, ErrUsernameTaken, err)
+}
+
+func TestDeleteAccountLockout(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	client := models.ClientInfo{IP: "127.0.0.1"}
+
+	userStorage := mock_service.NewMockUserStorage(c)
+	userStorage.EXPECT().GetByID(gomock.Any(), "1").Return(&models.User{
+		ID:           "1",
+		Username:     "user",
+		PasswordHash: "$2a$04$9Q2TgRbq5zqQXNTrjG1DVeAaXKpFzQmm5rNvUhE/eDdNoVnnMVKIG",
+	}, nil)
+
+	attemptStorage := mock_service.NewMockLoginAttemptStorage(c)
+	attemptStorage.EXPECT().GetLock(gomock.Any(), gomock.Any()).Return(time.Duration(0), nil).Times(2)
+	attemptStorage.EXPECT().RegisterFailure(gomock.Any(), "user:user", failuresWindow).Return(int64(userFailuresThreshold), nil)
+	attemptStorage.EXPECT().Lock(gomock.Any(), "user:user", baseLockout).Return(nil)
+	attemptStorage.EXPECT().RegisterFailure(gomock.Any(), "ip:127.0.0.1", failuresWindow).Return(int64(1), nil)
+
+	service := NewAuthService(nil, nil, userStorage, nil, nil, nil, nil, nil, attemptStorage,
+		hash.NewPasswordHasher(hash.DefaultArgon2Params), models.AgePolicies{})
+
+	err := service.DeleteAccount(context.Background(), auth.UserAttributes{ID: "1"}, "password", client)
+	assert.Equal(t, ErrInvalidPassword, err)
+}
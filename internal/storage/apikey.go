@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/HeadGardener/coursework/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	// lastUsedPrecision limits how often using a key writes to the db.
+	lastUsedPrecision = "1 minute"
+)
+
+type APIKeyStorage struct {
+	db *sqlx.DB
+}
+
+func NewAPIKeyStorage(db *sqlx.DB) *APIKeyStorage {
+	return &APIKeyStorage{db: db}
+}
+
+func (s *APIKeyStorage) Create(ctx context.Context, key *models.APIKey) error {
+	if err := s.db.GetContext(ctx, &key.CreatedAt, `insert into api_keys (id, user_id, name, prefix, key_hash, scopes, expires_at)
+												values($1,$2,$3,$4,$5,$6,$7) returning created_at`,
+		key.ID,
+		key.UserID,
+		key.Name,
+		key.Prefix,
+		key.KeyHash,
+		key.Scopes,
+		key.ExpiresAt); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *APIKeyStorage) GetByPrefix(ctx context.Context, prefix string) (models.APIKey, error) {
+	var key models.APIKey
+
+	if err := s.db.GetContext(ctx, &key, `select * from api_keys where prefix=$1`, prefix); err != nil {
+		return models.APIKey{}, err
+	}
+
+	return key, nil
+}
+
+// List returns keys of the user, or of everyone if userID is empty, newest first.
+func (s *APIKeyStorage) List(ctx context.Context, userID string) ([]models.APIKey, error) {
+	keys := []models.APIKey{}
+
+	if err := s.db.SelectContext(ctx, &keys, `select * from api_keys
+												where $1 = '' or user_id::text = $1
+												order by created_at desc, id`,
+		userID); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// Revoke marks the key revoked, keys that don't exist or are already revoked give sql.ErrNoRows.
+func (s *APIKeyStorage) Revoke(ctx context.Context, keyID string) error {
+	res, err := s.db.ExecContext(ctx, `update api_keys set revoked_at=now() where id=$1 and revoked_at is null`, keyID)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (s *APIKeyStorage) TouchLastUsed(ctx context.Context, keyID string) error {
+	if _, err := s.db.ExecContext(ctx, `update api_keys set last_used_at=now()
+												where id=$1 and (last_used_at is null or last_used_at < now() - $2::interval)`,
+		keyID, lastUsedPrecision); err != nil {
+		return err
+	}
+
+	return nil
+}
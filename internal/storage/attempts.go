@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	loginFailuresKeyPrefix = "login_failures:"
+	loginLockKeyPrefix     = "login_lock:"
+)
+
+// LoginAttemptStorage counts failed sign in attempts and holds temporary locks,
+// keyed by whatever the caller limits on (username, client IP).
+type LoginAttemptStorage struct {
+	rdb *redis.Client
+}
+
+func NewLoginAttemptStorage(rdb *redis.Client) *LoginAttemptStorage {
+	return &LoginAttemptStorage{rdb: rdb}
+}
+
+// GetLock returns how long the key stays locked, zero if it isn't.
+func (s *LoginAttemptStorage) GetLock(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := s.rdb.PTTL(ctx, loginLockKey(key)).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get login lock: %w", err)
+	}
+
+	// negative values mean there is no key or no expiry
+	if ttl < 0 {
+		return 0, nil
+	}
+
+	return ttl, nil
+}
+
+// RegisterFailure increments the failures counter of key, the counter is reset
+// after window passes without failures.
+func (s *LoginAttemptStorage) RegisterFailure(ctx context.Context, key string, window time.Duration) (int64, error) {
+	var incr *redis.IntCmd
+
+	_, err := s.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, loginFailuresKey(key))
+		pipe.Expire(ctx, loginFailuresKey(key), window)
+
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("unable to register login failure: %w", err)
+	}
+
+	return incr.Val(), nil
+}
+
+func (s *LoginAttemptStorage) Lock(ctx context.Context, key string, d time.Duration) error {
+	if err := s.rdb.Set(ctx, loginLockKey(key), 1, d).Err(); err != nil {
+		return fmt.Errorf("unable to lock login: %w", err)
+	}
+
+	return nil
+}
+
+// Reset clears both the failures counter and the lock of key.
+func (s *LoginAttemptStorage) Reset(ctx context.Context, key string) error {
+	if err := s.rdb.Del(ctx, loginFailuresKey(key), loginLockKey(key)).Err(); err != nil {
+		return fmt.Errorf("unable to reset login failures: %w", err)
+	}
+
+	return nil
+}
+
+func loginFailuresKey(key string) string {
+	return loginFailuresKeyPrefix + key
+}
+
+func loginLockKey(key string) string {
+	return loginLockKeyPrefix + key
+}
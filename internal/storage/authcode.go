@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/HeadGardener/coursework/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	authCodeKeyPrefix = "oauth_code:"
+)
+
+// AuthorizationCodeStorage keeps OAuth authorization codes by their hash until
+// they are exchanged or expire.
+type AuthorizationCodeStorage struct {
+	rdb *redis.Client
+}
+
+func NewAuthorizationCodeStorage(rdb *redis.Client) *AuthorizationCodeStorage {
+	return &AuthorizationCodeStorage{rdb: rdb}
+}
+
+func (s *AuthorizationCodeStorage) Add(ctx context.Context, codeHash string, code models.AuthorizationCode,
+	ttl time.Duration) error {
+	b, err := json.Marshal(code)
+	if err != nil {
+		return err
+	}
+
+	if err = s.rdb.Set(ctx, authCodeKey(codeHash), b, ttl).Err(); err != nil {
+		return fmt.Errorf("unable to store authorization code: %w", err)
+	}
+
+	return nil
+}
+
+// Pop returns the code and deletes it, so it can be exchanged only once. False means
+// the code doesn't exist, was already used or has expired.
+func (s *AuthorizationCodeStorage) Pop(ctx context.Context, codeHash string) (models.AuthorizationCode, bool, error) {
+	b, err := s.rdb.GetDel(ctx, authCodeKey(codeHash)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return models.AuthorizationCode{}, false, nil
+		}
+
+		return models.AuthorizationCode{}, false, fmt.Errorf("failed to get authorization code: %w", err)
+	}
+
+	var code models.AuthorizationCode
+	if err = json.Unmarshal(b, &code); err != nil {
+		return models.AuthorizationCode{}, false, err
+	}
+
+	return code, true, nil
+}
+
+func authCodeKey(codeHash string) string {
+	return authCodeKeyPrefix + codeHash
+}
@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/HeadGardener/coursework/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+var ErrCategoryInUse = errors.New("category still has drinks or subcategories")
+
+type CategoryStorage struct {
+	db *sqlx.DB
+}
+
+func NewCategoryStorage(db *sqlx.DB) *CategoryStorage {
+	return &CategoryStorage{db: db}
+}
+
+func (s *CategoryStorage) GetAll(ctx context.Context) ([]models.Category, error) {
+	categories := []models.Category{}
+
+	if err := s.db.SelectContext(ctx, &categories, `select * from categories order by name, id`); err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+func (s *CategoryStorage) GetByID(ctx context.Context, id int) (models.Category, error) {
+	var category models.Category
+
+	if err := s.db.GetContext(ctx, &category, `select * from categories where id=$1`, id); err != nil {
+		return models.Category{}, err
+	}
+
+	return category, nil
+}
+
+// GetByName looks a category up by its name, ignoring case.
+func (s *CategoryStorage) GetByName(ctx context.Context, name string) (models.Category, error) {
+	var category models.Category
+
+	if err := s.db.GetContext(ctx, &category, `select * from categories where lower(name)=lower($1)`,
+		name); err != nil {
+		return models.Category{}, err
+	}
+
+	return category, nil
+}
+
+// CountDrinks returns the number of drinks that pass filter in each category, categories
+// without such drinks are left out.
+func (s *CategoryStorage) CountDrinks(ctx context.Context, filter models.DrinkFilter) (map[int]int, error) {
+	var rows []struct {
+		CategoryID int `db:"category_id"`
+		Count      int `db:"count"`
+	}
+
+	if err := s.db.SelectContext(ctx, &rows, `select category_id, count(*) from drinks
+												where `+ageFilterCond+` group by category_id`,
+		filter.SoftDrinkMaxABV, categoryNames(filter.AgeCategories)); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[int]int, len(rows))
+	for _, row := range rows {
+		counts[row.CategoryID] = row.Count
+	}
+
+	return counts, nil
+}
+
+func (s *CategoryStorage) Create(ctx context.Context, category *models.Category) (int, error) {
+	var id int
+
+	if err := s.db.QueryRowContext(ctx,
+		`insert into categories (name, parent_id, age_category) values($1,$2,$3) returning id`,
+		category.Name, category.ParentID, category.AgeCategory).Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (s *CategoryStorage) Update(ctx context.Context, id int, category *models.Category) error {
+	res, err := s.db.ExecContext(ctx, `update categories set name=$1, parent_id=$2, age_category=$3 where id=$4`,
+		category.Name, category.ParentID, category.AgeCategory, id)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// Delete removes a category that has neither drinks nor subcategories. It returns
+// ErrCategoryInUse if it still has some, sql.ErrNoRows if there is no such category.
+func (s *CategoryStorage) Delete(ctx context.Context, id int) error {
+	res, err := s.db.ExecContext(ctx, `delete from categories where id=$1
+											and not exists (select 1 from drinks where category_id=$1)
+											and not exists (select 1 from categories where parent_id=$1)`, id)
+	if err != nil {
+		// a drink or subcategory added meanwhile is caught by the foreign keys
+		if hasPgCode(err, foreignKeyViolation) {
+			return ErrCategoryInUse
+		}
+
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n > 0 {
+		return nil
+	}
+
+	var exists bool
+
+	if err = s.db.GetContext(ctx, &exists, `select exists (select 1 from categories where id=$1)`, id); err != nil {
+		return err
+	}
+
+	if exists {
+		return ErrCategoryInUse
+	}
+
+	return sql.ErrNoRows
+}
@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	denylistKeyPrefix = "denylist:"
+)
+
+// DenylistStorage holds IDs of access tokens (jti) and sessions that must be rejected
+// before the access token expires.
+type DenylistStorage struct {
+	rdb *redis.Client
+}
+
+func NewDenylistStorage(rdb *redis.Client) *DenylistStorage {
+	return &DenylistStorage{rdb: rdb}
+}
+
+func (s *DenylistStorage) Deny(ctx context.Context, id string, ttl time.Duration) error {
+	if err := s.rdb.Set(ctx, denylistKey(id), 1, ttl).Err(); err != nil {
+		return fmt.Errorf("unable to deny %s: %w", id, err)
+	}
+
+	return nil
+}
+
+// CheckDenied reports for each of ids whether it is in the denylist.
+func (s *DenylistStorage) CheckDenied(ctx context.Context, ids ...string) ([]bool, error) {
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = denylistKey(id)
+	}
+
+	values, err := s.rdb.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check denylist: %w", err)
+	}
+
+	denied := make([]bool, len(values))
+	for i, v := range values {
+		denied[i] = v != nil
+	}
+
+	return denied, nil
+}
+
+func denylistKey(id string) string {
+	return denylistKeyPrefix + id
+}
@@ -2,11 +2,42 @@ package storage
 
 import (
 	"context"
+	"strconv"
+	"strings"
 
 	"github.com/HeadGardener/coursework/internal/models"
 	"github.com/jmoiron/sqlx"
 )
 
+// ageFilterCond expects the soft drink ABV threshold as $1 and allowed age categories as $2.
+const ageFilterCond = `(abv <= $1 and 'soft' = any($2)) or (abv > $1 and age_category = any($2))`
+
+// drinkSearchCond expects the search query as $3. It matches the words of the name or
+// of a category the drink is in, misspelled and partial ones by trigram word similarity.
+// Accents are ignored.
+var drinkSearchCond = `(to_tsvector('simple', immutable_unaccent(name)) @@
+	websearch_to_tsquery('simple', immutable_unaccent($3))
+	or immutable_unaccent($3) <% immutable_unaccent(name)
+	or category_id in (` + categorySubtree(`to_tsvector('simple', immutable_unaccent(name)) @@
+		websearch_to_tsquery('simple', immutable_unaccent($3))
+		or immutable_unaccent($3) <% immutable_unaccent(name)`) + `))`
+
+// drinkSearchRank puts full-text matches of the name first and then orders by how close
+// the query is to it. Drinks found by their category come last.
+const drinkSearchRank = `ts_rank(to_tsvector('simple', immutable_unaccent(name)),
+	websearch_to_tsquery('simple', immutable_unaccent($3)))
+	+ word_similarity(immutable_unaccent($3), immutable_unaccent(name))`
+
+// drinkColumns are selected into models.Drink, the price goes into the nested Money.
+const drinkColumns = `id, name, category_id, bottle, cost as "cost.amount", currency as "cost.currency", abv, age_category`
+
+// drinkSortColumns are the only columns drinks can be ordered by.
+var drinkSortColumns = map[models.DrinkSort]string{
+	models.DrinkSortID:   "id",
+	models.DrinkSortName: "name",
+	models.DrinkSortCost: "cost",
+}
+
 type DrinkStorage struct {
 	db *sqlx.DB
 }
@@ -15,30 +46,146 @@ func NewDrinkStorage(db *sqlx.DB) *DrinkStorage {
 	return &DrinkStorage{db: db}
 }
 
-func (s *DrinkStorage) GetAll(ctx context.Context, adult bool) ([]models.Drink, error) {
-	var drinks []models.Drink
+// GetAll returns a page of drinks that pass filter and match query. Drinks not stronger
+// than the soft drink threshold are treated as soft, the rest are matched by their
+// age category.
+func (s *DrinkStorage) GetAll(ctx context.Context, filter models.DrinkFilter,
+	query models.DrinkQuery) ([]models.Drink, error) {
+	drinks := []models.Drink{}
 
-	var query = `select * from drinks`
-	if !adult {
-		query += ` where is_soft=true`
-	}
+	sqlQuery, args := buildDrinkQuery(filter, query)
 
-	if err := s.db.SelectContext(ctx, &drinks, query); err != nil {
+	if err := s.db.SelectContext(ctx, &drinks, sqlQuery, args...); err != nil {
 		return nil, err
 	}
 
 	return drinks, nil
 }
 
-func (s *DrinkStorage) GetByID(ctx context.Context, id int, adult bool) (models.Drink, error) {
-	var drink models.Drink
+// buildDrinkQuery puts together the select for GetAll. Every value goes in as a bind
+// argument and the sort column comes from drinkSortColumns, so nothing from the
+// request ends up in the SQL text. The page is a keyset one: it continues after
+// the cursor's (sort column, id) instead of skipping rows with an offset.
+func buildDrinkQuery(filter models.DrinkFilter, query models.DrinkQuery) (string, []any) {
+	b := &queryBuilder{
+		conds: []string{"(" + ageFilterCond + ")"},
+		args:  []any{filter.SoftDrinkMaxABV, categoryNames(filter.AgeCategories)},
+	}
+
+	if len(query.Categories) > 0 {
+		b.where("category_id in (" + categorySubtree("id = any("+b.arg(query.Categories)+")") + ")")
+	}
+
+	if query.Currency != "" {
+		b.where("currency = " + b.arg(query.Currency))
+	}
+
+	if query.MinCost != nil {
+		b.where("cost >= " + b.arg(*query.MinCost))
+	}
+
+	if query.MaxCost != nil {
+		b.where("cost <= " + b.arg(*query.MaxCost))
+	}
+
+	if query.MinBottle != nil {
+		b.where("bottle >= " + b.arg(*query.MinBottle))
+	}
 
-	var query = `select * from drinks where id=$1`
-	if !adult {
-		query += ` and is_soft=true`
+	if query.MaxBottle != nil {
+		b.where("bottle <= " + b.arg(*query.MaxBottle))
 	}
 
-	if err := s.db.GetContext(ctx, &drink, query, id); err != nil {
+	if query.Soft != nil {
+		if *query.Soft {
+			b.where("abv <= $1")
+		} else {
+			b.where("abv > $1")
+		}
+	}
+
+	column, ok := drinkSortColumns[query.Sort]
+	if !ok {
+		column = drinkSortColumns[models.DrinkSortID]
+	}
+
+	op, dir := ">", "asc"
+	if query.Desc {
+		op, dir = "<", "desc"
+	}
+
+	if after := query.After; after != nil {
+		switch query.Sort {
+		case models.DrinkSortName:
+			b.where("(name, id) " + op + " (" + b.arg(after.Name) + ", " + b.arg(after.ID) + ")")
+		case models.DrinkSortCost:
+			b.where("(cost, id) " + op + " (" + b.arg(after.Cost) + ", " + b.arg(after.ID) + ")")
+		default:
+			b.where("id " + op + " " + b.arg(after.ID))
+		}
+	}
+
+	order := column + " " + dir
+	if column != "id" {
+		order += ", id " + dir
+	}
+
+	return `select ` + drinkColumns + ` from drinks where ` + strings.Join(b.conds, " and ") +
+		` order by ` + order + ` limit ` + b.arg(query.Limit), b.args
+}
+
+// Search returns a page of drinks that pass filter and match the search query, ranked
+// by relevance, and the number of all such drinks.
+func (s *DrinkStorage) Search(ctx context.Context, filter models.DrinkFilter,
+	search models.DrinkSearch) ([]models.Drink, int, error) {
+	var (
+		drinks     = []models.Drink{}
+		total      int
+		categories = categoryNames(filter.AgeCategories)
+	)
+
+	if err := s.db.GetContext(ctx, &total, `select count(*) from drinks
+												where (`+ageFilterCond+`) and `+drinkSearchCond,
+		filter.SoftDrinkMaxABV, categories, search.Query); err != nil {
+		return nil, 0, err
+	}
+
+	if err := s.db.SelectContext(ctx, &drinks, `select `+drinkColumns+` from drinks
+												where (`+ageFilterCond+`) and `+drinkSearchCond+`
+												order by `+drinkSearchRank+` desc, id limit $4 offset $5`,
+		filter.SoftDrinkMaxABV, categories, search.Query, search.PerPage,
+		(search.Page-1)*search.PerPage); err != nil {
+		return nil, 0, err
+	}
+
+	return drinks, total, nil
+}
+
+// Autocomplete returns up to limit names of drinks that pass filter for the start of a name
+// typed so far. Names starting with it come first, then close ones by trigram similarity.
+func (s *DrinkStorage) Autocomplete(ctx context.Context, filter models.DrinkFilter, prefix string,
+	limit int) ([]models.DrinkSuggestion, error) {
+	suggestions := []models.DrinkSuggestion{}
+
+	if err := s.db.SelectContext(ctx, &suggestions, `select id, name from drinks
+												where (`+ageFilterCond+`) and (immutable_unaccent(name) ilike immutable_unaccent($4)
+												or immutable_unaccent($3) <% immutable_unaccent(name))
+												order by immutable_unaccent(name) ilike immutable_unaccent($4) desc,
+												word_similarity(immutable_unaccent($3), immutable_unaccent(name)) desc, name, id
+												limit $5`,
+		filter.SoftDrinkMaxABV, categoryNames(filter.AgeCategories), prefix, escapeLike(prefix)+"%",
+		limit); err != nil {
+		return nil, err
+	}
+
+	return suggestions, nil
+}
+
+func (s *DrinkStorage) GetByID(ctx context.Context, id int, filter models.DrinkFilter) (models.Drink, error) {
+	var drink models.Drink
+
+	if err := s.db.GetContext(ctx, &drink, `select `+drinkColumns+` from drinks where id=$3 and (`+ageFilterCond+`)`,
+		filter.SoftDrinkMaxABV, categoryNames(filter.AgeCategories), id); err != nil {
 		return models.Drink{}, err
 	}
 
@@ -49,8 +196,10 @@ func (s *DrinkStorage) Create(ctx context.Context, drink *models.Drink) (int, er
 	var id int
 
 	if err := s.db.QueryRowContext(ctx,
-		`insert into drinks (name, type, bottle, cost, is_soft) values($1,$2,$3,$4,$5) returning id`,
-		drink.Name, drink.Type, drink.Bottle, drink.Cost, drink.Soft).Scan(&id); err != nil {
+		`insert into drinks (name, category_id, bottle, cost, currency, abv, age_category)
+			values($1,$2,$3,$4,$5,$6,$7) returning id`,
+		drink.Name, drink.CategoryID, drink.Bottle, drink.Cost.Amount, drink.Cost.Currency, drink.ABV,
+		drink.AgeCategory).Scan(&id); err != nil {
 		return 0, err
 	}
 
@@ -58,14 +207,46 @@ func (s *DrinkStorage) Create(ctx context.Context, drink *models.Drink) (int, er
 }
 
 func (s *DrinkStorage) Update(ctx context.Context, id int, drink *models.Drink) error {
-	if _, err := s.db.ExecContext(ctx, `update drinks set name=$1, type=$2, bottle=$3, cost=$4 where id=$5`,
-		drink.Name, drink.Type, drink.Bottle, drink.Cost, id); err != nil {
+	if _, err := s.db.ExecContext(ctx, `update drinks set name=$1, category_id=$2, bottle=$3, cost=$4, currency=$5,
+											abv=$6, age_category=$7 where id=$8`,
+		drink.Name, drink.CategoryID, drink.Bottle, drink.Cost.Amount, drink.Cost.Currency, drink.ABV,
+		drink.AgeCategory, id); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// Patch locks the drink, lets apply change it and saves the result, so concurrent
+// patches of the same drink don't overwrite each other. It returns sql.ErrNoRows
+// if there is no such drink.
+func (s *DrinkStorage) Patch(ctx context.Context, id int, apply func(drink *models.Drink) error) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	var drink models.Drink
+
+	if err = tx.GetContext(ctx, &drink, `select `+drinkColumns+` from drinks where id=$1 for update`, id); err != nil {
+		return err
+	}
+
+	if err = apply(&drink); err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, `update drinks set name=$1, category_id=$2, bottle=$3, cost=$4, currency=$5,
+											abv=$6, age_category=$7 where id=$8`,
+		drink.Name, drink.CategoryID, drink.Bottle, drink.Cost.Amount, drink.Cost.Currency, drink.ABV,
+		drink.AgeCategory, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
 func (s *DrinkStorage) Delete(ctx context.Context, id int) error {
 	if _, err := s.db.ExecContext(ctx, `delete from drinks where id=$1`,
 		id); err != nil {
@@ -74,3 +255,39 @@ func (s *DrinkStorage) Delete(ctx context.Context, id int) error {
 
 	return nil
 }
+
+// categorySubtree selects the IDs of the categories matching rootCond and of all
+// categories below them.
+func categorySubtree(rootCond string) string {
+	return `with recursive subtree (id) as (
+		select id from categories where ` + rootCond + `
+		union
+		select c.id from categories c join subtree s on c.parent_id = s.id
+	) select id from subtree`
+}
+
+func categoryNames(categories []models.AgeCategory) []string {
+	names := make([]string, len(categories))
+	for i, category := range categories {
+		names[i] = string(category)
+	}
+
+	return names
+}
+
+// queryBuilder collects where conditions and numbers their bind arguments.
+type queryBuilder struct {
+	conds []string
+	args  []any
+}
+
+func (b *queryBuilder) where(cond string) {
+	b.conds = append(b.conds, cond)
+}
+
+// arg adds a bind argument and returns its placeholder.
+func (b *queryBuilder) arg(v any) string {
+	b.args = append(b.args, v)
+
+	return "$" + strconv.Itoa(len(b.args))
+}
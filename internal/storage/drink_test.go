@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/HeadGardener/coursework/internal/models"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestBuildDrinkQuery(t *testing.T) {
+	filter := models.DrinkFilter{
+		AgeCategories:   []models.AgeCategory{models.AgeCategorySoft},
+		SoftDrinkMaxABV: 0.5,
+	}
+
+	minCost, soft := 100, true
+
+	testTable := []struct {
+		name         string
+		query        models.DrinkQuery
+		expectedSQL  string
+		expectedArgs []any
+	}{
+		{
+			name:  "defaults",
+			query: models.DrinkQuery{Limit: 21},
+			expectedSQL: `select ` + drinkColumns + ` from drinks where (` + ageFilterCond + `)` +
+				` order by id asc limit $3`,
+			expectedArgs: []any{0.5, []string{"soft"}, 21},
+		},
+		{
+			name: "filters",
+			query: models.DrinkQuery{
+				Categories: []int{2},
+				Currency:   models.Currency("USD"),
+				MinCost:    &minCost,
+				Soft:       &soft,
+				Sort:       models.DrinkSortCost,
+				Desc:       true,
+				Limit:      11,
+			},
+			expectedSQL: `select ` + drinkColumns + ` from drinks where (` + ageFilterCond + `)` +
+				` and category_id in (` + categorySubtree("id = any($3)") + `)` +
+				` and currency = $4 and cost >= $5 and abv <= $1` +
+				` order by cost desc, id desc limit $6`,
+			expectedArgs: []any{0.5, []string{"soft"}, []int{2}, models.Currency("USD"), 100, 11},
+		},
+		{
+			name: "after cursor",
+			query: models.DrinkQuery{
+				Sort:  models.DrinkSortName,
+				Limit: 21,
+				After: &models.DrinkCursor{Sort: models.DrinkSortName, ID: 7, Name: "'; drop table drinks; --"},
+			},
+			expectedSQL: `select ` + drinkColumns + ` from drinks where (` + ageFilterCond + `)` +
+				` and (name, id) > ($3, $4)` +
+				` order by name asc, id asc limit $5`,
+			expectedArgs: []any{0.5, []string{"soft"}, "'; drop table drinks; --", 7, 21},
+		},
+		{
+			name:  "unknown sort",
+			query: models.DrinkQuery{Sort: "cost; drop table drinks", Limit: 21},
+			expectedSQL: `select ` + drinkColumns + ` from drinks where (` + ageFilterCond + `)` +
+				` order by id asc limit $3`,
+			expectedArgs: []any{0.5, []string{"soft"}, 21},
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			sql, args := buildDrinkQuery(filter, tc.query)
+
+			assert.Equal(t, tc.expectedSQL, sql)
+			assert.Equal(t, tc.expectedArgs, args)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/HeadGardener/coursework/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	exportJobKeyPrefix     = "export_job:"
+	exportArchiveKeyPrefix = "export_archive:"
+)
+
+// ExportStorage keeps export jobs and the built archives in redis, both expire
+// together with the download link.
+type ExportStorage struct {
+	rdb *redis.Client
+}
+
+func NewExportStorage(rdb *redis.Client) *ExportStorage {
+	return &ExportStorage{rdb: rdb}
+}
+
+func (s *ExportStorage) SaveJob(ctx context.Context, job models.ExportJob) error {
+	b, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	if err = s.rdb.Set(ctx, exportJobKey(job.ID), b, time.Until(job.ExpiresAt)).Err(); err != nil {
+		return fmt.Errorf("unable to store export job: %w", err)
+	}
+
+	return nil
+}
+
+// GetJob returns the job with jobID, false means it doesn't exist or has expired.
+func (s *ExportStorage) GetJob(ctx context.Context, jobID string) (models.ExportJob, bool, error) {
+	b, err := s.rdb.Get(ctx, exportJobKey(jobID)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return models.ExportJob{}, false, nil
+		}
+
+		return models.ExportJob{}, false, fmt.Errorf("failed to get export job: %w", err)
+	}
+
+	var job models.ExportJob
+	if err = json.Unmarshal(b, &job); err != nil {
+		return models.ExportJob{}, false, err
+	}
+
+	return job, true, nil
+}
+
+func (s *ExportStorage) SaveArchive(ctx context.Context, jobID string, archive []byte, ttl time.Duration) error {
+	if err := s.rdb.Set(ctx, exportArchiveKey(jobID), archive, ttl).Err(); err != nil {
+		return fmt.Errorf("unable to store export archive: %w", err)
+	}
+
+	return nil
+}
+
+// GetArchive returns the archive of the job, false means it has expired.
+func (s *ExportStorage) GetArchive(ctx context.Context, jobID string) ([]byte, bool, error) {
+	archive, err := s.rdb.Get(ctx, exportArchiveKey(jobID)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, false, nil
+		}
+
+		return nil, false, fmt.Errorf("failed to get export archive: %w", err)
+	}
+
+	return archive, true, nil
+}
+
+func exportJobKey(jobID string) string {
+	return exportJobKeyPrefix + jobID
+}
+
+func exportArchiveKey(jobID string) string {
+	return exportArchiveKeyPrefix + jobID
+}
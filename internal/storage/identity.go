@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/HeadGardener/coursework/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+type IdentityStorage struct {
+	db *sqlx.DB
+}
+
+func NewIdentityStorage(db *sqlx.DB) *IdentityStorage {
+	return &IdentityStorage{db: db}
+}
+
+// Get returns the identity of subject at provider, sql.ErrNoRows if it isn't linked to anyone.
+func (s *IdentityStorage) Get(ctx context.Context, provider, subject string) (models.Identity, error) {
+	var identity models.Identity
+
+	if err := s.db.GetContext(ctx, &identity, `select * from user_identities where provider=$1 and subject=$2`,
+		provider, subject); err != nil {
+		return models.Identity{}, err
+	}
+
+	return identity, nil
+}
+
+func (s *IdentityStorage) ListByUser(ctx context.Context, userID string) ([]models.Identity, error) {
+	identities := []models.Identity{}
+
+	if err := s.db.SelectContext(ctx, &identities, `select * from user_identities where user_id=$1
+													order by provider`,
+		userID); err != nil {
+		return nil, err
+	}
+
+	return identities, nil
+}
+
+// Link stores the identity, sql.ErrNoRows means the subject or the user is already
+// linked at this provider.
+func (s *IdentityStorage) Link(ctx context.Context, identity models.Identity) error {
+	res, err := s.db.ExecContext(ctx, `insert into user_identities (provider, subject, user_id) values($1,$2,$3)
+												on conflict do nothing`,
+		identity.Provider,
+		identity.Subject,
+		identity.UserID)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// CreateUser stores a new user together with the identity it was provisioned from.
+func (s *IdentityStorage) CreateUser(ctx context.Context, user *models.User, identity models.Identity) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if err = insertUser(ctx, tx, user); err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, `insert into user_identities (provider, subject, user_id) values($1,$2,$3)`,
+		identity.Provider,
+		identity.Subject,
+		user.ID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
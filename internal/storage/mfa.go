@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/HeadGardener/coursework/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	mfaChallengeKeyPrefix = "mfa_challenge:"
+	mfaAttemptsKeyPrefix  = "mfa_attempts:"
+	usedTOTPKeyPrefix     = "used_totp:"
+)
+
+var (
+	ErrMFAChallengeNotFound = errors.New("mfa challenge is invalid or expired")
+)
+
+type MFAStorage struct {
+	rdb *redis.Client
+}
+
+func NewMFAStorage(rdb *redis.Client) *MFAStorage {
+	return &MFAStorage{rdb: rdb}
+}
+
+func (s *MFAStorage) AddChallenge(ctx context.Context, tokenHash string, challenge models.MFAChallenge, ttl time.Duration) error {
+	b, err := json.Marshal(challenge)
+	if err != nil {
+		return fmt.Errorf("failed to marshal mfa challenge: %w", err)
+	}
+
+	if err = s.rdb.Set(ctx, mfaChallengeKey(tokenHash), b, ttl).Err(); err != nil {
+		return fmt.Errorf("unable to store mfa challenge: %w", err)
+	}
+
+	return nil
+}
+
+func (s *MFAStorage) GetChallenge(ctx context.Context, tokenHash string) (models.MFAChallenge, error) {
+	b, err := s.rdb.Get(ctx, mfaChallengeKey(tokenHash)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return models.MFAChallenge{}, ErrMFAChallengeNotFound
+		}
+
+		return models.MFAChallenge{}, fmt.Errorf("failed to get mfa challenge: %w", err)
+	}
+
+	var challenge models.MFAChallenge
+	if err = json.Unmarshal(b, &challenge); err != nil {
+		return models.MFAChallenge{}, fmt.Errorf("failed to unmarshal mfa challenge: %w", err)
+	}
+
+	return challenge, nil
+}
+
+// IncrChallengeAttempts counts failed codes entered for the challenge.
+func (s *MFAStorage) IncrChallengeAttempts(ctx context.Context, tokenHash string, ttl time.Duration) (int64, error) {
+	var incr *redis.IntCmd
+
+	_, err := s.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, mfaAttemptsKey(tokenHash))
+		pipe.Expire(ctx, mfaAttemptsKey(tokenHash), ttl)
+
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("unable to count mfa attempts: %w", err)
+	}
+
+	return incr.Val(), nil
+}
+
+func (s *MFAStorage) DeleteChallenge(ctx context.Context, tokenHash string) error {
+	if err := s.rdb.Del(ctx, mfaChallengeKey(tokenHash), mfaAttemptsKey(tokenHash)).Err(); err != nil {
+		return fmt.Errorf("unable to delete mfa challenge: %w", err)
+	}
+
+	return nil
+}
+
+// MarkTOTPUsed remembers the time step a user's code was accepted for and reports
+// false if a code for that step has already been used.
+func (s *MFAStorage) MarkTOTPUsed(ctx context.Context, userID string, counter int64, ttl time.Duration) (bool, error) {
+	ok, err := s.rdb.SetNX(ctx, usedTOTPKey(userID, counter), 1, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("unable to store used totp: %w", err)
+	}
+
+	return ok, nil
+}
+
+func mfaChallengeKey(tokenHash string) string {
+	return mfaChallengeKeyPrefix + tokenHash
+}
+
+func mfaAttemptsKey(tokenHash string) string {
+	return mfaAttemptsKeyPrefix + tokenHash
+}
+
+func usedTOTPKey(userID string, counter int64) string {
+	return usedTOTPKeyPrefix + userID + ":" + strconv.FormatInt(counter, 10)
+}
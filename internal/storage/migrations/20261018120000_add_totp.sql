@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table users
+    add column totp_secret varchar(64) not null default '',
+    add column totp_enabled bool not null default false;
+
+create table recovery_codes (
+    id serial primary key,
+    user_id uuid not null references users (id) on delete cascade,
+    code_hash varchar(64) not null,
+    used_at timestamp
+);
+
+create index recovery_codes_user_id_idx on recovery_codes (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table recovery_codes;
+
+alter table users
+    drop column totp_secret,
+    drop column totp_enabled;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table users add column birth_date date;
+
+-- the exact birth date is unknown, assume the stored age was reached today
+update users set birth_date = current_date - make_interval(years => age);
+
+alter table users
+    alter column birth_date set not null,
+    drop column age;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table users add column age integer;
+
+update users set age = date_part('year', age(birth_date));
+
+alter table users
+    alter column age set not null,
+    drop column birth_date;
+-- +goose StatementEnd
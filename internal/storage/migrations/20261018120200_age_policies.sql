@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table users add column region varchar(16) not null default '';
+
+alter table drinks add column age_category varchar(32) not null default 'spirits';
+
+update drinks set age_category = case
+    when is_soft then 'soft'
+    when lower(type) in ('beer', 'wine', 'cider') then 'beer_wine'
+    else 'spirits'
+end;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table drinks drop column age_category;
+
+alter table users drop column region;
+-- +goose StatementEnd
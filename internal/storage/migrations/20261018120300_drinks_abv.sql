@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table drinks add column abv numeric(5, 2) not null default 0
+    check (abv >= 0 and abv <= 100);
+
+-- strength of existing alcoholic drinks is unknown, take a typical one for their category
+update drinks set abv = case
+    when is_soft then 0
+    when age_category = 'beer_wine' then 5
+    else 40
+end;
+
+alter table drinks drop column is_soft;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table drinks add column is_soft bool not null default true;
+
+update drinks set is_soft = abv <= 0.5;
+
+alter table drinks drop column abv;
+-- +goose StatementEnd
@@ -0,0 +1,58 @@
+-- +goose Up
+-- +goose StatementBegin
+create table roles (
+    id serial primary key,
+    name varchar(64) not null unique
+);
+
+create table permissions (
+    id serial primary key,
+    name varchar(64) not null unique
+);
+
+create table role_permissions (
+    role_id integer not null references roles (id) on delete cascade,
+    permission_id integer not null references permissions (id) on delete cascade,
+    primary key (role_id, permission_id)
+);
+
+create table user_roles (
+    user_id uuid not null references users (id) on delete cascade,
+    role_id integer not null references roles (id) on delete cascade,
+    primary key (user_id, role_id)
+);
+
+insert into roles (name) values ('user'), ('bartender'), ('manager'), ('auditor'), ('admin');
+
+insert into permissions (name) values
+    ('drinks:create'),
+    ('drinks:update'),
+    ('drinks:delete'),
+    ('users:unlock');
+
+insert into role_permissions (role_id, permission_id)
+select r.id, p.id from roles r, permissions p
+where r.name = 'admin'
+   or (r.name = 'bartender' and p.name = 'drinks:update')
+   or (r.name = 'manager' and p.name in ('drinks:create', 'drinks:update', 'drinks:delete', 'users:unlock'));
+
+insert into user_roles (user_id, role_id)
+select u.id, r.id from users u, roles r
+where r.name = case when u.role = 1 then 'admin' else 'user' end;
+
+alter table users drop column role;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table users add column role integer not null default 0;
+
+update users set role = 1 where id in (
+    select ur.user_id from user_roles ur join roles r on r.id = ur.role_id where r.name = 'admin'
+);
+
+drop table user_roles;
+drop table role_permissions;
+drop table permissions;
+drop table roles;
+-- +goose StatementEnd
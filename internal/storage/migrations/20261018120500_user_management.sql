@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table users
+    add column disabled bool not null default false,
+    add column created_at timestamp not null default now();
+
+insert into permissions (name) values ('users:read'), ('users:manage');
+
+insert into role_permissions (role_id, permission_id)
+select r.id, p.id from roles r, permissions p
+where (r.name = 'admin' and p.name in ('users:read', 'users:manage'))
+   or (r.name in ('manager', 'auditor') and p.name = 'users:read');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+delete from permissions where name in ('users:read', 'users:manage');
+
+alter table users
+    drop column disabled,
+    drop column created_at;
+-- +goose StatementEnd
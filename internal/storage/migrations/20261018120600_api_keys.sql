@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+create table api_keys
+(
+    id           uuid primary key,
+    user_id      uuid        not null references users (id) on delete cascade,
+    name         varchar(64) not null,
+    prefix       varchar(16) not null unique,
+    key_hash     text        not null,
+    scopes       text        not null default '',
+    created_at   timestamp   not null default now(),
+    expires_at   timestamp   not null,
+    last_used_at timestamp,
+    revoked_at   timestamp
+);
+
+create index api_keys_user_id_idx on api_keys (user_id);
+
+insert into permissions (name) values ('api_keys:manage');
+
+insert into role_permissions (role_id, permission_id)
+select r.id, p.id from roles r, permissions p
+where r.name = 'admin' and p.name = 'api_keys:manage';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+delete from permissions where name = 'api_keys:manage';
+
+drop table api_keys;
+-- +goose StatementEnd
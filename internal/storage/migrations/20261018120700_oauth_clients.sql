@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+create table oauth_clients
+(
+    id            uuid primary key,
+    name          varchar(64) not null,
+    secret_hash   text        not null default '',
+    redirect_uris text        not null default '',
+    grant_types   text        not null,
+    scopes        text        not null default '',
+    created_at    timestamp   not null default now()
+);
+
+insert into permissions (name) values ('oauth_clients:manage');
+
+insert into role_permissions (role_id, permission_id)
+select r.id, p.id from roles r, permissions p
+where r.name = 'admin' and p.name = 'oauth_clients:manage';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+delete from permissions where name = 'oauth_clients:manage';
+
+drop table oauth_clients;
+-- +goose StatementEnd
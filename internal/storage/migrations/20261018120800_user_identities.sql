@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+create table user_identities
+(
+    provider   varchar(64)  not null,
+    subject    varchar(255) not null,
+    user_id    uuid         not null references users (id) on delete cascade,
+    created_at timestamp    not null default now(),
+    primary key (provider, subject),
+    unique (provider, user_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table user_identities;
+-- +goose StatementEnd
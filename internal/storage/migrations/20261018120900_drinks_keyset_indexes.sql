@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+create index drinks_name_id_idx on drinks (name, id);
+create index drinks_cost_id_idx on drinks (cost, id);
+create index drinks_type_idx on drinks (type);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index drinks_type_idx;
+drop index drinks_cost_id_idx;
+drop index drinks_name_id_idx;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+create extension if not exists pg_trgm;
+create extension if not exists unaccent;
+
+-- unaccent() is only stable, an immutable wrapper lets it be used in indexes
+create function immutable_unaccent(text) returns text
+    language sql immutable strict parallel safe
+    as $$ select public.unaccent('public.unaccent'::regdictionary, $1) $$;
+
+create index drinks_search_idx on drinks using gin (to_tsvector('simple', immutable_unaccent(name || ' ' || type)));
+create index drinks_name_trgm_idx on drinks using gin (immutable_unaccent(name) gin_trgm_ops);
+create index drinks_type_trgm_idx on drinks using gin (immutable_unaccent(type) gin_trgm_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index drinks_type_trgm_idx;
+drop index drinks_name_trgm_idx;
+drop index drinks_search_idx;
+drop function immutable_unaccent(text);
+-- +goose StatementEnd
@@ -0,0 +1,105 @@
+-- +goose Up
+-- +goose StatementBegin
+create table categories (
+    id serial primary key,
+    name varchar(255) not null,
+    parent_id integer references categories (id) on delete restrict,
+    age_category varchar(32)
+);
+
+create unique index categories_name_idx on categories (lower(name));
+create index categories_parent_id_idx on categories (parent_id);
+create index categories_name_trgm_idx on categories using gin (immutable_unaccent(name) gin_trgm_ops);
+
+insert into categories (name, age_category) values
+    ('Soft drinks', 'soft'),
+    ('Beer', 'beer_wine'),
+    ('Wine', 'beer_wine'),
+    ('Cider', 'beer_wine'),
+    ('Spirits', 'spirits');
+
+insert into categories (name, parent_id)
+select v.name, c.id from categories c, (values ('Whiskey'), ('Vodka'), ('Rum'), ('Gin'), ('Tequila')) v (name)
+where c.name = 'Spirits';
+
+insert into categories (name, parent_id)
+select 'Bourbon', id from categories where name = 'Whiskey';
+
+-- spellings of the free-text type met in the wild, anything else becomes a category of its own
+create temporary table category_aliases (
+    alias text primary key,
+    category text not null
+);
+
+insert into category_aliases (alias, category) values
+    ('soda', 'soft drinks'),
+    ('soft', 'soft drinks'),
+    ('soft drink', 'soft drinks'),
+    ('soft-drink', 'soft drinks'),
+    ('softdrink', 'soft drinks'),
+    ('lemonade', 'soft drinks'),
+    ('juice', 'soft drinks'),
+    ('water', 'soft drinks'),
+    ('lager', 'beer'),
+    ('ale', 'beer'),
+    ('beers', 'beer'),
+    ('wines', 'wine'),
+    ('spirit', 'spirits'),
+    ('liquor', 'spirits'),
+    ('whisky', 'whiskey');
+
+create temporary table drink_types as
+select id as drink_id, lower(regexp_replace(btrim(type), '\s+', ' ', 'g')) as type from drinks;
+
+update drink_types t set type = a.category from category_aliases a where a.alias = t.type;
+update drink_types set type = 'other' where type = '';
+
+insert into categories (name)
+select distinct initcap(type) from drink_types
+where type not in (select lower(name) from categories);
+
+alter table drinks add column category_id integer references categories (id) on delete restrict;
+
+update drinks d set category_id = c.id
+from drink_types t join categories c on lower(c.name) = t.type
+where d.id = t.drink_id;
+
+alter table drinks alter column category_id set not null;
+create index drinks_category_id_idx on drinks (category_id);
+
+drop table drink_types;
+drop table category_aliases;
+
+drop index drinks_type_idx;
+drop index drinks_type_trgm_idx;
+drop index drinks_search_idx;
+alter table drinks drop column type;
+
+create index drinks_search_idx on drinks using gin (to_tsvector('simple', immutable_unaccent(name)));
+
+insert into permissions (name) values ('categories:manage');
+
+insert into role_permissions (role_id, permission_id)
+select r.id, p.id from roles r, permissions p
+where r.name in ('admin', 'manager') and p.name = 'categories:manage';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+delete from permissions where name = 'categories:manage';
+
+alter table drinks add column type varchar(255) not null default '';
+
+update drinks d set type = lower(c.name) from categories c where c.id = d.category_id;
+
+alter table drinks alter column type drop default;
+
+drop index drinks_search_idx;
+create index drinks_search_idx on drinks using gin (to_tsvector('simple', immutable_unaccent(name || ' ' || type)));
+create index drinks_type_trgm_idx on drinks using gin (immutable_unaccent(type) gin_trgm_ops);
+create index drinks_type_idx on drinks (type);
+
+alter table drinks drop column category_id;
+
+drop table categories;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- prices were entered in dollars, they become integer cents
+alter table drinks alter column cost type bigint using round(cost::numeric * 100)::bigint;
+alter table drinks add constraint drinks_cost_check check (cost >= 0);
+
+alter table drinks add column currency char(3) not null default 'USD';
+alter table drinks alter column currency drop default;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table drinks drop column currency;
+
+alter table drinks drop constraint drinks_cost_check;
+alter table drinks alter column cost type float using cost / 100.0;
+-- +goose StatementEnd
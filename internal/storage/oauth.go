@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/HeadGardener/coursework/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+type OAuthClientStorage struct {
+	db *sqlx.DB
+}
+
+func NewOAuthClientStorage(db *sqlx.DB) *OAuthClientStorage {
+	return &OAuthClientStorage{db: db}
+}
+
+func (s *OAuthClientStorage) Create(ctx context.Context, client *models.OAuthClient) error {
+	if err := s.db.GetContext(ctx, &client.CreatedAt, `insert into oauth_clients
+												(id, name, secret_hash, redirect_uris, grant_types, scopes)
+												values($1,$2,$3,$4,$5,$6) returning created_at`,
+		client.ID,
+		client.Name,
+		client.SecretHash,
+		client.RedirectURIs,
+		client.GrantTypes,
+		client.Scopes); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *OAuthClientStorage) GetByID(ctx context.Context, clientID string) (models.OAuthClient, error) {
+	var client models.OAuthClient
+
+	if err := s.db.GetContext(ctx, &client, `select * from oauth_clients where id::text=$1`, clientID); err != nil {
+		return models.OAuthClient{}, err
+	}
+
+	return client, nil
+}
+
+func (s *OAuthClientStorage) List(ctx context.Context) ([]models.OAuthClient, error) {
+	clients := []models.OAuthClient{}
+
+	if err := s.db.SelectContext(ctx, &clients, `select * from oauth_clients order by created_at, id`); err != nil {
+		return nil, err
+	}
+
+	return clients, nil
+}
+
+func (s *OAuthClientStorage) Delete(ctx context.Context, clientID string) error {
+	res, err := s.db.ExecContext(ctx, `delete from oauth_clients where id::text=$1`, clientID)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
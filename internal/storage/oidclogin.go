@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/HeadGardener/coursework/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	oidcLoginKeyPrefix = "oidc_login:"
+)
+
+// OIDCLoginStorage keeps logins started at an OpenID Connect provider by the hash
+// of their state until the provider redirects back.
+type OIDCLoginStorage struct {
+	rdb *redis.Client
+}
+
+func NewOIDCLoginStorage(rdb *redis.Client) *OIDCLoginStorage {
+	return &OIDCLoginStorage{rdb: rdb}
+}
+
+func (s *OIDCLoginStorage) Add(ctx context.Context, stateHash string, login models.OIDCLogin, ttl time.Duration) error {
+	b, err := json.Marshal(login)
+	if err != nil {
+		return err
+	}
+
+	if err = s.rdb.Set(ctx, oidcLoginKey(stateHash), b, ttl).Err(); err != nil {
+		return fmt.Errorf("unable to store oidc login: %w", err)
+	}
+
+	return nil
+}
+
+// Pop returns the login and deletes it, so a callback can't be replayed. False means
+// the state is unknown or has expired.
+func (s *OIDCLoginStorage) Pop(ctx context.Context, stateHash string) (models.OIDCLogin, bool, error) {
+	b, err := s.rdb.GetDel(ctx, oidcLoginKey(stateHash)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return models.OIDCLogin{}, false, nil
+		}
+
+		return models.OIDCLogin{}, false, fmt.Errorf("failed to get oidc login: %w", err)
+	}
+
+	var login models.OIDCLogin
+	if err = json.Unmarshal(b, &login); err != nil {
+		return models.OIDCLogin{}, false, err
+	}
+
+	return login, true, nil
+}
+
+func oidcLoginKey(stateHash string) string {
+	return oidcLoginKeyPrefix + stateHash
+}
@@ -2,88 +2,118 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"log"
+	"time"
 
 	"github.com/HeadGardener/coursework/internal/config"
 	"github.com/HeadGardener/coursework/internal/lib/hash"
 	"github.com/HeadGardener/coursework/internal/models"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/jmoiron/sqlx"
 )
 
+// Postgres error codes the storages translate into their own errors.
+const (
+	foreignKeyViolation = "23503"
+	uniqueViolation     = "23505"
+)
+
 //nolint:gomnd
 func initTable(ctx context.Context, db *sqlx.DB) error {
 	log.Println("inserting admin into users table")
-	if _, err := db.ExecContext(ctx, `insert into users (id, username, name, role, age, password_hash)
-											values ($1, $2, $3, $4, $5, $6)`,
+	adminPasswordHash, err := hash.NewPasswordHasher(hash.DefaultArgon2Params).Hash("1234567890")
+	if err != nil {
+		return err
+	}
+
+	if _, err = db.ExecContext(ctx, `with admin as (
+												insert into users (id, username, name, birth_date, password_hash)
+												values ($1, $2, $3, $4, $5) returning id
+											)
+											insert into user_roles (user_id, role_id)
+											select admin.id, roles.id from admin, roles where roles.name = $6`,
 		uuid.NewString(),
 		"superadmin",
 		"admin",
-		models.RoleAdmin,
-		30,
-		hash.GetStringHash("1234567890")); err != nil {
+		time.Date(1994, time.January, 1, 0, 0, 0, 0, time.UTC),
+		adminPasswordHash,
+		models.RoleAdmin); err != nil {
 		log.Println("failed to insert admin: ", err.Error())
 	}
 
 	log.Println("inserting drinks into drinks table")
-	if _, err := db.ExecContext(ctx, `insert into drinks (name, type, bottle, cost, is_soft)
-											values ($1,$2,$3,$4,$5)`,
+	if _, err := db.ExecContext(ctx, `insert into drinks (name, category_id, bottle, cost, currency, abv, age_category)
+											select $1, id, $3, $4, $5, $6, $7 from categories where name=$2`,
 		"VOSS",
-		"water",
+		"Soft drinks",
 		700,
-		10,
-		true); err != nil {
+		1000,
+		"USD",
+		0,
+		models.AgeCategorySoft); err != nil {
 		log.Println("failed to insert drink while initializing: ", err.Error())
 	}
 
-	if _, err := db.ExecContext(ctx, `insert into drinks (name, type, bottle, cost, is_soft)
-											values ($1,$2,$3,$4,$5)`,
+	if _, err := db.ExecContext(ctx, `insert into drinks (name, category_id, bottle, cost, currency, abv, age_category)
+											select $1, id, $3, $4, $5, $6, $7 from categories where name=$2`,
 		"Dr.Pepper",
-		"soda",
+		"Soft drinks",
 		300,
-		3,
-		true); err != nil {
+		300,
+		"USD",
+		0,
+		models.AgeCategorySoft); err != nil {
 		log.Println("failed to insert drink while initializing: ", err.Error())
 	}
 
-	if _, err := db.ExecContext(ctx, `insert into drinks (name, type, bottle, cost, is_soft)
-											values ($1,$2,$3,$4,$5)`,
+	if _, err := db.ExecContext(ctx, `insert into drinks (name, category_id, bottle, cost, currency, abv, age_category)
+											select $1, id, $3, $4, $5, $6, $7 from categories where name=$2`,
 		"Mountain Dew",
-		"soda",
+		"Soft drinks",
 		500,
-		2,
-		true); err != nil {
+		200,
+		"USD",
+		0,
+		models.AgeCategorySoft); err != nil {
 		log.Println("failed to insert drink while initializing: ", err.Error())
 	}
 
-	if _, err := db.ExecContext(ctx, `insert into drinks (name, type, bottle, cost, is_soft)
-											values ($1,$2,$3,$4,$5)`,
+	if _, err := db.ExecContext(ctx, `insert into drinks (name, category_id, bottle, cost, currency, abv, age_category)
+											select $1, id, $3, $4, $5, $6, $7 from categories where name=$2`,
 		"Corona Extra",
-		"beer",
+		"Beer",
 		355,
-		5,
-		false); err != nil {
+		500,
+		"USD",
+		4.5,
+		models.AgeCategoryBeerWine); err != nil {
 		log.Println("failed to insert drink while initializing: ", err.Error())
 	}
 
-	if _, err := db.ExecContext(ctx, `insert into drinks (name, type, bottle, cost, is_soft)
-											values ($1,$2,$3,$4,$5)`,
+	if _, err := db.ExecContext(ctx, `insert into drinks (name, category_id, bottle, cost, currency, abv, age_category)
+											select $1, id, $3, $4, $5, $6, $7 from categories where name=$2`,
 		"Jagermeister",
-		"liquor",
+		"Spirits",
 		1000,
-		40,
-		false); err != nil {
+		4000,
+		"USD",
+		35,
+		models.AgeCategorySpirits); err != nil {
 		log.Println("failed to insert drink while initializing: ", err.Error())
 	}
 
-	if _, err := db.ExecContext(ctx, `insert into drinks (name, type, bottle, cost, is_soft)
-											values ($1,$2,$3,$4,$5)`,
+	if _, err := db.ExecContext(ctx, `insert into drinks (name, category_id, bottle, cost, currency, abv, age_category)
+											select $1, id, $3, $4, $5, $6, $7 from categories where name=$2`,
 		"Maker's Mark",
-		"bourbon",
+		"Bourbon",
 		1000,
-		50,
-		false); err != nil {
+		5000,
+		"USD",
+		45,
+		models.AgeCategorySpirits); err != nil {
 		log.Println("failed to insert drink while initializing: ", err.Error())
 	}
 
@@ -100,7 +130,14 @@ func NewDB(ctx context.Context, conf config.DBConfig) (*sqlx.DB, error) {
 		return nil, err
 	}
 
-	initTable(ctx, db)
+	if err = initTable(ctx, db); err != nil {
+		return nil, err
+	}
 
 	return db, nil
 }
+
+func hasPgCode(err error, code string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == code
+}
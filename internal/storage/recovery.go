@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type RecoveryCodeStorage struct {
+	db *sqlx.DB
+}
+
+func NewRecoveryCodeStorage(db *sqlx.DB) *RecoveryCodeStorage {
+	return &RecoveryCodeStorage{db: db}
+}
+
+// Replace drops every recovery code of the user and stores the new ones.
+func (s *RecoveryCodeStorage) Replace(ctx context.Context, userID string, codeHashes []string) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if _, err = tx.ExecContext(ctx, `delete from recovery_codes where user_id=$1`, userID); err != nil {
+		return err
+	}
+
+	for _, codeHash := range codeHashes {
+		if _, err = tx.ExecContext(ctx, `insert into recovery_codes (user_id, code_hash) values ($1, $2)`,
+			userID, codeHash); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Use marks an unused recovery code as used and reports whether there was one.
+func (s *RecoveryCodeStorage) Use(ctx context.Context, userID, codeHash string) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `update recovery_codes set used_at=now()
+											where user_id=$1 and code_hash=$2 and used_at is null`,
+		userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
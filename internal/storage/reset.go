@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	resetTokenKeyPrefix = "password_reset:"
+)
+
+var (
+	ErrResetTokenNotFound = errors.New("reset token is invalid or expired")
+)
+
+type ResetTokenStorage struct {
+	rdb *redis.Client
+}
+
+func NewResetTokenStorage(rdb *redis.Client) *ResetTokenStorage {
+	return &ResetTokenStorage{rdb: rdb}
+}
+
+func (s *ResetTokenStorage) Add(ctx context.Context, tokenHash, userID string, ttl time.Duration) error {
+	if err := s.rdb.Set(ctx, resetTokenKey(tokenHash), userID, ttl).Err(); err != nil {
+		return fmt.Errorf("unable to store reset token: %w", err)
+	}
+
+	return nil
+}
+
+// Pop returns the user the reset token was issued for and deletes the token,
+// so it can be redeemed only once.
+func (s *ResetTokenStorage) Pop(ctx context.Context, tokenHash string) (string, error) {
+	userID, err := s.rdb.GetDel(ctx, resetTokenKey(tokenHash)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return "", ErrResetTokenNotFound
+		}
+
+		return "", fmt.Errorf("failed to get reset token: %w", err)
+	}
+
+	return userID, nil
+}
+
+func resetTokenKey(tokenHash string) string {
+	return resetTokenKeyPrefix + tokenHash
+}
@@ -2,6 +2,8 @@ package storage
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -9,6 +11,16 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+const (
+	sessionKeyPrefix      = "session:"
+	userSessionsKeyPrefix = "user_sessions:"
+	usedTokenKeyPrefix    = "used_refresh_token:"
+)
+
+var (
+	ErrSessionNotFound = errors.New("session doesn't exist")
+)
+
 type TokenStorage struct {
 	rdb *redis.Client
 }
@@ -17,14 +29,21 @@ func NewTokenStorage(rdb *redis.Client) *TokenStorage {
 	return &TokenStorage{rdb: rdb}
 }
 
+// Add stores session under its own key and registers it in the user's session index,
+// so one user can hold several sessions at once.
 func (s *TokenStorage) Add(ctx context.Context, session models.Session, ttl time.Duration) error {
-	s.rdb.Del(ctx, session.UserID)
-
 	b, err := models.MarshalSession(session)
 	if err != nil {
 		return fmt.Errorf("failed to create session: %w", err)
 	}
-	err = s.rdb.Set(ctx, session.UserID, b, ttl).Err()
+
+	_, err = s.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, sessionKey(session.ID), b, ttl)
+		pipe.SAdd(ctx, userSessionsKey(session.UserID), session.ID)
+		pipe.Expire(ctx, userSessionsKey(session.UserID), ttl)
+
+		return nil
+	})
 	if err != nil {
 		return fmt.Errorf("unable to store token: %w", err)
 	}
@@ -32,10 +51,14 @@ func (s *TokenStorage) Add(ctx context.Context, session models.Session, ttl time
 	return nil
 }
 
-func (s *TokenStorage) Get(ctx context.Context, userID string) (models.Session, error) {
-	b, err := s.rdb.Get(ctx, userID).Bytes()
+func (s *TokenStorage) Get(ctx context.Context, sessionID string) (models.Session, error) {
+	b, err := s.rdb.Get(ctx, sessionKey(sessionID)).Bytes()
 	if err != nil {
-		return models.Session{}, fmt.Errorf("user session doesn't exist: %w", err)
+		if errors.Is(err, redis.Nil) {
+			return models.Session{}, ErrSessionNotFound
+		}
+
+		return models.Session{}, fmt.Errorf("failed to get session: %w", err)
 	}
 
 	session, err := models.UnmarshalSession(b)
@@ -46,10 +69,115 @@ func (s *TokenStorage) Get(ctx context.Context, userID string) (models.Session,
 	return session, nil
 }
 
-func (s *TokenStorage) Delete(ctx context.Context, userID string) error {
-	if err := s.rdb.Del(ctx, userID).Err(); err != nil {
-		return err
+// GetAllByUser returns every live session of the user and drops index entries
+// whose sessions have already expired.
+func (s *TokenStorage) GetAllByUser(ctx context.Context, userID string) ([]models.Session, error) {
+	sessionIDs, err := s.rdb.SMembers(ctx, userSessionsKey(userID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user sessions: %w", err)
+	}
+
+	if len(sessionIDs) == 0 {
+		return []models.Session{}, nil
+	}
+
+	keys := make([]string, len(sessionIDs))
+	for i, id := range sessionIDs {
+		keys[i] = sessionKey(id)
+	}
+
+	values, err := s.rdb.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user sessions: %w", err)
+	}
+
+	var (
+		sessions = make([]models.Session, 0, len(values))
+		stale    []any
+	)
+
+	for i, v := range values {
+		str, ok := v.(string)
+		if !ok {
+			stale = append(stale, sessionIDs[i])
+			continue
+		}
+
+		session, err := models.UnmarshalSession([]byte(str))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get session: %w", err)
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	if len(stale) != 0 {
+		if err = s.rdb.SRem(ctx, userSessionsKey(userID), stale...).Err(); err != nil {
+			return nil, fmt.Errorf("failed to clean up user sessions: %w", err)
+		}
+	}
+
+	return sessions, nil
+}
+
+func (s *TokenStorage) Delete(ctx context.Context, userID, sessionID string) error {
+	_, err := s.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, sessionKey(sessionID))
+		pipe.SRem(ctx, userSessionsKey(userID), sessionID)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to delete session: %w", err)
 	}
 
 	return nil
 }
+
+// MarkRefreshTokenUsed remembers the hash of a rotated refresh token. It reports false
+// if the token had already been marked, i.e. it is being rotated for the second time.
+func (s *TokenStorage) MarkRefreshTokenUsed(ctx context.Context, tokenHash string, token models.UsedRefreshToken,
+	ttl time.Duration) (bool, error) {
+	b, err := json.Marshal(token)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal used token: %w", err)
+	}
+
+	ok, err := s.rdb.SetNX(ctx, usedTokenKey(tokenHash), b, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("unable to store used token: %w", err)
+	}
+
+	return ok, nil
+}
+
+// GetUsedRefreshToken reports whether the refresh token with tokenHash was already rotated.
+func (s *TokenStorage) GetUsedRefreshToken(ctx context.Context, tokenHash string) (models.UsedRefreshToken, bool, error) {
+	b, err := s.rdb.Get(ctx, usedTokenKey(tokenHash)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return models.UsedRefreshToken{}, false, nil
+		}
+
+		return models.UsedRefreshToken{}, false, fmt.Errorf("failed to get used token: %w", err)
+	}
+
+	var token models.UsedRefreshToken
+	if err = json.Unmarshal(b, &token); err != nil {
+		return models.UsedRefreshToken{}, false, fmt.Errorf("failed to unmarshal used token: %w", err)
+	}
+
+	return token, true, nil
+}
+
+func sessionKey(sessionID string) string {
+	return sessionKeyPrefix + sessionID
+}
+
+func userSessionsKey(userID string) string {
+	return userSessionsKeyPrefix + userID
+}
+
+func usedTokenKey(tokenHash string) string {
+	return usedTokenKeyPrefix + tokenHash
+}
@@ -2,11 +2,16 @@ package storage
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"strings"
 
 	"github.com/HeadGardener/coursework/internal/models"
 	"github.com/jmoiron/sqlx"
 )
 
+var ErrUsernameTaken = errors.New("username is already taken")
+
 type UserStorage struct {
 	db *sqlx.DB
 }
@@ -15,19 +20,35 @@ func NewUserStorage(db *sqlx.DB) *UserStorage {
 	return &UserStorage{db: db}
 }
 
+// Create stores user with the default user role.
 func (s *UserStorage) Create(ctx context.Context, user *models.User) (string, error) {
-	if _, err := s.db.ExecContext(ctx, `insert into users (id, username, name, role, age, password_hash)
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if err = insertUser(ctx, tx, user); err != nil {
+		return "", err
+	}
+
+	return user.ID, tx.Commit()
+}
+
+// insertUser adds user with the default user role as part of tx.
+func insertUser(ctx context.Context, tx *sqlx.Tx, user *models.User) error {
+	if _, err := tx.ExecContext(ctx, `insert into users (id, username, name, birth_date, region, password_hash)
 												values($1,$2,$3,$4,$5,$6)`,
 		user.ID,
 		user.Username,
 		user.Name,
-		user.Role,
-		user.Age,
+		user.BirthDate,
+		user.Region,
 		user.PasswordHash); err != nil {
-		return "", err
+		return err
 	}
 
-	return user.ID, nil
+	return assignRole(ctx, tx, user.ID, models.RoleUser)
 }
 
 func (s *UserStorage) GetByUsername(ctx context.Context, username string) (*models.User, error) {
@@ -49,3 +70,190 @@ func (s *UserStorage) GetByID(ctx context.Context, userID string) (*models.User,
 
 	return &user, nil
 }
+
+func (s *UserStorage) UpdatePassword(ctx context.Context, userID, passwordHash string) error {
+	if _, err := s.db.ExecContext(ctx, `update users set password_hash=$1 where id=$2`,
+		passwordHash, userID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *UserStorage) SetTOTP(ctx context.Context, userID, secret string, enabled bool) error {
+	if _, err := s.db.ExecContext(ctx, `update users set totp_secret=$1, totp_enabled=$2 where id=$3`,
+		secret, enabled, userID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetAccess returns the roles of the user and every permission they grant.
+func (s *UserStorage) GetAccess(ctx context.Context, userID string) (models.Access, error) {
+	access := models.Access{
+		Roles:       []string{},
+		Permissions: []models.Permission{},
+	}
+
+	if err := s.db.SelectContext(ctx, &access.Roles, `select r.name from roles r
+												join user_roles ur on ur.role_id = r.id
+												where ur.user_id=$1 order by r.name`,
+		userID); err != nil {
+		return models.Access{}, err
+	}
+
+	if err := s.db.SelectContext(ctx, &access.Permissions, `select distinct p.name from permissions p
+												join role_permissions rp on rp.permission_id = p.id
+												join user_roles ur on ur.role_id = rp.role_id
+												where ur.user_id=$1 order by p.name`,
+		userID); err != nil {
+		return models.Access{}, err
+	}
+
+	return access, nil
+}
+
+func assignRole(ctx context.Context, tx *sqlx.Tx, userID, role string) error {
+	if _, err := tx.ExecContext(ctx, `insert into user_roles (user_id, role_id)
+												select $1, id from roles where name=$2
+												on conflict do nothing`,
+		userID, role); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// List returns a page of users ordered by creation time and the total number
+// of users matching filter.
+func (s *UserStorage) List(ctx context.Context, filter models.UserFilter) ([]models.User, int, error) {
+	var (
+		users   = []models.User{}
+		total   int
+		pattern = "%" + escapeLike(filter.Query) + "%"
+	)
+
+	if err := s.db.GetContext(ctx, &total, `select count(*) from users
+												where $1 = '' or username ilike $2 or name ilike $2`,
+		filter.Query, pattern); err != nil {
+		return nil, 0, err
+	}
+
+	if err := s.db.SelectContext(ctx, &users, `select * from users
+												where $1 = '' or username ilike $2 or name ilike $2
+												order by created_at, id limit $3 offset $4`,
+		filter.Query, pattern, filter.PerPage, (filter.Page-1)*filter.PerPage); err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// GetRoles returns role names of several users at once, keyed by user ID.
+func (s *UserStorage) GetRoles(ctx context.Context, userIDs []string) (map[string][]string, error) {
+	var rows []struct {
+		UserID string `db:"user_id"`
+		Name   string `db:"name"`
+	}
+
+	if err := s.db.SelectContext(ctx, &rows, `select ur.user_id, r.name from user_roles ur
+												join roles r on r.id = ur.role_id
+												where ur.user_id::text = any($1) order by r.name`,
+		userIDs); err != nil {
+		return nil, err
+	}
+
+	roles := make(map[string][]string, len(userIDs))
+	for _, row := range rows {
+		roles[row.UserID] = append(roles[row.UserID], row.Name)
+	}
+
+	return roles, nil
+}
+
+func (s *UserStorage) ListRoles(ctx context.Context) ([]string, error) {
+	var roles []string
+
+	if err := s.db.SelectContext(ctx, &roles, `select name from roles order by name`); err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+// SetRoles replaces the roles of the user.
+func (s *UserStorage) SetRoles(ctx context.Context, userID string, roles []string) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if _, err = tx.ExecContext(ctx, `delete from user_roles where user_id=$1`, userID); err != nil {
+		return err
+	}
+
+	for _, role := range roles {
+		if err = assignRole(ctx, tx, userID, role); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *UserStorage) SetDisabled(ctx context.Context, userID string, disabled bool) error {
+	res, err := s.db.ExecContext(ctx, `update users set disabled=$1 where id=$2`, disabled, userID)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// UpdateProfile sets the username and name of the user, ErrUsernameTaken means another
+// user has the username.
+func (s *UserStorage) UpdateProfile(ctx context.Context, userID, username, name string) error {
+	res, err := s.db.ExecContext(ctx, `update users set username=$1, name=$2 where id=$3`, username, name, userID)
+	if err != nil {
+		if hasPgCode(err, uniqueViolation) {
+			return ErrUsernameTaken
+		}
+
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// Delete removes the user, recovery codes and role assignments go with it.
+func (s *UserStorage) Delete(ctx context.Context, userID string) error {
+	if _, err := s.db.ExecContext(ctx, `delete from users where id=$1`, userID); err != nil {
+		return err
+	}
+
+	return nil
+}